@@ -8,6 +8,7 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // PostgresSpec defines the desired state of Postgres
+// +kubebuilder:validation:XValidation:rule="self.database == oldSelf.database || (has(self.databaseRename) && self.databaseRename.policy == 'Rename')",message="database can only be changed with databaseRename.policy Rename"
 type PostgresSpec struct {
 	Database string `json:"database"`
 	// +optional
@@ -20,6 +21,265 @@ type PostgresSpec struct {
 	// +optional
 	// +listType=set
 	Extensions []string `json:"extensions,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=schema
+	// Schemas owned by a group role of their own instead of the owner role of the database.
+	// Each of them gets owner, reader and writer roles. The schemas have to be listed in schemas.
+	SchemaOwners []PostgresSchemaOwner `json:"schemaOwners,omitempty"`
+	// +optional
+	// +listType=set
+	// Roles other than the owner and writer roles that create objects in the schemas, e.g. the owner role
+	// of another schema in schemaOwners. The reader, writer and owner roles get privileges on objects they
+	// create. Only roles of this Postgres are accepted, others are reported in the DefaultPrivilegesApplied condition.
+	DefaultPrivilegesFor []string `json:"defaultPrivilegesFor,omitempty"`
+	// +optional
+	// Take a logical backup with pg_dump before the database is dropped.
+	// Only used when dropOnDelete is enabled.
+	BackupBeforeDrop *PostgresBackupSpec `json:"backupBeforeDrop,omitempty"`
+	// +optional
+	// Create the database as a copy of another database
+	CloneFrom *PostgresCloneSource `json:"cloneFrom,omitempty"`
+	// +optional
+	// +listType=set
+	// Namespaces whose PostgresGrants may bind users to the roles of this database, "*" allows all.
+	// Grants from the namespace of this resource are always allowed.
+	AllowGrantsFrom []string `json:"allowGrantsFrom,omitempty"`
+	// +optional
+	// +listType=set
+	// Namespaces whose Postgres resources may be created as a copy of this database, "*" allows all.
+	// Clones from the namespace of this resource are always allowed.
+	AllowClonesFrom []string `json:"allowClonesFrom,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=schema
+	// +listMapKey=table
+	// +listMapKey=role
+	// Privileges of the reader or writer role on single tables. They replace the
+	// schema-wide privileges of that role on the table.
+	TableGrants []PostgresTableGrant `json:"tableGrants,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=schema
+	// +listMapKey=table
+	// Tables with row level security enabled and their policies
+	RowSecurity []PostgresRowSecurity `json:"rowSecurity,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// Logical replication slots of this database
+	ReplicationSlots []PostgresReplicationSlot `json:"replicationSlots,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// postgres_fdw servers pointing at the databases of other Postgres resources in the same namespace
+	ForeignServers []PostgresForeignServer `json:"foreignServers,omitempty"`
+	// +optional
+	// ConfigMaps in the same namespace holding SQL scripts to apply once. Scripts are applied in the
+	// order of the ConfigMaps, and within a ConfigMap in the lexical order of their keys.
+	Migrations []PostgresMigrationSource `json:"migrations,omitempty"`
+	// +optional
+	// What happens when database is changed, changes are rejected by default
+	DatabaseRename *PostgresDatabaseRename `json:"databaseRename,omitempty"`
+}
+
+// PostgresDatabaseRename decides how a change of the database name is handled
+type PostgresDatabaseRename struct {
+	// Reject keeps the database and reports the change, Rename renames the database
+	// along with the reader, writer and owner roles named after it
+	// +kubebuilder:validation:Enum=Reject;Rename
+	// +kubebuilder:default=Reject
+	Policy string `json:"policy"`
+	// +optional
+	// Terminate the connections to the database, PostgreSQL refuses to rename a database in use
+	TerminateConnections bool `json:"terminateConnections,omitempty"`
+}
+
+// PostgresMigrationSource references a ConfigMap of SQL scripts
+type PostgresMigrationSource struct {
+	ConfigMap string `json:"configMap"`
+}
+
+// PostgresForeignServer is a postgres_fdw server for the database of another Postgres resource
+type PostgresForeignServer struct {
+	Name string `json:"name"`
+	// Name of the Postgres resource in the same namespace the server points at
+	Postgres string `json:"postgres"`
+	// +optional
+	// Host the server connects to, defaults to the host of the PostgreSQL server the operator connects to.
+	// Set it when that address is not reachable from the database server itself, e.g. a proxy or load balancer.
+	Host string `json:"host,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// Port the server connects to, defaults to the port the operator connects to
+	Port int32 `json:"port,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=role
+	UserMappings []PostgresUserMapping `json:"userMappings,omitempty"`
+}
+
+// PostgresUserMapping lets a group role of this database use a foreign server
+type PostgresUserMapping struct {
+	// Group role of this database the mapping is for
+	Role PostgresPolicyRole `json:"role"`
+	// Name of a PostgresUser of the foreign database in the same namespace,
+	// the credentials in its secret are used on the server
+	User string `json:"user"`
+}
+
+// PostgresSchemaOwner gives a schema an owner role of its own
+type PostgresSchemaOwner struct {
+	Schema string `json:"schema"`
+	// +optional
+	// Name of the owner role, defaults to <database>-<schema>-group
+	Role string `json:"role,omitempty"`
+}
+
+// PostgresReplicationSlot is a logical replication slot in the database
+type PostgresReplicationSlot struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+	// +optional
+	// +kubebuilder:default=pgoutput
+	// Output plugin decoding the changes
+	Plugin string `json:"plugin"`
+	// +optional
+	// Drop the slot once it was inactive for this long, e.g. 24h. It is recreated at the
+	// current position, changes made in the meantime are lost to its consumer.
+	DropInactiveAfter *metav1.Duration `json:"dropInactiveAfter,omitempty"`
+}
+
+// PostgresRowSecurity enables row level security on a table
+type PostgresRowSecurity struct {
+	// +optional
+	// +kubebuilder:default=public
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	// +optional
+	// Apply the policies to the table owner as well
+	Force bool `json:"force,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Policies []PostgresPolicy `json:"policies,omitempty"`
+}
+
+// PostgresPolicy is a row security policy for group roles of this database
+// +kubebuilder:validation:XValidation:rule="has(self.using) || has(self.withCheck)",message="at least one of using or withCheck must be set"
+// +kubebuilder:validation:XValidation:rule="!(self.command == 'INSERT' && has(self.using))",message="INSERT policies only support withCheck"
+// +kubebuilder:validation:XValidation:rule="!(self.command in ['SELECT', 'DELETE'] && has(self.withCheck))",message="SELECT and DELETE policies only support using"
+type PostgresPolicy struct {
+	Name string `json:"name"`
+	// +optional
+	// +kubebuilder:validation:Enum=ALL;SELECT;INSERT;UPDATE;DELETE
+	// +kubebuilder:default=ALL
+	Command string `json:"command"`
+	// +optional
+	// Combine with other policies using AND instead of OR
+	Restrictive bool `json:"restrictive,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// Group roles of this database the policy applies to
+	Roles []PostgresPolicyRole `json:"roles"`
+	// +optional
+	// SQL expression rows have to satisfy to be visible
+	Using string `json:"using,omitempty"`
+	// +optional
+	// SQL expression new rows have to satisfy
+	WithCheck string `json:"withCheck,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=owner;reader;writer
+type PostgresPolicyRole string
+
+// PostgresTableGrant defines the privileges of a group role on a table.
+// +kubebuilder:validation:XValidation:rule="!(has(self.columns) && has(self.exceptColumns))",message="columns and exceptColumns are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.columns) || has(self.exceptColumns)) || self.privileges.all(p, p in ['SELECT', 'INSERT', 'UPDATE', 'REFERENCES'])",message="only SELECT, INSERT, UPDATE and REFERENCES can be granted on columns"
+type PostgresTableGrant struct {
+	// +optional
+	// +kubebuilder:default=public
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	// +kubebuilder:validation:Enum=reader;writer
+	// Group role of this database the privileges are granted to
+	Role string `json:"role"`
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Privileges []PostgresTablePrivilege `json:"privileges"`
+	// +optional
+	// +listType=set
+	// Restrict the privileges to these columns
+	Columns []string `json:"columns,omitempty"`
+	// +optional
+	// +listType=set
+	// Restrict the privileges to all columns but these
+	ExceptColumns []string `json:"exceptColumns,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=SELECT;INSERT;UPDATE;DELETE;TRUNCATE;REFERENCES;TRIGGER
+type PostgresTablePrivilege string
+
+// PostgresCloneSource references the database a new database is copied from.
+// +kubebuilder:validation:XValidation:rule="has(self.postgres) != has(self.database)",message="exactly one of postgres or database must be set"
+type PostgresCloneSource struct {
+	// +optional
+	// Name of a Postgres resource to copy. It has to allow it in allowClonesFrom.
+	Postgres string `json:"postgres,omitempty"`
+	// +optional
+	// Namespace of the Postgres resource, defaults to the namespace of the clone.
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	// Name of a database on the server to copy. The operator has to list it in CLONE_SOURCE_DATABASES.
+	Database string `json:"database,omitempty"`
+	// +optional
+	// Terminate the connections to the source database so it can be used as a template
+	TerminateConnections bool `json:"terminateConnections,omitempty"`
+	// +optional
+	// +kubebuilder:default="postgres:17-alpine"
+	// Image providing pg_dump and pg_restore, used to copy a source database that is in use
+	Image string `json:"image,omitempty"`
+}
+
+// PostgresBackupSpec configures the pg_dump Job that runs before a database is dropped.
+// +kubebuilder:validation:XValidation:rule="has(self.persistentVolumeClaim) != has(self.objectStore)",message="exactly one of persistentVolumeClaim or objectStore must be set"
+type PostgresBackupSpec struct {
+	// +optional
+	// +kubebuilder:default="postgres:17-alpine"
+	// Image providing pg_dump, its major version must not be older than the server's
+	Image string `json:"image,omitempty"`
+	// +optional
+	// Write the dump to a PersistentVolumeClaim in the namespace of the Postgres resource
+	PersistentVolumeClaim *PostgresBackupPVCTarget `json:"persistentVolumeClaim,omitempty"`
+	// +optional
+	// Upload the dump to an S3 compatible object store
+	ObjectStore *PostgresBackupObjectStoreTarget `json:"objectStore,omitempty"`
+}
+
+// PostgresBackupPVCTarget writes the dump to a PersistentVolumeClaim.
+type PostgresBackupPVCTarget struct {
+	// Name of the PersistentVolumeClaim
+	ClaimName string `json:"claimName"`
+	// +optional
+	// Directory inside the volume the dump is written to
+	Path string `json:"path,omitempty"`
+}
+
+// PostgresBackupObjectStoreTarget uploads the dump to an S3 compatible object store.
+type PostgresBackupObjectStoreTarget struct {
+	// Destination prefix, e.g. s3://bucket/backups
+	URL string `json:"url"`
+	// +optional
+	// Endpoint of a non-AWS S3 compatible service
+	Endpoint string `json:"endpoint,omitempty"`
+	// Name of a secret holding AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optionally AWS_DEFAULT_REGION
+	CredentialsSecret string `json:"credentialsSecret"`
+	// +optional
+	// +kubebuilder:default="amazon/aws-cli:latest"
+	// Image providing the aws CLI used for the upload
+	Image string `json:"image,omitempty"`
 }
 
 // PostgresStatus defines the observed state of Postgres
@@ -27,11 +287,136 @@ type PostgresStatus struct {
 	Succeeded bool          `json:"succeeded"`
 	Roles     PostgresRoles `json:"roles"`
 	// +optional
+	// Name of the database the roles and schemas were created for
+	Database string `json:"database,omitempty"`
+	// +optional
 	// +listType=set
 	Schemas []string `json:"schemas,omitempty"`
 	// +optional
 	// +listType=set
 	Extensions []string `json:"extensions,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=schema
+	// Group roles of the schemas with an owner of their own
+	SchemaRoles []PostgresSchemaRoles `json:"schemaRoles,omitempty"`
+	// +optional
+	// Database this database was copied from
+	ClonedFrom string `json:"clonedFrom,omitempty"`
+	// +optional
+	// Table grants currently applied
+	TableGrants []PostgresTableGrantRef `json:"tableGrants,omitempty"`
+	// +optional
+	// Tables row level security was enabled on
+	RowSecurityTables []PostgresTableRef `json:"rowSecurityTables,omitempty"`
+	// +optional
+	// Row security policies currently applied
+	Policies []PostgresPolicyStatus `json:"policies,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// Replication slots created by the operator and their state
+	ReplicationSlots []PostgresReplicationSlotStatus `json:"replicationSlots,omitempty"`
+	// +optional
+	// Foreign servers currently created
+	ForeignServers []PostgresForeignServerStatus `json:"foreignServers,omitempty"`
+	// +optional
+	// User mappings currently created
+	UserMappings []PostgresUserMappingStatus `json:"userMappings,omitempty"`
+	// +optional
+	// Scripts applied to the database
+	Migrations []PostgresMigrationStatus `json:"migrations,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// PostgresTableRef names a table of the database
+type PostgresTableRef struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+}
+
+// PostgresPolicyStatus records an applied policy to detect changes made outside of the operator
+type PostgresPolicyStatus struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Name   string `json:"name"`
+	// Hash of the policy spec it was created from
+	Hash string `json:"hash"`
+	// +optional
+	// USING expression as stored by the server
+	Using string `json:"using,omitempty"`
+	// +optional
+	// WITH CHECK expression as stored by the server
+	WithCheck string `json:"withCheck,omitempty"`
+}
+
+// PostgresReplicationSlotStatus reports the state of a replication slot
+type PostgresReplicationSlotStatus struct {
+	Name   string `json:"name"`
+	Plugin string `json:"plugin"`
+	// Whether a consumer is connected to the slot
+	Active bool `json:"active"`
+	// Bytes of WAL retained for the slot
+	LagBytes int64 `json:"lagBytes"`
+	// +optional
+	// When the slot was first seen inactive
+	InactiveSince *metav1.Time `json:"inactiveSince,omitempty"`
+}
+
+// PostgresForeignServerStatus records a created foreign server
+type PostgresForeignServerStatus struct {
+	Name string `json:"name"`
+	// Database the server points at
+	Database string `json:"database"`
+}
+
+// PostgresUserMappingStatus records a created user mapping
+type PostgresUserMappingStatus struct {
+	Server string `json:"server"`
+	Role   string `json:"role"`
+	// Hash of the credentials the mapping was last set with
+	CredentialsHash string `json:"credentialsHash"`
+}
+
+// PostgresMigrationStatus records an applied script
+type PostgresMigrationStatus struct {
+	// Name of the script, <configmap>/<key>
+	Name string `json:"name"`
+	// SHA-256 of the script
+	Checksum  string      `json:"checksum"`
+	AppliedAt metav1.Time `json:"appliedAt"`
+}
+
+// PostgresTableGrantRef identifies an applied table grant
+type PostgresTableGrantRef struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Role   string `json:"role"`
+}
+
+const (
+	// ConditionBackupCompleted reports the state of the pre-drop backup Job
+	ConditionBackupCompleted = "BackupCompleted"
+	// ConditionCloned reports whether the database was copied from its clone source
+	ConditionCloned = "Cloned"
+	// ConditionMigrationsApplied reports whether all scripts of the migrations were applied
+	ConditionMigrationsApplied = "MigrationsApplied"
+	// ConditionDefaultPrivilegesApplied reports whether pg_default_acl grants the group roles privileges
+	// on objects created in the schemas in the future
+	ConditionDefaultPrivilegesApplied = "DefaultPrivilegesApplied"
+	// ConditionInSync reports whether a resync found the database, its roles and schemas as the operator created them
+	ConditionInSync = "InSync"
+	// ConditionDatabaseRenamed reports whether a change of the database name was applied
+	ConditionDatabaseRenamed = "DatabaseRenamed"
+)
+
+// PostgresSchemaRoles stores the group roles of a schema
+type PostgresSchemaRoles struct {
+	Schema string        `json:"schema"`
+	Roles  PostgresRoles `json:"roles"`
 }
 
 // PostgresRoles stores the different group roles for database
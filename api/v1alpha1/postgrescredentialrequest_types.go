@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresCredentialRequestSpec defines the desired state of PostgresCredentialRequest
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new request instead"
+type PostgresCredentialRequestSpec struct {
+	// Name of the PostgresUser in the namespace of this request whose group role the issued role inherits
+	User string `json:"user"`
+	// +optional
+	// +kubebuilder:default="1h"
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="ttl must be positive"
+	// How long the issued role can log in, it is dropped once the lease expires
+	TTL metav1.Duration `json:"ttl,omitempty"`
+	// +optional
+	// Name of the secret to create with the credentials, defaults to the name of the request
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// PostgresCredentialRequestStatus defines the observed state of PostgresCredentialRequest
+type PostgresCredentialRequestStatus struct {
+	Succeeded bool `json:"succeeded"`
+	// +optional
+	// Issued login role
+	PostgresRole string `json:"postgresRole,omitempty"`
+	// +optional
+	// Login of the issued role, differs from the role on Azure
+	PostgresLogin string `json:"postgresLogin,omitempty"`
+	// +optional
+	// Group role of the PostgresUser inherited by the issued role
+	PostgresGroup string `json:"postgresGroup,omitempty"`
+	// +optional
+	DatabaseName string `json:"databaseName,omitempty"`
+	// +optional
+	// When the lease expires
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// +optional
+	// Reflects whether the lease expired and the issued role was dropped
+	Expired bool `json:"expired,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Role",type=string,JSONPath=`.status.postgresRole`
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.expiresAt`
+// +kubebuilder:printcolumn:name="Expired",type=boolean,JSONPath=`.status.expired`
+
+// PostgresCredentialRequest is the Schema for the postgrescredentialrequests API.
+// It issues a short-lived login role with the privileges of a PostgresUser.
+type PostgresCredentialRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresCredentialRequestSpec   `json:"spec,omitempty"`
+	Status PostgresCredentialRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresCredentialRequestList contains a list of PostgresCredentialRequest
+type PostgresCredentialRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresCredentialRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresCredentialRequest{}, &PostgresCredentialRequestList{})
+}
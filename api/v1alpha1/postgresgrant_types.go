@@ -0,0 +1,67 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresGrantSpec defines the desired state of PostgresGrant
+type PostgresGrantSpec struct {
+	// Name of the PostgresUser in the namespace of this grant whose role is granted access
+	User string `json:"user"`
+	// Postgres resource owning the group role that is granted
+	Postgres PostgresGrantTarget `json:"postgres"`
+	// +optional
+	// +kubebuilder:validation:Enum=OWNER;READ;WRITE
+	// +kubebuilder:default=READ
+	// Group role of the target database to grant
+	Privileges string `json:"privileges,omitempty"`
+}
+
+// PostgresGrantTarget references a Postgres resource, possibly in another namespace.
+type PostgresGrantTarget struct {
+	// Name of the Postgres resource
+	Name string `json:"name"`
+	// +optional
+	// Namespace of the Postgres resource, defaults to the namespace of the grant.
+	// The target has to allow it in allowGrantsFrom.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PostgresGrantStatus defines the observed state of PostgresGrant
+type PostgresGrantStatus struct {
+	Succeeded bool `json:"succeeded"`
+	// +optional
+	// Role of the PostgresUser the group role is granted to
+	PostgresRole string `json:"postgresRole,omitempty"`
+	// +optional
+	// Group role that is granted
+	PostgresGroup string `json:"postgresGroup,omitempty"`
+	// +optional
+	DatabaseName string `json:"databaseName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+
+// PostgresGrant is the Schema for the postgresgrants API
+type PostgresGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresGrantSpec   `json:"spec,omitempty"`
+	Status PostgresGrantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresGrantList contains a list of PostgresGrant
+type PostgresGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresGrant{}, &PostgresGrantList{})
+}
@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresPublicationSpec defines the desired state of PostgresPublication
+// +kubebuilder:validation:XValidation:rule="self.allTables != (has(self.tables) || has(self.schemas))",message="either allTables or tables and schemas must be set"
+type PostgresPublicationSpec struct {
+	// Name of the Postgres resource in the same namespace whose database is published
+	Postgres string `json:"postgres"`
+	// +optional
+	// Name of the publication, defaults to the name of this resource
+	Name string `json:"name,omitempty"`
+	// +optional
+	// Publish all tables of the database, including tables created later
+	AllTables bool `json:"allTables,omitempty"`
+	// +optional
+	// +listType=set
+	// Tables to publish, either as table or schema.table
+	Tables []string `json:"tables,omitempty"`
+	// +optional
+	// +listType=set
+	// Publish all tables of these schemas, requires PostgreSQL 15 or later
+	Schemas []string `json:"schemas,omitempty"`
+	// +optional
+	// +listType=set
+	// Operations to publish, defaults to all of them
+	Operations []PostgresPublicationOperation `json:"operations,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=insert;update;delete;truncate
+type PostgresPublicationOperation string
+
+// PostgresPublicationStatus defines the observed state of PostgresPublication
+type PostgresPublicationStatus struct {
+	Succeeded bool `json:"succeeded"`
+	// +optional
+	PublicationName string `json:"publicationName,omitempty"`
+	// +optional
+	DatabaseName string `json:"databaseName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+
+// PostgresPublication is the Schema for the postgrespublications API
+type PostgresPublication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresPublicationSpec   `json:"spec,omitempty"`
+	Status PostgresPublicationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresPublicationList contains a list of PostgresPublication
+type PostgresPublicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresPublication `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresPublication{}, &PostgresPublicationList{})
+}
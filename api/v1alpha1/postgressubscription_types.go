@@ -0,0 +1,78 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresSubscriptionSpec defines the desired state of PostgresSubscription
+type PostgresSubscriptionSpec struct {
+	// Name of the Postgres resource in the same namespace whose database subscribes
+	Postgres string `json:"postgres"`
+	// +optional
+	// Name of the subscription, defaults to the name of this resource
+	Name string `json:"name,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// Publications on the publisher to subscribe to
+	Publications []string `json:"publications"`
+	// Secret holding the connection string of the publisher
+	ConnectionSecret PostgresSubscriptionSecretRef `json:"connectionSecret"`
+	// +optional
+	// +kubebuilder:default=true
+	// Whether the subscription is replicating
+	Enabled *bool `json:"enabled,omitempty"`
+	// +optional
+	// +kubebuilder:default=true
+	// Copy the existing data of the published tables when the subscription is created
+	CopyData *bool `json:"copyData,omitempty"`
+	// +optional
+	// Name of the replication slot on the publisher, defaults to the subscription name
+	SlotName string `json:"slotName,omitempty"`
+}
+
+// PostgresSubscriptionSecretRef selects the key of a secret holding a libpq connection string
+type PostgresSubscriptionSecretRef struct {
+	// Name of the secret, e.g. the secret of a PostgresUser with replication enabled
+	Name string `json:"name"`
+	// +optional
+	// +kubebuilder:default=POSTGRES_URL
+	Key string `json:"key,omitempty"`
+}
+
+// PostgresSubscriptionStatus defines the observed state of PostgresSubscription
+type PostgresSubscriptionStatus struct {
+	Succeeded bool `json:"succeeded"`
+	// +optional
+	SubscriptionName string `json:"subscriptionName,omitempty"`
+	// +optional
+	DatabaseName string `json:"databaseName,omitempty"`
+	// +optional
+	// Hash of the connection string the subscription was last configured with
+	ConnectionHash string `json:"connectionHash,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+
+// PostgresSubscription is the Schema for the postgressubscriptions API
+type PostgresSubscription struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresSubscriptionSpec   `json:"spec,omitempty"`
+	Status PostgresSubscriptionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresSubscriptionList contains a list of PostgresSubscription
+type PostgresSubscriptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresSubscription `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresSubscription{}, &PostgresSubscriptionList{})
+}
@@ -21,11 +21,76 @@ type PostgresUserSpec struct {
 	// List of privileges to grant to this user
 	Privileges string `json:"privileges"`
 	// +optional
+	// Schema with an owner of its own in the schemaOwners of the database. The user gets
+	// the owner, reader or writer role of that schema instead of the database's.
+	Schema string `json:"schema,omitempty"`
+	// +optional
 	AWS *PostgresUserAWSSpec `json:"aws,omitempty"`
 	// +optional
+	// Allow the user to connect for replication, e.g. for logical decoding by CDC tools
+	Replication bool `json:"replication,omitempty"`
+	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+	// +optional
+	// Other namespaces the secret is copied to, they have to match the namespace selector of the operator.
+	// Ignored unless the credentials are stored in a Secret.
+	SecretNamespaces []string `json:"secretNamespaces,omitempty"`
+	// +optional
+	// Where to store the credentials of the user, a Secret of the namespace by default
+	CredentialSink *PostgresCredentialSink `json:"credentialSink,omitempty"`
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Enum=libpq;SQLAlchemy;Prisma;Rails;R2DBC;pgpass
+	// Connection strings to add to the credentials besides POSTGRES_URL, POSTGRES_JDBC_URL and POSTGRES_DOTNET_URL.
+	// They are escaped for their format and include the URI args of the operator.
+	ConnectionFormats []string `json:"connectionFormats,omitempty"`
+	// +optional
+	// Issue a TLS client certificate for the role, e.g. for cert authentication in pg_hba.conf.
+	// Its key, certificate and CA are added to the credentials as tls.key, tls.crt and ca.crt.
+	ClientCertificate *PostgresClientCertificate `json:"clientCertificate,omitempty"`
+}
+
+// PostgresClientCertificate requests a client certificate whose common name is the role of a PostgresUser
+type PostgresClientCertificate struct {
+	// +optional
+	// cert-manager issuer to request the certificate from with a Certificate, the CA configured
+	// on the operator signs it otherwise
+	IssuerRef *PostgresCertificateIssuerRef `json:"issuerRef,omitempty"`
+	// +optional
+	// +kubebuilder:default="2160h"
+	// How long certificates are valid, they are renewed after two thirds of it
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// +optional
+	// +kubebuilder:default="/etc/postgres-credentials"
+	// Where clients mount the credentials, used for sslcert, sslkey and sslrootcert in the connection strings
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// PostgresCertificateIssuerRef references a cert-manager Issuer or ClusterIssuer
+type PostgresCertificateIssuerRef struct {
+	Name string `json:"name"`
+	// +optional
+	// +kubebuilder:default=Issuer
+	Kind string `json:"kind,omitempty"`
+	// +optional
+	// +kubebuilder:default="cert-manager.io"
+	Group string `json:"group,omitempty"`
+}
+
+// PostgresCredentialSink selects where the credentials of a PostgresUser are stored
+// +kubebuilder:validation:XValidation:rule="!has(self.path) || !self.path.split('/').exists(s, s == '..' || s == '.')",message="path must not contain . or .. segments"
+type PostgresCredentialSink struct {
+	// +kubebuilder:validation:Enum=Secret;Vault;AWSSecretsManager
+	// +kubebuilder:default=Secret
+	// Secret stores the credentials in a Secret of the namespace, Vault in a KV version 2 secrets engine
+	// and AWSSecretsManager as a JSON secret. Vault and AWSSecretsManager must be configured on the operator.
+	Type string `json:"type"`
+	// +optional
+	// Path of the credentials below the prefix and namespace configured on the operator, defaults to
+	// the name of the secret. Ignored by the Secret sink.
+	Path string `json:"path,omitempty"`
 }
 
 // PostgresUserAWSSpec encapsulates AWS specific configuration toggles.
@@ -46,6 +111,19 @@ type PostgresUserStatus struct {
 	// Reflects whether IAM authentication is enabled for this user.
 	// +optional
 	EnableIamAuth bool `json:"enableIamAuth"`
+	// Reflects whether the user may connect for replication.
+	// +optional
+	Replication bool `json:"replication,omitempty"`
+	// Where the credentials are stored, they are removed from there when the sink changes or the user is deleted
+	// +optional
+	CredentialSink *PostgresCredentialSinkStatus `json:"credentialSink,omitempty"`
+}
+
+// PostgresCredentialSinkStatus is the sink and location the credentials of a PostgresUser were written to
+type PostgresCredentialSinkStatus struct {
+	Type string `json:"type"`
+	// Location of the credentials in the sink, namespace/name for a Secret
+	Location string `json:"location"`
 }
 
 // +kubebuilder:object:root=true
@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -35,49 +36,696 @@ func (in *Postgres) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackupObjectStoreTarget) DeepCopyInto(out *PostgresBackupObjectStoreTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackupObjectStoreTarget.
+func (in *PostgresBackupObjectStoreTarget) DeepCopy() *PostgresBackupObjectStoreTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackupObjectStoreTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackupPVCTarget) DeepCopyInto(out *PostgresBackupPVCTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackupPVCTarget.
+func (in *PostgresBackupPVCTarget) DeepCopy() *PostgresBackupPVCTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackupPVCTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackupSpec) DeepCopyInto(out *PostgresBackupSpec) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(PostgresBackupPVCTarget)
+		**out = **in
+	}
+	if in.ObjectStore != nil {
+		in, out := &in.ObjectStore, &out.ObjectStore
+		*out = new(PostgresBackupObjectStoreTarget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackupSpec.
+func (in *PostgresBackupSpec) DeepCopy() *PostgresBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCertificateIssuerRef) DeepCopyInto(out *PostgresCertificateIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCertificateIssuerRef.
+func (in *PostgresCertificateIssuerRef) DeepCopy() *PostgresCertificateIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCertificateIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClientCertificate) DeepCopyInto(out *PostgresClientCertificate) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(PostgresCertificateIssuerRef)
+		**out = **in
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClientCertificate.
+func (in *PostgresClientCertificate) DeepCopy() *PostgresClientCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClientCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCloneSource) DeepCopyInto(out *PostgresCloneSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCloneSource.
+func (in *PostgresCloneSource) DeepCopy() *PostgresCloneSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCloneSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCredentialRequest) DeepCopyInto(out *PostgresCredentialRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCredentialRequest.
+func (in *PostgresCredentialRequest) DeepCopy() *PostgresCredentialRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCredentialRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresCredentialRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCredentialRequestList) DeepCopyInto(out *PostgresCredentialRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresCredentialRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCredentialRequestList.
+func (in *PostgresCredentialRequestList) DeepCopy() *PostgresCredentialRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCredentialRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresCredentialRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCredentialRequestSpec) DeepCopyInto(out *PostgresCredentialRequestSpec) {
+	*out = *in
+	out.TTL = in.TTL
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCredentialRequestSpec.
+func (in *PostgresCredentialRequestSpec) DeepCopy() *PostgresCredentialRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCredentialRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCredentialRequestStatus) DeepCopyInto(out *PostgresCredentialRequestStatus) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCredentialRequestStatus.
+func (in *PostgresCredentialRequestStatus) DeepCopy() *PostgresCredentialRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCredentialRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCredentialSink) DeepCopyInto(out *PostgresCredentialSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCredentialSink.
+func (in *PostgresCredentialSink) DeepCopy() *PostgresCredentialSink {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCredentialSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCredentialSinkStatus) DeepCopyInto(out *PostgresCredentialSinkStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCredentialSinkStatus.
+func (in *PostgresCredentialSinkStatus) DeepCopy() *PostgresCredentialSinkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCredentialSinkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseRename) DeepCopyInto(out *PostgresDatabaseRename) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseRename.
+func (in *PostgresDatabaseRename) DeepCopy() *PostgresDatabaseRename {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseRename)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresForeignServer) DeepCopyInto(out *PostgresForeignServer) {
+	*out = *in
+	if in.UserMappings != nil {
+		in, out := &in.UserMappings, &out.UserMappings
+		*out = make([]PostgresUserMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresForeignServer.
+func (in *PostgresForeignServer) DeepCopy() *PostgresForeignServer {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresForeignServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresForeignServerStatus) DeepCopyInto(out *PostgresForeignServerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresForeignServerStatus.
+func (in *PostgresForeignServerStatus) DeepCopy() *PostgresForeignServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresForeignServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresGrant) DeepCopyInto(out *PostgresGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresGrant.
+func (in *PostgresGrant) DeepCopy() *PostgresGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresGrantList) DeepCopyInto(out *PostgresGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresGrantList.
+func (in *PostgresGrantList) DeepCopy() *PostgresGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresGrantSpec) DeepCopyInto(out *PostgresGrantSpec) {
+	*out = *in
+	out.Postgres = in.Postgres
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresGrantSpec.
+func (in *PostgresGrantSpec) DeepCopy() *PostgresGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresGrantStatus) DeepCopyInto(out *PostgresGrantStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresGrantStatus.
+func (in *PostgresGrantStatus) DeepCopy() *PostgresGrantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresGrantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresGrantTarget) DeepCopyInto(out *PostgresGrantTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresGrantTarget.
+func (in *PostgresGrantTarget) DeepCopy() *PostgresGrantTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresGrantTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresList) DeepCopyInto(out *PostgresList) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Postgres, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Postgres, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresList.
+func (in *PostgresList) DeepCopy() *PostgresList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresMigrationSource) DeepCopyInto(out *PostgresMigrationSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresMigrationSource.
+func (in *PostgresMigrationSource) DeepCopy() *PostgresMigrationSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresMigrationSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresMigrationStatus) DeepCopyInto(out *PostgresMigrationStatus) {
+	*out = *in
+	in.AppliedAt.DeepCopyInto(&out.AppliedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresMigrationStatus.
+func (in *PostgresMigrationStatus) DeepCopy() *PostgresMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPolicy) DeepCopyInto(out *PostgresPolicy) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]PostgresPolicyRole, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPolicy.
+func (in *PostgresPolicy) DeepCopy() *PostgresPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPolicyStatus) DeepCopyInto(out *PostgresPolicyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPolicyStatus.
+func (in *PostgresPolicyStatus) DeepCopy() *PostgresPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublication) DeepCopyInto(out *PostgresPublication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublication.
+func (in *PostgresPublication) DeepCopy() *PostgresPublication {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresPublication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationList) DeepCopyInto(out *PostgresPublicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresPublication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationList.
+func (in *PostgresPublicationList) DeepCopy() *PostgresPublicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresPublicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationSpec) DeepCopyInto(out *PostgresPublicationSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]PostgresPublicationOperation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationSpec.
+func (in *PostgresPublicationSpec) DeepCopy() *PostgresPublicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationStatus) DeepCopyInto(out *PostgresPublicationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationStatus.
+func (in *PostgresPublicationStatus) DeepCopy() *PostgresPublicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresReplicationSlot) DeepCopyInto(out *PostgresReplicationSlot) {
+	*out = *in
+	if in.DropInactiveAfter != nil {
+		in, out := &in.DropInactiveAfter, &out.DropInactiveAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresReplicationSlot.
+func (in *PostgresReplicationSlot) DeepCopy() *PostgresReplicationSlot {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresReplicationSlot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresReplicationSlotStatus) DeepCopyInto(out *PostgresReplicationSlotStatus) {
+	*out = *in
+	if in.InactiveSince != nil {
+		in, out := &in.InactiveSince, &out.InactiveSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresReplicationSlotStatus.
+func (in *PostgresReplicationSlotStatus) DeepCopy() *PostgresReplicationSlotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresReplicationSlotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresRoles) DeepCopyInto(out *PostgresRoles) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRoles.
+func (in *PostgresRoles) DeepCopy() *PostgresRoles {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresRoles)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresRowSecurity) DeepCopyInto(out *PostgresRowSecurity) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]PostgresPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresList.
-func (in *PostgresList) DeepCopy() *PostgresList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRowSecurity.
+func (in *PostgresRowSecurity) DeepCopy() *PostgresRowSecurity {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresList)
+	out := new(PostgresRowSecurity)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PostgresList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSchemaOwner) DeepCopyInto(out *PostgresSchemaOwner) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSchemaOwner.
+func (in *PostgresSchemaOwner) DeepCopy() *PostgresSchemaOwner {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(PostgresSchemaOwner)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresRoles) DeepCopyInto(out *PostgresRoles) {
+func (in *PostgresSchemaRoles) DeepCopyInto(out *PostgresSchemaRoles) {
 	*out = *in
+	out.Roles = in.Roles
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRoles.
-func (in *PostgresRoles) DeepCopy() *PostgresRoles {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSchemaRoles.
+func (in *PostgresSchemaRoles) DeepCopy() *PostgresSchemaRoles {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresRoles)
+	out := new(PostgresSchemaRoles)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -95,6 +743,74 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SchemaOwners != nil {
+		in, out := &in.SchemaOwners, &out.SchemaOwners
+		*out = make([]PostgresSchemaOwner, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultPrivilegesFor != nil {
+		in, out := &in.DefaultPrivilegesFor, &out.DefaultPrivilegesFor
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackupBeforeDrop != nil {
+		in, out := &in.BackupBeforeDrop, &out.BackupBeforeDrop
+		*out = new(PostgresBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloneFrom != nil {
+		in, out := &in.CloneFrom, &out.CloneFrom
+		*out = new(PostgresCloneSource)
+		**out = **in
+	}
+	if in.AllowGrantsFrom != nil {
+		in, out := &in.AllowGrantsFrom, &out.AllowGrantsFrom
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowClonesFrom != nil {
+		in, out := &in.AllowClonesFrom, &out.AllowClonesFrom
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TableGrants != nil {
+		in, out := &in.TableGrants, &out.TableGrants
+		*out = make([]PostgresTableGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RowSecurity != nil {
+		in, out := &in.RowSecurity, &out.RowSecurity
+		*out = make([]PostgresRowSecurity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReplicationSlots != nil {
+		in, out := &in.ReplicationSlots, &out.ReplicationSlots
+		*out = make([]PostgresReplicationSlot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ForeignServers != nil {
+		in, out := &in.ForeignServers, &out.ForeignServers
+		*out = make([]PostgresForeignServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]PostgresMigrationSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.DatabaseRename != nil {
+		in, out := &in.DatabaseRename, &out.DatabaseRename
+		*out = new(PostgresDatabaseRename)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSpec.
@@ -121,6 +837,57 @@ func (in *PostgresStatus) DeepCopyInto(out *PostgresStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SchemaRoles != nil {
+		in, out := &in.SchemaRoles, &out.SchemaRoles
+		*out = make([]PostgresSchemaRoles, len(*in))
+		copy(*out, *in)
+	}
+	if in.TableGrants != nil {
+		in, out := &in.TableGrants, &out.TableGrants
+		*out = make([]PostgresTableGrantRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.RowSecurityTables != nil {
+		in, out := &in.RowSecurityTables, &out.RowSecurityTables
+		*out = make([]PostgresTableRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]PostgresPolicyStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReplicationSlots != nil {
+		in, out := &in.ReplicationSlots, &out.ReplicationSlots
+		*out = make([]PostgresReplicationSlotStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ForeignServers != nil {
+		in, out := &in.ForeignServers, &out.ForeignServers
+		*out = make([]PostgresForeignServerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserMappings != nil {
+		in, out := &in.UserMappings, &out.UserMappings
+		*out = make([]PostgresUserMappingStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]PostgresMigrationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresStatus.
@@ -134,7 +901,7 @@ func (in *PostgresStatus) DeepCopy() *PostgresStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresUser) DeepCopyInto(out *PostgresUser) {
+func (in *PostgresSubscription) DeepCopyInto(out *PostgresSubscription) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -142,6 +909,186 @@ func (in *PostgresUser) DeepCopyInto(out *PostgresUser) {
 	out.Status = in.Status
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscription.
+func (in *PostgresSubscription) DeepCopy() *PostgresSubscription {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresSubscription) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionList) DeepCopyInto(out *PostgresSubscriptionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresSubscription, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionList.
+func (in *PostgresSubscriptionList) DeepCopy() *PostgresSubscriptionList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresSubscriptionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionSecretRef) DeepCopyInto(out *PostgresSubscriptionSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionSecretRef.
+func (in *PostgresSubscriptionSecretRef) DeepCopy() *PostgresSubscriptionSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionSpec) DeepCopyInto(out *PostgresSubscriptionSpec) {
+	*out = *in
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ConnectionSecret = in.ConnectionSecret
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CopyData != nil {
+		in, out := &in.CopyData, &out.CopyData
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionSpec.
+func (in *PostgresSubscriptionSpec) DeepCopy() *PostgresSubscriptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionStatus) DeepCopyInto(out *PostgresSubscriptionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionStatus.
+func (in *PostgresSubscriptionStatus) DeepCopy() *PostgresSubscriptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresTableGrant) DeepCopyInto(out *PostgresTableGrant) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]PostgresTablePrivilege, len(*in))
+		copy(*out, *in)
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExceptColumns != nil {
+		in, out := &in.ExceptColumns, &out.ExceptColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresTableGrant.
+func (in *PostgresTableGrant) DeepCopy() *PostgresTableGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresTableGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresTableGrantRef) DeepCopyInto(out *PostgresTableGrantRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresTableGrantRef.
+func (in *PostgresTableGrantRef) DeepCopy() *PostgresTableGrantRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresTableGrantRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresTableRef) DeepCopyInto(out *PostgresTableRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresTableRef.
+func (in *PostgresTableRef) DeepCopy() *PostgresTableRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresTableRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUser) DeepCopyInto(out *PostgresUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUser.
 func (in *PostgresUser) DeepCopy() *PostgresUser {
 	if in == nil {
@@ -207,6 +1154,36 @@ func (in *PostgresUserList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserMapping) DeepCopyInto(out *PostgresUserMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserMapping.
+func (in *PostgresUserMapping) DeepCopy() *PostgresUserMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserMappingStatus) DeepCopyInto(out *PostgresUserMappingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserMappingStatus.
+func (in *PostgresUserMappingStatus) DeepCopy() *PostgresUserMappingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserMappingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
 	*out = *in
@@ -236,6 +1213,26 @@ func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.SecretNamespaces != nil {
+		in, out := &in.SecretNamespaces, &out.SecretNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CredentialSink != nil {
+		in, out := &in.CredentialSink, &out.CredentialSink
+		*out = new(PostgresCredentialSink)
+		**out = **in
+	}
+	if in.ConnectionFormats != nil {
+		in, out := &in.ConnectionFormats, &out.ConnectionFormats
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(PostgresClientCertificate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserSpec.
@@ -251,6 +1248,11 @@ func (in *PostgresUserSpec) DeepCopy() *PostgresUserSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresUserStatus) DeepCopyInto(out *PostgresUserStatus) {
 	*out = *in
+	if in.CredentialSink != nil {
+		in, out := &in.CredentialSink, &out.CredentialSink
+		*out = new(PostgresCredentialSinkStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserStatus.
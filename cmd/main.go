@@ -166,6 +166,26 @@ func main() {
 		logger.Error(err, "unable to create controller", "controller", "PostgresUser")
 		os.Exit(1)
 	}
+	if err = (controller.NewPostgresGrantReconciler(mgr, cfg, pg)).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create controller", "controller", "PostgresGrant")
+		os.Exit(1)
+	}
+	if err = (controller.NewPostgresPublicationReconciler(mgr, cfg, pg)).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create controller", "controller", "PostgresPublication")
+		os.Exit(1)
+	}
+	if err = (controller.NewPostgresSubscriptionReconciler(mgr, cfg, pg)).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create controller", "controller", "PostgresSubscription")
+		os.Exit(1)
+	}
+	if err = (controller.NewPostgresCredentialRequestReconciler(mgr, cfg, pg)).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create controller", "controller", "PostgresCredentialRequest")
+		os.Exit(1)
+	}
+	if err = (controller.NewOrphanScanner(mgr, cfg, pg)).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create orphan scanner")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
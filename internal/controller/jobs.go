@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// jobState summarizes the outcome of a Job
+type jobState int
+
+const (
+	jobRunning jobState = iota
+	jobSucceeded
+	jobFailed
+)
+
+// maxJobNameLength keeps the generated job-name pod label below 63 characters
+const maxJobNameLength = 52
+
+func jobName(base, suffix string) string {
+	if len(base)+len(suffix)+1 > maxJobNameLength {
+		base = base[:maxJobNameLength-len(suffix)-1]
+	}
+	return fmt.Sprintf("%s-%s", base, suffix)
+}
+
+func getJobState(job *batchv1.Job) jobState {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return jobSucceeded
+		case batchv1.JobFailed:
+			return jobFailed
+		}
+	}
+	return jobRunning
+}
+
+const (
+	// jobLoginAnnotation records the temporary login role a Job connects with
+	jobLoginAnnotation = "postgres.db.movetokube.com/login-role"
+	// jobLoginValidity bounds how long the login role of a Job may log in
+	jobLoginValidity = 24 * time.Hour
+
+	jobCredentialsVolume    = "credentials"
+	jobCredentialsMountPath = "/etc/postgres-credentials"
+)
+
+// pgClientCredentials holds the operator's connection settings without its
+// login, so they can be handed to Jobs running PostgreSQL client tools.
+type pgClientCredentials struct {
+	host    string
+	uriArgs string
+	// path of the CA bundle verifying the server in the operator's pod
+	caBundle string
+}
+
+// secretData converts the credentials of a login to libpq environment variables
+func (c pgClientCredentials) secretData(user, password string) (map[string][]byte, error) {
+	hostname, port, err := net.SplitHostPort(c.host)
+	if err != nil {
+		hostname = c.host
+		port = "5432"
+	}
+	data := map[string][]byte{
+		"PGHOST":     []byte(hostname),
+		"PGPORT":     []byte(port),
+		"PGUSER":     []byte(user),
+		"PGPASSWORD": []byte(password),
+	}
+	args, err := url.ParseQuery(c.uriArgs)
+	if err != nil {
+		return nil, fmt.Errorf("parse uri args: %w", err)
+	}
+	for arg, env := range map[string]string{
+		"sslmode":         "PGSSLMODE",
+		"sslrootcert":     "PGSSLROOTCERT",
+		"connect_timeout": "PGCONNECT_TIMEOUT",
+	} {
+		if v := args.Get(arg); v != "" {
+			data[env] = []byte(v)
+		}
+	}
+	if c.caBundle != "" {
+		bundle, err := os.ReadFile(c.caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("read server CA bundle: %w", err)
+		}
+		data[caBundleKey] = bundle
+		data["PGSSLROOTCERT"] = []byte(path.Join(jobCredentialsMountPath, caBundleKey))
+	}
+	return data, nil
+}
+
+// jobLogin describes the temporary role a Job connects with. It only holds the
+// privileges of the roles in memberOf, never those of the operator.
+type jobLogin struct {
+	memberOf []string
+	// database the objects of the login are reassigned in when it is dropped
+	database string
+	tag      postgres.ObjectTag
+}
+
+// ensureClientJob creates the Job along with a short-lived login role and a secret holding
+// its credentials, the Job and secret owned by owner. Containers that need the credentials
+// reference them through credentialsEnv. An existing Job with the same name is returned as is.
+// The login must be removed with deleteJobCredentials once the Job finished.
+func ensureClientJob(ctx context.Context, c client.Client, pg postgres.PG, owner client.Object, creds pgClientCredentials, login jobLogin, job *batchv1.Job) (*batchv1.Job, error) {
+	found := &batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, found)
+	if err == nil {
+		return found, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	suffix, err := utils.GetSecureRandomString(12)
+	if err != nil {
+		return nil, err
+	}
+	password, err := utils.GetSecureRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	role := "postgres_operator_job_" + strings.ToLower(suffix)
+	user, err := pg.CreateExpiringUserRole(role, password, time.Now().Add(jobLoginValidity), login.tag)
+	if err != nil {
+		return nil, fmt.Errorf("create login role: %w", err)
+	}
+	err = createClientJob(ctx, c, pg, owner, creds, login, role, user, password, job)
+	if err != nil {
+		if dropErr := pg.DropRole(role, login.memberOf[0], login.database); dropErr != nil {
+			err = fmt.Errorf("%w, drop login role %s: %w", err, role, dropErr)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func createClientJob(ctx context.Context, c client.Client, pg postgres.PG, owner client.Object, creds pgClientCredentials, login jobLogin, role, user, password string, job *batchv1.Job) error {
+	for _, member := range login.memberOf {
+		if err := pg.GrantRole(member, role); err != nil {
+			return fmt.Errorf("grant %s to login role: %w", member, err)
+		}
+	}
+
+	data, err := creds.secretData(user, password)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialsSecretName(job.Name),
+			Namespace: job.Namespace,
+			Labels:    map[string]string{"app": owner.GetName()},
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+		secret.Data = data
+		return controllerutil.SetControllerReference(owner, secret, c.Scheme())
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := controllerutil.SetControllerReference(owner, job, c.Scheme()); err != nil {
+		return err
+	}
+	metav1.SetMetaDataAnnotation(&job.ObjectMeta, jobLoginAnnotation, role)
+	return c.Create(ctx, job)
+}
+
+// deleteJobCredentials drops the login role of a finished Job, handing the objects it
+// created over to newOwner, and removes the secret holding its credentials
+func deleteJobCredentials(ctx context.Context, c client.Client, pg postgres.PG, job *batchv1.Job, newOwner, database string) error {
+	if role := job.Annotations[jobLoginAnnotation]; role != "" {
+		if err := pg.DropRole(role, newOwner, database); err != nil {
+			return fmt.Errorf("drop login role %s: %w", role, err)
+		}
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialsSecretName(job.Name),
+			Namespace: job.Namespace,
+		},
+	}
+	return client.IgnoreNotFound(c.Delete(ctx, secret))
+}
+
+func credentialsSecretName(jobName string) string {
+	return jobName + "-credentials"
+}
+
+// credentialsVolume holds the files of the credentials of a Job, i.e. the CA bundle of the server
+func credentialsVolume(jobName string) corev1.Volume {
+	return corev1.Volume{
+		Name: jobCredentialsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: credentialsSecretName(jobName),
+				Items:      []corev1.KeyToPath{{Key: caBundleKey, Path: caBundleKey}},
+				Optional:   ptr.To(true),
+			},
+		},
+	}
+}
+
+// credentialsMount mounts the credentials volume where PGSSLROOTCERT points to
+func credentialsMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: jobCredentialsVolume, MountPath: jobCredentialsMountPath, ReadOnly: true}
+}
+
+// credentialsEnv exposes the login credentials of a Job to a container
+func credentialsEnv(jobName string) []corev1.EnvFromSource {
+	return []corev1.EnvFromSource{{
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: credentialsSecretName(jobName)},
+		},
+	}}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// objectTag identifies obj as the resource of kind a role, database or schema is created for
+func objectTag(instance, cluster, kind string, obj metav1.Object, database string) postgres.ObjectTag {
+	return postgres.ObjectTag{
+		ManagedBy: postgres.ManagedBy,
+		Cluster:   cluster,
+		Instance:  instance,
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		UID:       string(obj.GetUID()),
+		Database:  database,
+		Adopt:     utils.AdoptionRequested(obj.GetAnnotations()),
+	}
+}
+
+// foreignOwner returns the owner of a ROLE or DATABASE tagged by another resource than tag,
+// nil if the object may be dropped on behalf of tag
+func foreignOwner(pg postgres.PG, objectType, name string, tag postgres.ObjectTag) (*postgres.ObjectTag, error) {
+	if tag.Adopt {
+		return nil, nil
+	}
+	existing, err := pg.GetTag(objectType, name)
+	if err != nil || existing == nil || existing.SameOwner(tag) {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// retainObjects marks the database and roles of cr as retained when cr is deleted without dropping them,
+// so they are not taken for orphans. Objects tagged by another resource are left alone.
+func (r *PostgresReconciler) retainObjects(cr *dbv1alpha1.Postgres) error {
+	tag := objectTag(r.instanceFilter, r.cluster, "Postgres", cr, cr.Spec.Database)
+	roles := []dbv1alpha1.PostgresRoles{cr.Status.Roles}
+	for _, schemaRoles := range cr.Status.SchemaRoles {
+		roles = append(roles, schemaRoles.Roles)
+	}
+	objects := [][2]string{{"DATABASE", cr.Spec.Database}}
+	for _, group := range roles {
+		for _, role := range []string{group.Owner, group.Reader, group.Writer} {
+			if role != "" {
+				objects = append(objects, [2]string{"ROLE", role})
+			}
+		}
+	}
+
+	for _, object := range objects {
+		existing, err := r.pg.GetTag(object[0], object[1])
+		if err != nil {
+			return err
+		}
+		if existing == nil || existing.Retained || !existing.SameOwner(tag) {
+			continue
+		}
+		existing.Retained = true
+		err = r.pg.SetTag(object[0], object[1], *existing)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
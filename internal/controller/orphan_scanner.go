@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+var (
+	orphanedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postgres_operator_orphaned_objects",
+		Help: "Roles and databases created by the operator whose resource no longer exists, found by the last scan",
+	}, []string{"type"})
+	orphansDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "postgres_operator_orphans_dropped_total",
+		Help: "Number of orphaned roles and databases dropped",
+	}, []string{"type"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(orphanedObjects, orphansDropped)
+}
+
+// OrphanScanner periodically looks for roles and databases tagged by this operator instance whose
+// resource is gone, e.g. because its finalizer was removed by hand, and reports or drops them
+type OrphanScanner struct {
+	client.Client
+	pg             postgres.PG
+	instanceFilter string
+	cluster        string
+	interval       time.Duration
+	policy         config.OrphanPolicy
+}
+
+// NewOrphanScanner returns a new manager.Runnable
+func NewOrphanScanner(mgr manager.Manager, cfg *config.Cfg, pg postgres.PG) *OrphanScanner {
+	return &OrphanScanner{
+		Client:         mgr.GetClient(),
+		pg:             pg,
+		instanceFilter: cfg.AnnotationFilter,
+		cluster:        cfg.ClusterName,
+		interval:       cfg.OrphanScanInterval,
+		policy:         cfg.OrphanPolicy,
+	}
+}
+
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgres;postgresusers;postgrescredentialrequests,verbs=get;list;watch
+
+// SetupWithManager adds the scanner to the Manager, unless scanning is disabled.
+func (s *OrphanScanner) SetupWithManager(mgr ctrl.Manager) error {
+	if s.interval <= 0 {
+		return nil
+	}
+	return mgr.Add(s)
+}
+
+// NeedLeaderElection lets only the leader drop objects
+func (s *OrphanScanner) NeedLeaderElection() bool {
+	return true
+}
+
+// Start scans for orphans until ctx is done
+func (s *OrphanScanner) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("orphan-scanner")
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.scan(ctx, logger); err != nil {
+			logger.Error(err, "Could not scan for orphaned roles and databases")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *OrphanScanner) scan(ctx context.Context, logger logr.Logger) error {
+	objects, err := s.pg.GetTaggedObjects()
+	if err != nil {
+		return err
+	}
+	// Several Postgres may share a database, its roles are in use as long as one of them is left
+	databases := dbv1alpha1.PostgresList{}
+	if err := s.List(ctx, &databases); err != nil {
+		return err
+	}
+	users := dbv1alpha1.PostgresUserList{}
+	if err := s.List(ctx, &users); err != nil {
+		return err
+	}
+	requests := dbv1alpha1.PostgresCredentialRequestList{}
+	if err := s.List(ctx, &requests); err != nil {
+		return err
+	}
+
+	var orphans []postgres.TaggedObject
+	for _, object := range objects {
+		// Resources of other clusters are not visible from here
+		if object.Tag.Instance != s.instanceFilter || object.Tag.Cluster != s.cluster {
+			continue
+		}
+		// Kept on purpose, e.g. by a Postgres deleted without dropOnDelete
+		if object.Tag.Retained {
+			continue
+		}
+		var inUse bool
+		switch object.Tag.Kind {
+		case "Postgres":
+			// The database in the tag is outdated while a rename is pending, the resource is what counts
+			inUse = slices.ContainsFunc(databases.Items, func(db dbv1alpha1.Postgres) bool {
+				if !utils.MatchesInstanceAnnotation(db.Annotations, s.instanceFilter) {
+					return false
+				}
+				return db.Namespace == object.Tag.Namespace && db.Name == object.Tag.Name || db.Spec.Database == object.Tag.Database
+			})
+		case "PostgresUser":
+			inUse = slices.ContainsFunc(users.Items, func(user dbv1alpha1.PostgresUser) bool {
+				return string(user.UID) == object.Tag.UID
+			})
+		case "PostgresCredentialRequest":
+			inUse = slices.ContainsFunc(requests.Items, func(request dbv1alpha1.PostgresCredentialRequest) bool {
+				return string(request.UID) == object.Tag.UID
+			})
+		default:
+			// Left alone until the operator knows the kind
+			inUse = true
+		}
+		if !inUse {
+			orphans = append(orphans, object)
+		}
+	}
+
+	orphanedObjects.Reset()
+	for _, orphan := range orphans {
+		orphanedObjects.WithLabelValues(orphan.Type).Inc()
+	}
+	if s.policy != config.OrphanPolicyDrop {
+		for _, orphan := range orphans {
+			logger.Info(fmt.Sprintf("Found orphaned %s %s of %s %s/%s", orphan.Type, orphan.Name, orphan.Tag.Kind, orphan.Tag.Namespace, orphan.Tag.Name))
+		}
+		return nil
+	}
+
+	// Roles go first, what they own in their database is reassigned before it is dropped
+	slices.SortStableFunc(orphans, func(a, b postgres.TaggedObject) int {
+		if a.Type == b.Type {
+			return 0
+		}
+		if a.Type == "ROLE" {
+			return -1
+		}
+		return 1
+	})
+	for _, orphan := range orphans {
+		if orphan.Type == "ROLE" {
+			err = s.pg.DropRole(orphan.Name, s.pg.GetUser(), orphan.Tag.Database)
+		} else {
+			err = s.pg.DropDatabase(orphan.Name)
+		}
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("Could not drop orphaned %s %s", orphan.Type, orphan.Name))
+			continue
+		}
+		logger.Info(fmt.Sprintf("Dropped orphaned %s %s of %s %s/%s", orphan.Type, orphan.Name, orphan.Tag.Kind, orphan.Tag.Namespace, orphan.Tag.Name))
+		orphansDropped.WithLabelValues(orphan.Type).Inc()
+		orphanedObjects.WithLabelValues(orphan.Type).Dec()
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	mockpg "github.com/movetokube/postgres-operator/pkg/postgres/mock"
+)
+
+var _ = Describe("OrphanScanner", func() {
+	const namespace = "operator"
+
+	var (
+		mockCtrl *gomock.Controller
+		pg       *mockpg.MockPG
+		scanner  *OrphanScanner
+		database *dbv1alpha1.Postgres
+		user     *dbv1alpha1.PostgresUser
+		objects  []postgres.TaggedObject
+	)
+
+	tagged := func(objectType, name, kind, owner, uid, db string) postgres.TaggedObject {
+		return postgres.TaggedObject{
+			Type: objectType,
+			Name: name,
+			Tag: postgres.ObjectTag{
+				ManagedBy: postgres.ManagedBy,
+				Kind:      kind,
+				Namespace: namespace,
+				Name:      owner,
+				UID:       uid,
+				Database:  db,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		pg = mockpg.NewMockPG(mockCtrl)
+		scanner = &OrphanScanner{
+			Client: managerClient,
+			pg:     pg,
+			policy: config.OrphanPolicyReport,
+		}
+
+		database = &dbv1alpha1.Postgres{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: namespace, UID: types.UID("orders-uid")},
+			Spec:       dbv1alpha1.PostgresSpec{Database: "orders"},
+		}
+		user = &dbv1alpha1.PostgresUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-app", Namespace: namespace, UID: types.UID("orders-app-uid")},
+			Spec:       dbv1alpha1.PostgresUserSpec{Role: "app", Database: "orders", SecretName: "orders"},
+		}
+		Expect(k8sClient.Create(ctx, database)).To(Succeed())
+		Expect(k8sClient.Create(ctx, user)).To(Succeed())
+
+		objects = []postgres.TaggedObject{
+			tagged("DATABASE", "orders", "Postgres", "orders", "orders-uid", "orders"),
+			tagged("ROLE", "orders-group", "Postgres", "orders", "orders-uid", "orders"),
+			tagged("ROLE", "app-x7Fh2k", "PostgresUser", "orders-app", "orders-app-uid", "orders"),
+			// Their resources were deleted without the operator cleaning up
+			tagged("ROLE", "app-k2Lq9a", "PostgresUser", "orders-app", "deleted-uid", "orders"),
+			tagged("DATABASE", "invoices", "Postgres", "invoices", "invoices-uid", "invoices"),
+			tagged("ROLE", "invoices-group", "Postgres", "invoices", "invoices-uid", "invoices"),
+		}
+		other := tagged("ROLE", "other-x1", "PostgresUser", "other", "other-uid", "other")
+		other.Tag.Instance = "other-instance"
+		objects = append(objects, other)
+		pg.EXPECT().GetTaggedObjects().Return(objects, nil)
+		pg.EXPECT().GetUser().Return("postgres").AnyTimes()
+	})
+
+	AfterEach(func() {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, user))).To(Succeed())
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, database))).To(Succeed())
+		mockCtrl.Finish()
+	})
+
+	It("should report orphans without dropping them", func() {
+		pg.EXPECT().DropRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+
+		Expect(scanner.scan(ctx, logr.Discard())).To(Succeed())
+
+		Expect(testutil.ToFloat64(orphanedObjects.WithLabelValues("ROLE"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(orphanedObjects.WithLabelValues("DATABASE"))).To(Equal(1.0))
+	})
+
+	It("should drop orphaned roles before orphaned databases", func() {
+		scanner.policy = config.OrphanPolicyDrop
+		gomock.InOrder(
+			pg.EXPECT().DropRole("app-k2Lq9a", "postgres", "orders").Return(nil),
+			pg.EXPECT().DropRole("invoices-group", "postgres", "invoices").Return(nil),
+			pg.EXPECT().DropDatabase("invoices").Return(nil),
+		)
+
+		Expect(scanner.scan(ctx, logr.Discard())).To(Succeed())
+
+		Expect(testutil.ToFloat64(orphanedObjects.WithLabelValues("ROLE"))).To(BeZero())
+	})
+
+	It("should keep the roles of a database another Postgres still uses", func() {
+		shared := &dbv1alpha1.Postgres{
+			ObjectMeta: metav1.ObjectMeta{Name: "invoices-copy", Namespace: namespace, UID: types.UID("copy-uid")},
+			Spec:       dbv1alpha1.PostgresSpec{Database: "invoices"},
+		}
+		Expect(k8sClient.Create(ctx, shared)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, shared)).To(Succeed())
+		})
+		scanner.policy = config.OrphanPolicyDrop
+		pg.EXPECT().DropRole("app-k2Lq9a", "postgres", "orders").Return(nil)
+		pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+
+		Expect(scanner.scan(ctx, logr.Discard())).To(Succeed())
+	})
+
+	It("should keep objects retained by a Postgres deleted without dropOnDelete", func() {
+		for i := range objects {
+			if objects[i].Tag.Name == "invoices" {
+				objects[i].Tag.Retained = true
+			}
+		}
+		scanner.policy = config.OrphanPolicyDrop
+		pg.EXPECT().DropRole("app-k2Lq9a", "postgres", "orders").Return(nil)
+		pg.EXPECT().DropRole("invoices-group", gomock.Any(), gomock.Any()).Times(0)
+		pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+
+		Expect(scanner.scan(ctx, logr.Discard())).To(Succeed())
+
+		Expect(testutil.ToFloat64(orphanedObjects.WithLabelValues("DATABASE"))).To(BeZero())
+	})
+
+	It("should keep the objects of a Postgres whose database is being renamed", func() {
+		// The rename to orders-v2 has not happened yet, the objects are still tagged with orders
+		database.Spec.Database = "orders-v2"
+		Expect(k8sClient.Update(ctx, database)).To(Succeed())
+		scanner.policy = config.OrphanPolicyDrop
+		pg.EXPECT().DropRole("app-k2Lq9a", "postgres", "orders").Return(nil)
+		pg.EXPECT().DropRole("invoices-group", "postgres", "invoices").Return(nil)
+		pg.EXPECT().DropDatabase("invoices").Return(nil)
+		pg.EXPECT().DropRole("orders-group", gomock.Any(), gomock.Any()).Times(0)
+		pg.EXPECT().DropDatabase("orders").Times(0)
+
+		Expect(scanner.scan(ctx, logr.Discard())).To(Succeed())
+	})
+})
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+)
+
+const (
+	// backupPollInterval is how often a running pre-drop backup is checked
+	backupPollInterval = 15 * time.Second
+	backupVolume       = "backup"
+	backupMountPath    = "/backup"
+)
+
+// ensurePreDropBackup makes sure a pg_dump Job ran successfully for the database of cr.
+// It returns true once the backup completed, callers must not drop anything before that.
+func (r *PostgresReconciler) ensurePreDropBackup(ctx context.Context, cr *dbv1alpha1.Postgres) (bool, error) {
+	desired, err := r.newBackupJob(cr)
+	if err != nil {
+		return false, err
+	}
+	job, err := ensureClientJob(ctx, r.Client, r.pg, cr, r.pgCredentials(), r.backupLogin(cr), desired)
+	if err != nil {
+		return false, fmt.Errorf("create backup job: %w", err)
+	}
+
+	state := getJobState(job)
+	if state != jobRunning {
+		err = deleteJobCredentials(ctx, r.Client, r.pg, job, cr.Status.Roles.Owner, cr.Spec.Database)
+		if err != nil {
+			return false, fmt.Errorf("delete credentials of backup job: %w", err)
+		}
+	}
+	switch state {
+	case jobSucceeded:
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionBackupCompleted,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Succeeded",
+			Message: fmt.Sprintf("backup job %s completed", job.Name),
+		})
+		return true, nil
+	case jobFailed:
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionBackupCompleted,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("backup job %s failed, delete it to retry", job.Name),
+		})
+		return false, fmt.Errorf("backup job %s failed", job.Name)
+	default:
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionBackupCompleted,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Running",
+			Message: fmt.Sprintf("waiting for backup job %s", job.Name),
+		})
+		return false, nil
+	}
+}
+
+func (r *PostgresReconciler) pgCredentials() pgClientCredentials {
+	return pgClientCredentials{
+		host:     r.pgHost,
+		uriArgs:  r.pgUriArgs,
+		caBundle: r.serverCABundle,
+	}
+}
+
+// backupLogin lets the backup Job read the database of cr as its owner role
+func (r *PostgresReconciler) backupLogin(cr *dbv1alpha1.Postgres) jobLogin {
+	return jobLogin{
+		memberOf: []string{cr.Status.Roles.Owner},
+		database: cr.Spec.Database,
+		tag:      objectTag(r.instanceFilter, r.cluster, "Postgres", cr, cr.Spec.Database),
+	}
+}
+
+// newBackupJob builds the Job running pg_dump for cr. The dump is written in
+// pg_dump's custom format to either the configured volume or a scratch volume
+// that is uploaded to the object store afterwards.
+func (r *PostgresReconciler) newBackupJob(cr *dbv1alpha1.Postgres) (*batchv1.Job, error) {
+	spec := cr.Spec.BackupBeforeDrop
+	name := jobName(cr.Name, "backup")
+	file := fmt.Sprintf("%s-%s.dump", cr.Spec.Database, time.Now().UTC().Format("20060102150405"))
+
+	dump := corev1.Container{
+		Name:    "pg-dump",
+		Image:   spec.Image,
+		Command: []string{"/bin/sh", "-c"},
+		Env: []corev1.EnvVar{
+			{Name: "PGDATABASE", Value: cr.Spec.Database},
+			{Name: "BACKUP_FILE", Value: file},
+		},
+		EnvFrom:      credentialsEnv(name),
+		VolumeMounts: []corev1.VolumeMount{{Name: backupVolume, MountPath: backupMountPath}, credentialsMount()},
+	}
+	podSpec := corev1.PodSpec{RestartPolicy: corev1.RestartPolicyNever}
+
+	switch {
+	case spec.PersistentVolumeClaim != nil:
+		dir := path.Join(backupMountPath, spec.PersistentVolumeClaim.Path)
+		dump.Env = append(dump.Env, corev1.EnvVar{Name: "BACKUP_DIR", Value: dir})
+		dump.Args = []string{`mkdir -p "$BACKUP_DIR" && pg_dump -Fc -f "$BACKUP_DIR/$BACKUP_FILE"`}
+		podSpec.Containers = []corev1.Container{dump}
+		podSpec.Volumes = []corev1.Volume{{
+			Name: backupVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: spec.PersistentVolumeClaim.ClaimName,
+				},
+			},
+		}, credentialsVolume(name)}
+	case spec.ObjectStore != nil:
+		store := spec.ObjectStore
+		dump.Args = []string{`pg_dump -Fc -f "` + backupMountPath + `/$BACKUP_FILE"`}
+		upload := corev1.Container{
+			Name:    "upload",
+			Image:   store.Image,
+			Command: []string{"/bin/sh", "-c"},
+			Args:    []string{`aws s3 cp ${ENDPOINT:+--endpoint-url "$ENDPOINT"} "` + backupMountPath + `/$BACKUP_FILE" "$DESTINATION/$BACKUP_FILE"`},
+			Env: []corev1.EnvVar{
+				{Name: "BACKUP_FILE", Value: file},
+				{Name: "DESTINATION", Value: strings.TrimSuffix(store.URL, "/")},
+				{Name: "ENDPOINT", Value: store.Endpoint},
+			},
+			EnvFrom: []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: store.CredentialsSecret},
+				},
+			}},
+			VolumeMounts: []corev1.VolumeMount{{Name: backupVolume, MountPath: backupMountPath}},
+		}
+		podSpec.InitContainers = []corev1.Container{dump}
+		podSpec.Containers = []corev1.Container{upload}
+		podSpec.Volumes = []corev1.Volume{{
+			Name:         backupVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		}, credentialsVolume(name)}
+	default:
+		return nil, fmt.Errorf("backupBeforeDrop of %s/%s has no target", cr.Namespace, cr.Name)
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    map[string]string{"app": cr.Name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](3),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": cr.Name}},
+				Spec:       podSpec,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// clonePollInterval is how often a running clone Job is checked
+const clonePollInterval = 15 * time.Second
+
+// cloneDB creates the database of cr as a copy of its clone source. The source is used
+// as TEMPLATE, unless it has active connections: then an empty database is created and
+// filled by a pg_dump | pg_restore Job. It returns true once the copy is complete.
+func (r *PostgresReconciler) cloneDB(ctx context.Context, cr *dbv1alpha1.Postgres, owner string) (bool, error) {
+	src, err := r.cloneSource(ctx, cr)
+	if err != nil {
+		return false, err
+	}
+	source := src.database
+
+	// A previous reconcile already fell back to copying with a Job
+	job := &batchv1.Job{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: jobName(cr.Name, "clone")}, job)
+	if err == nil {
+		return r.cloneJobDone(ctx, cr, job, source, owner)
+	}
+	if !kerrors.IsNotFound(err) {
+		return false, err
+	}
+
+	tag := objectTag(r.instanceFilter, r.cluster, "Postgres", cr, cr.Spec.Database)
+	err = r.pg.CreateDBFromTemplate(cr.Spec.Database, owner, source, cr.Spec.CloneFrom.TerminateConnections, tag)
+	if err == nil {
+		cr.Status.ClonedFrom = source
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionCloned,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Template",
+			Message: fmt.Sprintf("created from template %s", source),
+		})
+		return true, nil
+	}
+	if errors.Is(err, postgres.ErrDatabaseExists) {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionCloned,
+			Status:  metav1.ConditionFalse,
+			Reason:  "DatabaseExists",
+			Message: fmt.Sprintf("%s exists already and is not copied from %s, drop it to retry", cr.Spec.Database, source),
+		})
+		return false, err
+	}
+	if !errors.Is(err, postgres.ErrDatabaseInUse) {
+		return false, err
+	}
+
+	// The source is in use, copy it into an empty database instead
+	err = r.pg.CreateDB(cr.Spec.Database, owner, tag)
+	if err != nil {
+		return false, err
+	}
+	// The job restores as owner and reads the source with the privileges of its reader role only
+	login := jobLogin{memberOf: []string{owner, src.reader}, database: cr.Spec.Database, tag: tag}
+	job, err = ensureClientJob(ctx, r.Client, r.pg, cr, r.pgCredentials(), login, r.newCloneJob(cr, source, owner))
+	if err != nil {
+		return false, fmt.Errorf("create clone job: %w", err)
+	}
+	return r.cloneJobDone(ctx, cr, job, source, owner)
+}
+
+func (r *PostgresReconciler) cloneJobDone(ctx context.Context, cr *dbv1alpha1.Postgres, job *batchv1.Job, source, owner string) (bool, error) {
+	state := getJobState(job)
+	if state != jobRunning {
+		err := deleteJobCredentials(ctx, r.Client, r.pg, job, owner, cr.Spec.Database)
+		if err != nil {
+			return false, fmt.Errorf("delete credentials of clone job: %w", err)
+		}
+	}
+	switch state {
+	case jobSucceeded:
+		cr.Status.ClonedFrom = source
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionCloned,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Copied",
+			Message: fmt.Sprintf("copied from %s by job %s", source, job.Name),
+		})
+		return true, nil
+	case jobFailed:
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionCloned,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("clone job %s failed, delete it to retry", job.Name),
+		})
+		return false, fmt.Errorf("clone job %s failed", job.Name)
+	default:
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionCloned,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Copying",
+			Message: fmt.Sprintf("%s is in use, copying it with job %s", source, job.Name),
+		})
+		return false, nil
+	}
+}
+
+// cloneSourceDB is the database a clone is copied from and the role a clone Job reads it with
+type cloneSourceDB struct {
+	database string
+	reader   string
+}
+
+// cloneSource resolves the database cr is copied from. A database without Postgres
+// resource is read by a clone Job as its owner.
+func (r *PostgresReconciler) cloneSource(ctx context.Context, cr *dbv1alpha1.Postgres) (cloneSourceDB, error) {
+	var source cloneSourceDB
+	if ref := cr.Spec.CloneFrom.Postgres; ref != "" {
+		key := types.NamespacedName{Namespace: cr.Spec.CloneFrom.Namespace, Name: ref}
+		if key.Namespace == "" {
+			key.Namespace = cr.Namespace
+		}
+		sourceCR := &dbv1alpha1.Postgres{}
+		err := r.Get(ctx, key, sourceCR)
+		if err != nil {
+			return source, err
+		}
+		if !cloneAllowed(sourceCR, cr.Namespace) {
+			return source, fmt.Errorf("clone source %s does not allow clones from namespace %s", key, cr.Namespace)
+		}
+		if !sourceCR.Status.Succeeded {
+			return source, fmt.Errorf("clone source \"%s\" is not ready", ref)
+		}
+		source = cloneSourceDB{database: sourceCR.Spec.Database, reader: sourceCR.Status.Roles.Reader}
+	} else {
+		if !slices.Contains(r.cloneSourceDatabases, cr.Spec.CloneFrom.Database) {
+			return source, fmt.Errorf("clone source \"%s\" is not listed in CLONE_SOURCE_DATABASES of the operator", cr.Spec.CloneFrom.Database)
+		}
+		state, err := r.pg.GetDatabaseState(cr.Spec.CloneFrom.Database, nil, nil)
+		if err != nil {
+			return source, err
+		}
+		if state.Owner == "" {
+			return source, fmt.Errorf("clone source \"%s\" does not exist", cr.Spec.CloneFrom.Database)
+		}
+		source = cloneSourceDB{database: cr.Spec.CloneFrom.Database, reader: state.Owner}
+	}
+	if source.database == cr.Spec.Database {
+		return source, fmt.Errorf("database \"%s\" cannot be cloned from itself", source.database)
+	}
+	return source, nil
+}
+
+// cloneAllowed checks the allow-list of source for clones into namespace
+func cloneAllowed(source *dbv1alpha1.Postgres, namespace string) bool {
+	return source.Namespace == namespace ||
+		slices.Contains(source.Spec.AllowClonesFrom, "*") ||
+		slices.Contains(source.Spec.AllowClonesFrom, namespace)
+}
+
+// newCloneJob builds the Job copying source into the database of cr. Restored objects
+// are owned by the owner role of cr, privileges of the source are not copied.
+func (r *PostgresReconciler) newCloneJob(cr *dbv1alpha1.Postgres, source, owner string) *batchv1.Job {
+	name := jobName(cr.Name, "clone")
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    map[string]string{"app": cr.Name},
+		},
+		Spec: batchv1.JobSpec{
+			// a retry would restore into a partially filled database
+			BackoffLimit: ptr.To[int32](0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": cr.Name}},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "pg-clone",
+						Image:   cr.Spec.CloneFrom.Image,
+						Command: []string{"/bin/sh", "-c"},
+						Args:    []string{`set -eo pipefail; pg_dump -Fc -d "$SOURCE" | pg_restore --no-owner --no-acl --exit-on-error --role="$OWNER" -d "$PGDATABASE"`},
+						Env: []corev1.EnvVar{
+							{Name: "SOURCE", Value: source},
+							{Name: "OWNER", Value: owner},
+							{Name: "PGDATABASE", Value: cr.Spec.Database},
+						},
+						EnvFrom:      credentialsEnv(name),
+						VolumeMounts: []corev1.VolumeMount{credentialsMount()},
+					}},
+					Volumes: []corev1.Volume{credentialsVolume(name)},
+				},
+			},
+		},
+	}
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/go-logr/logr"
 	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
@@ -24,25 +31,45 @@ import (
 // PostgresReconciler reconciles a Postgres object
 type PostgresReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	pg     postgres.PG
-	// pgHost         string
+	Scheme    *runtime.Scheme
+	pg        postgres.PG
+	pgHost    string
+	pgUriArgs string
+	// CA bundle of the server, handed to Jobs
+	serverCABundle string
 	instanceFilter string
+	cluster        string
+	// Databases without Postgres resource that may be cloned
+	cloneSourceDatabases []string
+	// Requeue period of Postgres resources to detect drift, disabled if zero
+	resyncPeriod     time.Duration
+	driftRemediation bool
 }
 
 // NewPostgresReconciler returns a new reconcile.Reconciler
 func NewPostgresReconciler(mgr manager.Manager, c *config.Cfg, pg postgres.PG) *PostgresReconciler {
+	conn := postgres.ClientConnectionArgs(c)
 	return &PostgresReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		pg:             pg,
-		instanceFilter: c.AnnotationFilter,
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		pg:                   pg,
+		pgHost:               c.PostgresHost,
+		pgUriArgs:            conn.UriArgs,
+		serverCABundle:       conn.SSLRootCert,
+		instanceFilter:       c.AnnotationFilter,
+		cluster:              c.ClusterName,
+		cloneSourceDatabases: c.CloneSourceDatabases,
+		resyncPeriod:         c.ResyncPeriod,
+		driftRemediation:     c.DriftRemediation,
 	}
 }
 
 // +kubebuilder:rbac:groups=db.movetokube.com,resources=postgres,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=db.movetokube.com,resources=postgres/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=db.movetokube.com,resources=postgres/finalizers,verbs=update
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -80,7 +107,50 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	// deletion logic
 	if !instance.GetDeletionTimestamp().IsZero() {
-		if r.shouldDropDB(ctx, instance, reqLogger) && instance.Status.Succeeded {
+		driftDetected.DeleteLabelValues(instance.Namespace, instance.Name)
+		driftRemediations.DeleteLabelValues(instance.Namespace, instance.Name)
+		// Slots retain WAL even without a consumer and keep the database from being dropped
+		if len(instance.Status.ReplicationSlots) > 0 {
+			err = r.dropReplicationSlots(instance)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		dropDB := r.shouldDropDB(ctx, instance, reqLogger) && instance.Status.Succeeded
+		if dropDB {
+			// A database taken over by another resource is left to it, along with its roles
+			tag := objectTag(r.instanceFilter, r.cluster, "Postgres", instance, instance.Spec.Database)
+			owner, err := foreignOwner(r.pg, "DATABASE", instance.Spec.Database, tag)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if owner != nil {
+				reqLogger.Info(fmt.Sprintf("Not dropping database %s, it belongs to %s", instance.Spec.Database, owner))
+				dropDB = false
+			}
+		}
+		if dropDB {
+			if instance.Spec.BackupBeforeDrop != nil {
+				done, err := r.ensurePreDropBackup(ctx, instance)
+				if patchErr := r.Status().Patch(ctx, instance, client.MergeFrom(before)); patchErr != nil {
+					reqLogger.Error(patchErr, "could not update db status")
+				}
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				if !done {
+					reqLogger.Info("Waiting for backup before dropping database")
+					return ctrl.Result{RequeueAfter: backupPollInterval}, nil
+				}
+				before = instance.DeepCopy()
+			}
+			for _, schemaRoles := range instance.Status.SchemaRoles {
+				err := r.dropSchemaRoles(schemaRoles, r.pg.GetUser(), instance.Spec.Database)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			instance.Status.SchemaRoles = nil
 			if instance.Status.Roles.Owner != "" {
 				err := r.pg.DropRole(instance.Status.Roles.Owner, r.pg.GetUser(), instance.Spec.Database)
 				if err != nil {
@@ -106,6 +176,11 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			if err != nil {
 				return ctrl.Result{}, err
 			}
+		} else {
+			err = r.retainObjects(instance)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 		err = r.Status().Patch(ctx, instance, client.MergeFrom(before))
 		if err != nil {
@@ -131,29 +206,63 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	// Follow a change of the database name before anything else refers to it
+	proceed, err := r.reconcileDatabaseName(reqLogger, instance)
+	if err != nil {
+		return requeue(errors.NewInternalError(err))
+	}
+	if !proceed {
+		reqLogger.Info("Waiting for the change of the database name to be allowed")
+		err = r.Status().Patch(ctx, instance, client.MergeFrom(before))
+		return ctrl.Result{}, err
+	}
+
+	// Things may have changed on the server since the last resync
+	if r.resyncPeriod > 0 && instance.Status.Succeeded {
+		r.reconcileDrift(reqLogger, instance)
+	}
+
 	// creation logic
 	if !instance.Status.Succeeded {
 		owner := instance.Spec.MasterRole
 		if owner == "" {
 			owner = fmt.Sprintf("%s-group", instance.Spec.Database)
 		}
+		tag := objectTag(r.instanceFilter, r.cluster, "Postgres", instance, instance.Spec.Database)
+		instance.Status.Database = instance.Spec.Database
 		// Create owner role
-		err = r.pg.CreateGroupRole(owner)
+		err = r.pg.CreateGroupRole(owner, tag)
 		if err != nil {
 			return requeue(errors.NewInternalError(err))
 		}
 		instance.Status.Roles.Owner = owner
 
 		// Create database
-		err = r.pg.CreateDB(instance.Spec.Database, owner)
-		if err != nil {
-			reqLogger.Error(err, "Could not create DB")
-			return requeue(errors.NewInternalError(err))
+		if instance.Spec.CloneFrom != nil && instance.Status.ClonedFrom == "" {
+			done, err := r.cloneDB(ctx, instance, owner)
+			if err != nil {
+				reqLogger.Error(err, "Could not clone DB")
+				return requeue(errors.NewInternalError(err))
+			}
+			if !done {
+				reqLogger.Info("Waiting for database to be copied")
+				err = r.Status().Patch(ctx, instance, client.MergeFrom(before))
+				if err != nil {
+					return requeue(err)
+				}
+				return ctrl.Result{RequeueAfter: clonePollInterval}, nil
+			}
+		} else {
+			err = r.pg.CreateDB(instance.Spec.Database, owner, tag)
+			if err != nil {
+				reqLogger.Error(err, "Could not create DB")
+				return requeue(errors.NewInternalError(err))
+			}
 		}
 
 		// Create reader role
 		reader := fmt.Sprintf("%s-reader", instance.Spec.Database)
-		err = r.pg.CreateGroupRole(reader)
+		err = r.pg.CreateGroupRole(reader, tag)
 		if err != nil {
 			return requeue(errors.NewInternalError(err))
 		}
@@ -161,7 +270,7 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 		// Create writer role
 		writer := fmt.Sprintf("%s-writer", instance.Spec.Database)
-		err = r.pg.CreateGroupRole(writer)
+		err = r.pg.CreateGroupRole(writer, tag)
 		if err != nil {
 			return requeue(errors.NewInternalError(err))
 		}
@@ -206,9 +315,6 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	// create schemas
 	var (
 		database            = instance.Spec.Database
-		owner               = instance.Status.Roles.Owner
-		reader              = instance.Status.Roles.Reader
-		writer              = instance.Status.Roles.Writer
 		readerPrivs         = "SELECT"
 		writerPrivs         = "SELECT,INSERT,DELETE,UPDATE"
 		writerSequencePrivs = "USAGE,SELECT"
@@ -217,6 +323,9 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		ownerFunctionPrivs  = "ALL"
 		ownerSequencePrivs  = "ALL"
 	)
+	// Schemas with an owner of their own are created along with their roles
+	r.reconcileSchemaOwners(reqLogger, instance)
+	schemaTag := objectTag(r.instanceFilter, r.cluster, "Postgres", instance, database)
 	for _, schema := range instance.Spec.Schemas {
 		// Schema was previously created
 		if slices.Contains(instance.Status.Schemas, schema) {
@@ -224,7 +333,7 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 
 		// Create schema
-		err = r.pg.CreateSchema(database, owner, schema)
+		err = r.pg.CreateSchema(database, rolesForSchema(instance, schema).Owner, schema, schemaTag)
 		if err != nil {
 			reqLogger.Error(err, fmt.Sprintf("Could not create schema %s", schema))
 			continue
@@ -232,51 +341,77 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		instance.Status.Schemas = append(instance.Status.Schemas, schema)
 	}
 
+	// Scripts may create the tables the grants and policies below refer to
+	migrationsDone := r.applyMigrations(ctx, reqLogger, instance)
+
+	// Revoke removed table grants first, schema privileges restore what they replaced
+	r.revokeRemovedTableGrants(reqLogger, instance)
+
 	// Set privileges on schemas during every reconcile to ensure privileges are correct
+	var schemaPrivileges []postgres.PostgresSchemaPrivileges
+	// Roles not managed by instance are reported by verifyDefaultPrivileges
+	additional, _ := additionalCreators(instance)
 	for _, schema := range instance.Spec.Schemas {
+		roles := rolesForSchema(instance, schema)
+		// Users set their role to the owner or writer role, objects they create belong to it
+		creators := append([]string{roles.Owner, roles.Writer}, additional...)
 
 		// Set privileges on schema
 		schemaPrivilegesReader := postgres.PostgresSchemaPrivileges{
 			DB:           database,
-			Role:         reader,
+			Role:         roles.Reader,
 			Schema:       schema,
 			Privs:        readerPrivs,
 			CreateSchema: false,
+			// Privileges on these tables are set by table grants
+			ExcludedTables: excludedTables(instance.Spec.TableGrants, schema, "reader"),
+			Creators:       creators,
 		}
 		err = r.pg.SetSchemaPrivileges(schemaPrivilegesReader)
 		if err != nil {
-			reqLogger.Error(err, fmt.Sprintf("Could not give %s permissions \"%s\"", reader, readerPrivs))
+			reqLogger.Error(err, fmt.Sprintf("Could not give %s permissions \"%s\"", roles.Reader, readerPrivs))
 			continue
 		}
 		schemaPrivilegesWriter := postgres.PostgresSchemaPrivileges{
 			DB:            database,
-			Role:          writer,
+			Role:          roles.Writer,
 			Schema:        schema,
 			Privs:         writerPrivs,
 			SequencePrivs: writerSequencePrivs,
 			FunctionPrivs: writerFunctionPrivs,
 			CreateSchema:  true,
+			// Privileges on these tables are set by table grants
+			ExcludedTables: excludedTables(instance.Spec.TableGrants, schema, "writer"),
+			Creators:       creators,
 		}
 		err = r.pg.SetSchemaPrivileges(schemaPrivilegesWriter)
 		if err != nil {
-			reqLogger.Error(err, fmt.Sprintf("Could not give %s permissions \"%s\", sequence privileges \"%s\", and function privileges \"%s\"", writer, writerPrivs, writerSequencePrivs, writerFunctionPrivs))
+			reqLogger.Error(err, fmt.Sprintf("Could not give %s permissions \"%s\", sequence privileges \"%s\", and function privileges \"%s\"", roles.Writer, writerPrivs, writerSequencePrivs, writerFunctionPrivs))
 			continue
 		}
 		schemaPrivilegesOwner := postgres.PostgresSchemaPrivileges{
 			DB:            database,
-			Role:          owner,
+			Role:          roles.Owner,
 			Schema:        schema,
 			Privs:         ownerPrivs,
 			SequencePrivs: ownerSequencePrivs,
 			FunctionPrivs: ownerFunctionPrivs,
 			CreateSchema:  true,
+			Creators:      creators,
 		}
 		err = r.pg.SetSchemaPrivileges(schemaPrivilegesOwner)
 		if err != nil {
-			reqLogger.Error(err, fmt.Sprintf("Could not give %s permissions \"%s\", sequence privileges \"%s\", and function privileges \"%s\"", owner, ownerPrivs, ownerSequencePrivs, ownerFunctionPrivs))
+			reqLogger.Error(err, fmt.Sprintf("Could not give %s permissions \"%s\", sequence privileges \"%s\", and function privileges \"%s\"", roles.Owner, ownerPrivs, ownerSequencePrivs, ownerFunctionPrivs))
 			continue
 		}
+		schemaPrivileges = append(schemaPrivileges, schemaPrivilegesReader, schemaPrivilegesWriter, schemaPrivilegesOwner)
 	}
+	r.verifyDefaultPrivileges(reqLogger, instance, schemaPrivileges)
+
+	r.applyTableGrants(reqLogger, instance)
+	r.reconcileRowSecurity(reqLogger, instance)
+	r.reconcileReplicationSlots(reqLogger, instance, metav1.Now())
+	foreignServersReady := r.reconcileForeignServers(ctx, reqLogger, instance)
 
 	err = r.Status().Patch(ctx, instance, client.MergeFrom(before))
 	if err != nil {
@@ -291,7 +426,15 @@ func (r *PostgresReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	reqLogger.Info("Reconciling done")
-	return ctrl.Result{}, nil
+	// Look for drift on a regular basis
+	requeueAfter := r.resyncPeriod
+	if len(instance.Spec.ReplicationSlots) > 0 || !foreignServersReady || !migrationsDone {
+		// Keep the state of the slots current and retry what waits for other resources
+		if requeueAfter == 0 || requeueAfter > pollInterval {
+			requeueAfter = pollInterval
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func (r *PostgresReconciler) addFinalizer(reqLogger logr.Logger, m *dbv1alpha1.Postgres) error {
@@ -338,6 +481,10 @@ func (r *PostgresReconciler) shouldDropDB(ctx context.Context, cr *dbv1alpha1.Po
 // SetupWithManager sets up the controller with the Manager.
 func (r *PostgresReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&dbv1alpha1.Postgres{}).
+		// Status changes, like the state of replication slots, do not need another reconcile
+		For(&dbv1alpha1.Postgres{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Owns(&batchv1.Job{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.databasesUsingSecret)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.databasesUsingConfigMap)).
 		Complete(r)
 }
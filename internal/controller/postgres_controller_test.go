@@ -2,25 +2,41 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"go.uber.org/mock/gomock"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
 	mockpg "github.com/movetokube/postgres-operator/pkg/postgres/mock"
 	"github.com/movetokube/postgres-operator/pkg/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// isJobLogin matches the temporary login roles of client Jobs
+var isJobLogin = gomock.Cond(func(role string) bool {
+	return strings.HasPrefix(role, "postgres_operator_job_")
+})
+
 var _ = Describe("PostgresReconciler", func() {
 	const (
 		name      = "test-db"
@@ -172,6 +188,7 @@ var _ = Describe("PostgresReconciler", func() {
 			})
 
 			It("should remove finalizer", func() {
+				pg.EXPECT().GetTag(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 				err := runReconcile(rp, ctx, req)
 				// No error should be returned
 				Expect(err).NotTo(HaveOccurred())
@@ -193,10 +210,45 @@ var _ = Describe("PostgresReconciler", func() {
 				// Neither DropRole nor DropDatabase should be called
 				pg.EXPECT().DropRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+				pg.EXPECT().GetTag(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 				// Call Reconcile
 				err := runReconcile(rp, ctx, req)
 				Expect(err).NotTo(HaveOccurred())
 			})
+
+			It("should mark its database and roles as retained", func() {
+				tag := postgres.ObjectTag{
+					ManagedBy: postgres.ManagedBy,
+					Kind:      "Postgres",
+					Namespace: namespace,
+					Name:      name,
+					UID:       "uid",
+					Database:  name,
+				}
+				foreign := tag
+				foreign.Namespace = "another-namespace"
+				retained := tag
+				retained.Retained = true
+				ownerTag := tag
+				pg.EXPECT().GetTag("DATABASE", name).Return(&tag, nil)
+				pg.EXPECT().GetTag("ROLE", name+"-owner").Return(&ownerTag, nil)
+				pg.EXPECT().GetTag("ROLE", name+"-reader").Return(nil, nil)
+				pg.EXPECT().GetTag("ROLE", name+"-writer").Return(&foreign, nil)
+				pg.EXPECT().SetTag("DATABASE", name, retained).Return(nil)
+				pg.EXPECT().SetTag("ROLE", name+"-owner", retained).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should keep the finalizer when the objects cannot be marked", func() {
+				pg.EXPECT().GetTag("DATABASE", name).Return(nil, fmt.Errorf("connection refused"))
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+			})
 		})
 
 		Context("DropOnDelete is enabled", func() {
@@ -210,6 +262,7 @@ var _ = Describe("PostgresReconciler", func() {
 			BeforeEach(func() {
 				// Expected function calls
 				pg.EXPECT().GetUser().Return("pguser").AnyTimes()
+				pg.EXPECT().GetTag("DATABASE", name).Return(nil, nil).AnyTimes()
 				dropGroupRole = pg.EXPECT().DropRole(name+"-owner", "pguser", name)
 				dropReaderRole = pg.EXPECT().DropRole(name+"-reader", "pguser", name)
 				dropWriterRole = pg.EXPECT().DropRole(name+"-writer", "pguser", name)
@@ -300,12 +353,205 @@ var _ = Describe("PostgresReconciler", func() {
 					dropGroupRole.Times(0)
 					dropReaderRole.Times(0)
 					dropWriterRole.Times(0)
+					pg.EXPECT().GetTag(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 					// Call Reconcile
 					err := runReconcile(rp, ctx, req)
 					Expect(err).NotTo(HaveOccurred())
 				})
 			})
 		})
+
+		Context("Database belongs to another resource", func() {
+			BeforeEach(func() {
+				pg.EXPECT().GetUser().Return("pguser").AnyTimes()
+				foreignPostgres := postgresCR.DeepCopy()
+				foreignPostgres.Spec.DropOnDelete = true
+				// Another Postgres using the same database keeps it from being dropped
+				Expect(clearPgs("another-namespace")).To(Succeed())
+				initClient(foreignPostgres, true)
+			})
+
+			It("should leave the database and its roles alone", func() {
+				foreign := &postgres.ObjectTag{
+					ManagedBy: postgres.ManagedBy,
+					Kind:      "Postgres",
+					Namespace: "another-namespace",
+					Name:      name,
+				}
+				pg.EXPECT().GetTag("DATABASE", name).Return(foreign, nil)
+				// Asked again whether the objects are to be retained
+				pg.EXPECT().GetTag(gomock.Any(), gomock.Any()).Return(foreign, nil).AnyTimes()
+				pg.EXPECT().SetTag(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().DropRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				err := cl.Get(ctx, req.NamespacedName, foundPostgres)
+				if err != nil {
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+				} else {
+					Expect(foundPostgres.GetFinalizers()).To(BeEmpty())
+				}
+			})
+
+			It("should drop an adopted database", func() {
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				foundPostgres.Annotations = map[string]string{utils.ADOPT_ANNOTATION: "true"}
+				Expect(cl.Update(ctx, foundPostgres)).To(Succeed())
+				pg.EXPECT().GetTag(gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().DropRole(gomock.Any(), "pguser", name).Return(nil).Times(3)
+				pg.EXPECT().DropDatabase(name).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+
+		Context("BackupBeforeDrop is set", func() {
+			const database = "backup-db"
+			var jobKey types.NamespacedName
+
+			setJobCondition := func(condition batchv1.JobConditionType) {
+				job := &batchv1.Job{}
+				Expect(cl.Get(ctx, jobKey, job)).To(Succeed())
+				job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+					Type:   condition,
+					Status: corev1.ConditionTrue,
+				})
+				Expect(cl.Status().Update(ctx, job)).To(Succeed())
+			}
+
+			BeforeEach(func() {
+				pg.EXPECT().GetUser().Return("pguser").AnyTimes()
+				pg.EXPECT().GetTag("DATABASE", database).Return(nil, nil).AnyTimes()
+				pg.EXPECT().CreateExpiringUserRole(isJobLogin, gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(role, _ string, _ time.Time, _ postgres.ObjectTag) (string, error) {
+						return role, nil
+					}).AnyTimes()
+				pg.EXPECT().GrantRole(name+"-owner", isJobLogin).Return(nil).AnyTimes()
+				backupPostgres := postgresCR.DeepCopy()
+				backupPostgres.Spec.Database = database
+				backupPostgres.Spec.DropOnDelete = true
+				backupPostgres.Spec.BackupBeforeDrop = &v1alpha1.PostgresBackupSpec{
+					Image: "postgres:17-alpine",
+					PersistentVolumeClaim: &v1alpha1.PostgresBackupPVCTarget{
+						ClaimName: "backups",
+					},
+				}
+				initClient(backupPostgres, true)
+				jobKey = types.NamespacedName{Name: name + "-backup", Namespace: namespace}
+			})
+
+			AfterEach(func() {
+				Expect(cl.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace(namespace))).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(namespace))).To(Succeed())
+			})
+
+			It("should start a backup job and wait for it", func() {
+				pg.EXPECT().DropRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(Equal(backupPollInterval))
+
+				job := &batchv1.Job{}
+				Expect(cl.Get(ctx, jobKey, job)).To(Succeed())
+				Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+				Expect(job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("backups"))
+				credentials := &corev1.Secret{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: jobKey.Name + "-credentials", Namespace: namespace}, credentials)).To(Succeed())
+				Expect(credentials.Data).To(HaveKey("PGPASSWORD"))
+				// The job logs in with its own role instead of the operator's
+				Expect(string(credentials.Data["PGUSER"])).To(HavePrefix("postgres_operator_job_"))
+				Expect(job.Annotations).To(HaveKeyWithValue(jobLoginAnnotation, string(credentials.Data["PGUSER"])))
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.GetFinalizers()).To(ConsistOf("finalizer.db.movetokube.com"))
+				Expect(meta.IsStatusConditionFalse(foundPostgres.Status.Conditions, v1alpha1.ConditionBackupCompleted)).To(BeTrue())
+			})
+
+			It("should hand the CA bundle of the server to the job", func() {
+				bundle := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+				Expect(os.WriteFile(bundle, []byte("server CA"), 0o600)).To(Succeed())
+				rp.serverCABundle = bundle
+				rp.pgUriArgs = "sslmode=verify-full"
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				credentials := &corev1.Secret{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: jobKey.Name + "-credentials", Namespace: namespace}, credentials)).To(Succeed())
+				Expect(credentials.Data).To(HaveKeyWithValue("ca.crt", []byte("server CA")))
+				Expect(credentials.Data).To(HaveKeyWithValue("PGSSLMODE", []byte("verify-full")))
+				Expect(credentials.Data).To(HaveKeyWithValue("PGSSLROOTCERT", []byte("/etc/postgres-credentials/ca.crt")))
+				job := &batchv1.Job{}
+				Expect(cl.Get(ctx, jobKey, job)).To(Succeed())
+				Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(credentialsMount()))
+				Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(credentialsVolume(jobKey.Name)))
+			})
+
+			It("should drop the job login when the job cannot be set up", func() {
+				ctrl := gomock.NewController(GinkgoT())
+				failingPG := mockpg.NewMockPG(ctrl)
+				failingPG.EXPECT().GetUser().Return("pguser").AnyTimes()
+				failingPG.EXPECT().GetTag("DATABASE", database).Return(nil, nil).AnyTimes()
+				failingPG.EXPECT().CreateExpiringUserRole(isJobLogin, gomock.Any(), gomock.Any(), gomock.Any()).Return("login", nil)
+				failingPG.EXPECT().GrantRole(name+"-owner", isJobLogin).Return(fmt.Errorf("permission denied"))
+				failingPG.EXPECT().DropRole(isJobLogin, name+"-owner", database).Return(nil)
+				failingPG.EXPECT().DropDatabase(gomock.Any()).Times(0)
+				rp.pg = failingPG
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+				err := cl.Get(ctx, jobKey, &batchv1.Job{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should drop the database once the backup completed", func() {
+				pg.EXPECT().DropRole(isJobLogin, name+"-owner", database).Return(nil)
+				pg.EXPECT().DropRole(gomock.Any(), "pguser", database).Return(nil).Times(3)
+				pg.EXPECT().DropDatabase(database).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				setJobCondition(batchv1.JobComplete)
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				credentials := &corev1.Secret{}
+				err := cl.Get(ctx, types.NamespacedName{Name: jobKey.Name + "-credentials", Namespace: namespace}, credentials)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				err = cl.Get(ctx, req.NamespacedName, foundPostgres)
+				if err != nil {
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+				} else {
+					Expect(foundPostgres.GetFinalizers()).To(BeEmpty())
+				}
+			})
+
+			It("should not drop anything but the job login when the backup failed", func() {
+				pg.EXPECT().DropRole(isJobLogin, name+"-owner", database).Return(nil)
+				pg.EXPECT().DropRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().DropDatabase(gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				setJobCondition(batchv1.JobFailed)
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				credentials := &corev1.Secret{}
+				err := cl.Get(ctx, types.NamespacedName{Name: jobKey.Name + "-credentials", Namespace: namespace}, credentials)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.GetFinalizers()).To(ConsistOf("finalizer.db.movetokube.com"))
+				condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionBackupCompleted)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Reason).To(Equal("Failed"))
+			})
+		})
 	})
 
 	Describe("Checking creation logic", func() {
@@ -332,11 +578,11 @@ var _ = Describe("PostgresReconciler", func() {
 			It("should pick a default role name", func() {
 				// CreateGroupRole we're after
 				expectedName := name + "-group"
-				pg.EXPECT().CreateGroupRole(expectedName).Return(nil)
+				pg.EXPECT().CreateGroupRole(expectedName, gomock.Any()).Return(nil)
 				// Rest of CreateGroupRole calls (reader and writer)
-				pg.EXPECT().CreateGroupRole(gomock.Any()).Return(nil).AnyTimes()
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 				// CreateDB call
-				pg.EXPECT().CreateDB(name, expectedName).Return(nil)
+				pg.EXPECT().CreateDB(name, expectedName, gomock.Any()).Return(nil)
 				// Call Reconcile
 				err := runReconcile(rp, ctx, req)
 				Expect(err).NotTo(HaveOccurred())
@@ -354,11 +600,11 @@ var _ = Describe("PostgresReconciler", func() {
 			It("should use name in MasterRole", func() {
 				// CreateGroupRole we're after
 				expectedName := "my-master-role"
-				pg.EXPECT().CreateGroupRole(expectedName).Return(nil)
+				pg.EXPECT().CreateGroupRole(expectedName, gomock.Any()).Return(nil)
 				// Rest of CreateGroupRole calls (reader and writer)
-				pg.EXPECT().CreateGroupRole(gomock.Any()).Return(nil).AnyTimes()
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 				// CreateDB call
-				pg.EXPECT().CreateDB(name, expectedName).Return(nil)
+				pg.EXPECT().CreateDB(name, expectedName, gomock.Any()).Return(nil)
 				// Call Reconcile
 				err := runReconcile(rp, ctx, req)
 				Expect(err).NotTo(HaveOccurred())
@@ -382,8 +628,8 @@ var _ = Describe("PostgresReconciler", func() {
 			})
 
 			It("should create the database", func() {
-				pg.EXPECT().CreateGroupRole(gomock.Any()).Return(nil).Times(3)
-				pg.EXPECT().CreateDB(name, gomock.Any()).Return(nil)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				pg.EXPECT().CreateDB(name, gomock.Any(), gomock.Any()).Return(nil)
 				// Call Reconcile
 				err := runReconcile(rp, ctx, req)
 				Expect(err).NotTo(HaveOccurred())
@@ -411,8 +657,8 @@ var _ = Describe("PostgresReconciler", func() {
 			BeforeEach(func() {
 				initClient(postgresCR, false)
 				// Expected function calls
-				pg.EXPECT().CreateGroupRole(gomock.Any()).Return(nil).Times(3)
-				pg.EXPECT().CreateDB(name, gomock.Any()).Return(nil)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				pg.EXPECT().CreateDB(name, gomock.Any(), gomock.Any()).Return(nil)
 			})
 
 			It("should update status", func() {
@@ -448,8 +694,8 @@ var _ = Describe("PostgresReconciler", func() {
 			BeforeEach(func() {
 				initClient(postgresCR.DeepCopy(), false)
 				// Expected function calls
-				pg.EXPECT().CreateGroupRole(gomock.Any()).Return(nil).Times(1)
-				pg.EXPECT().CreateDB(name, gomock.Any()).Return(fmt.Errorf("Could not create database"))
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				pg.EXPECT().CreateDB(name, gomock.Any(), gomock.Any()).Return(fmt.Errorf("Could not create database"))
 			})
 
 			It("should not mark status as successful", func() {
@@ -468,6 +714,189 @@ var _ = Describe("PostgresReconciler", func() {
 				Expect(foundPostgres.Status.Succeeded).To(BeFalse())
 			})
 		})
+
+		Context("CloneFrom is set", func() {
+			const source = "source-db"
+			jobKey := types.NamespacedName{Name: name + "-clone", Namespace: namespace}
+
+			BeforeEach(func() {
+				clonePostgres := postgresCR.DeepCopy()
+				clonePostgres.Spec.CloneFrom = &v1alpha1.PostgresCloneSource{
+					Database: source,
+					Image:    "postgres:17-alpine",
+				}
+				initClient(clonePostgres, false)
+				rp.cloneSourceDatabases = []string{source}
+				pg.EXPECT().GetUser().Return("pguser").AnyTimes()
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+				pg.EXPECT().GetDatabaseState(source, nil, nil).Return(postgres.PostgresDatabaseState{Owner: "source-owner"}, nil).AnyTimes()
+			})
+
+			AfterEach(func() {
+				Expect(cl.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace(namespace))).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(namespace))).To(Succeed())
+			})
+
+			It("should create the database from the source as template", func() {
+				pg.EXPECT().CreateDBFromTemplate(name, name+"-group", source, false, gomock.Any()).Return(nil)
+				pg.EXPECT().CreateDB(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Succeeded).To(BeTrue())
+				Expect(foundPostgres.Status.ClonedFrom).To(Equal(source))
+				Expect(meta.IsStatusConditionTrue(foundPostgres.Status.Conditions, v1alpha1.ConditionCloned)).To(BeTrue())
+			})
+
+			It("should not clone a database the operator does not list", func() {
+				rp.cloneSourceDatabases = []string{"other-db"}
+				pg.EXPECT().CreateDBFromTemplate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().CreateDB(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(MatchError(ContainSubstring("CLONE_SOURCE_DATABASES")))
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Succeeded).To(BeFalse())
+			})
+
+			It("should not record a database that existed already as cloned", func() {
+				pg.EXPECT().CreateDBFromTemplate(name, gomock.Any(), source, false, gomock.Any()).
+					Return(fmt.Errorf("%w: %s", postgres.ErrDatabaseExists, name))
+				pg.EXPECT().CreateDB(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Succeeded).To(BeFalse())
+				Expect(foundPostgres.Status.ClonedFrom).To(BeEmpty())
+				condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionCloned)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Reason).To(Equal("DatabaseExists"))
+			})
+
+			It("should copy the database with a job when the source is in use", func() {
+				pg.EXPECT().CreateDBFromTemplate(name, gomock.Any(), source, false, gomock.Any()).Return(postgres.ErrDatabaseInUse)
+				pg.EXPECT().CreateDB(name, name+"-group", gomock.Any()).Return(nil)
+				pg.EXPECT().CreateExpiringUserRole(isJobLogin, gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(role, _ string, _ time.Time, _ postgres.ObjectTag) (string, error) {
+						return role, nil
+					})
+				pg.EXPECT().GrantRole(name+"-group", isJobLogin).Return(nil)
+				// The source is read as its owner, not as the operator
+				pg.EXPECT().GrantRole("source-owner", isJobLogin).Return(nil)
+				pg.EXPECT().DropRole(isJobLogin, name+"-group", name).Return(nil)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(Equal(clonePollInterval))
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Succeeded).To(BeFalse())
+				Expect(foundPostgres.Status.ClonedFrom).To(BeEmpty())
+
+				job := &batchv1.Job{}
+				Expect(cl.Get(ctx, jobKey, job)).To(Succeed())
+				Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "SOURCE", Value: source}))
+				job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+					Type:   batchv1.JobComplete,
+					Status: corev1.ConditionTrue,
+				})
+				Expect(cl.Status().Update(ctx, job)).To(Succeed())
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Succeeded).To(BeTrue())
+				Expect(foundPostgres.Status.ClonedFrom).To(Equal(source))
+
+				credentials := &corev1.Secret{}
+				err = cl.Get(ctx, types.NamespacedName{Name: jobKey.Name + "-credentials", Namespace: namespace}, credentials)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		Context("CloneFrom references a Postgres in another namespace", func() {
+			const sourceNamespace = "clone-sources"
+			var sourcePostgres *v1alpha1.Postgres
+
+			BeforeEach(func() {
+				err := cl.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sourceNamespace}})
+				if err != nil {
+					Expect(errors.IsAlreadyExists(err)).To(BeTrue())
+				}
+				sourcePostgres = &v1alpha1.Postgres{
+					ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: sourceNamespace},
+					Spec:       v1alpha1.PostgresSpec{Database: "source-db"},
+					Status: v1alpha1.PostgresStatus{
+						Succeeded: true,
+						Roles:     v1alpha1.PostgresRoles{Reader: "source-db-reader"},
+					},
+				}
+				clonePostgres := postgresCR.DeepCopy()
+				clonePostgres.Spec.CloneFrom = &v1alpha1.PostgresCloneSource{Postgres: "source", Namespace: sourceNamespace}
+				initClient(clonePostgres, false)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			})
+
+			AfterEach(func() {
+				Expect(clearPgs(sourceNamespace)).To(Succeed())
+			})
+
+			It("should not clone it without being allowed", func() {
+				initClient(sourcePostgres, false)
+				pg.EXPECT().CreateDBFromTemplate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(MatchError(ContainSubstring("does not allow clones from namespace " + namespace)))
+			})
+
+			It("should clone it when allowClonesFrom lists the namespace", func() {
+				sourcePostgres.Spec.AllowClonesFrom = []string{namespace}
+				initClient(sourcePostgres, false)
+				pg.EXPECT().CreateDBFromTemplate(name, gomock.Any(), "source-db", false, gomock.Any()).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+
+		Context("CloneFrom references another Postgres", func() {
+			BeforeEach(func() {
+				sourcePostgres := &v1alpha1.Postgres{
+					ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: namespace},
+					Spec:       v1alpha1.PostgresSpec{Database: "source-db"},
+					Status: v1alpha1.PostgresStatus{
+						Succeeded: true,
+						Roles:     v1alpha1.PostgresRoles{Reader: "source-db-reader"},
+					},
+				}
+				initClient(sourcePostgres, false)
+				clonePostgres := postgresCR.DeepCopy()
+				clonePostgres.Spec.CloneFrom = &v1alpha1.PostgresCloneSource{Postgres: "source"}
+				initClient(clonePostgres, false)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			})
+
+			It("should clone its database", func() {
+				pg.EXPECT().CreateDBFromTemplate(name, gomock.Any(), "source-db", false, gomock.Any()).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should let the clone job read the source as its reader role", func() {
+				pg.EXPECT().CreateDBFromTemplate(name, gomock.Any(), "source-db", false, gomock.Any()).Return(postgres.ErrDatabaseInUse)
+				pg.EXPECT().CreateDB(name, name+"-group", gomock.Any()).Return(nil)
+				pg.EXPECT().CreateExpiringUserRole(isJobLogin, gomock.Any(), gomock.Any(), gomock.Any()).Return("login", nil)
+				pg.EXPECT().GrantRole(name+"-group", isJobLogin).Return(nil)
+				pg.EXPECT().GrantRole("source-db-reader", isJobLogin).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace(namespace))).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(namespace))).To(Succeed())
+			})
+		})
 	})
 
 	Describe("Checking extensions logic", func() {
@@ -586,6 +1015,8 @@ var _ = Describe("PostgresReconciler", func() {
 	Describe("Checking schemas logic", func() {
 		var postgresCR *v1alpha1.Postgres
 		BeforeEach(func() {
+			// Verified by the default privileges tests
+			pg.EXPECT().GetDefaultPrivileges(name, gomock.Any()).Return(nil, nil).AnyTimes()
 			postgresCR = &v1alpha1.Postgres{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
@@ -613,7 +1044,7 @@ var _ = Describe("PostgresReconciler", func() {
 
 			It("should not try to create schemas", func() {
 				// CreateSchema should not be called
-				pg.EXPECT().CreateSchema(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				pg.EXPECT().CreateSchema(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				// Call Reconcile
 				err := runReconcile(rp, ctx, req)
 				Expect(err).NotTo(HaveOccurred())
@@ -642,10 +1073,10 @@ var _ = Describe("PostgresReconciler", func() {
 				BeforeEach(func() {
 					// Expected method calls
 					// customers schema
-					pg.EXPECT().CreateSchema(name, name+"-group", "customers").Return(nil).Times(1)
+					pg.EXPECT().CreateSchema(name, name+"-group", "customers", gomock.Any()).Return(nil).Times(1)
 					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(3)
 					// stores schema
-					pg.EXPECT().CreateSchema(name, name+"-group", "stores").Return(nil).Times(1)
+					pg.EXPECT().CreateSchema(name, name+"-group", "stores", gomock.Any()).Return(nil).Times(1)
 					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(3)
 				})
 
@@ -664,10 +1095,10 @@ var _ = Describe("PostgresReconciler", func() {
 				BeforeEach(func() {
 					// Expected method calls
 					// customers schema errors
-					pg.EXPECT().CreateSchema(name, name+"-group", "customers").Return(fmt.Errorf("Could not create schema")).Times(1)
+					pg.EXPECT().CreateSchema(name, name+"-group", "customers", gomock.Any()).Return(fmt.Errorf("Could not create schema")).Times(1)
 					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(0)
 					// stores schema
-					pg.EXPECT().CreateSchema(name, name+"-group", "stores").Return(nil).Times(1)
+					pg.EXPECT().CreateSchema(name, name+"-group", "stores", gomock.Any()).Return(nil).Times(1)
 					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).AnyTimes()
 				})
 
@@ -695,10 +1126,10 @@ var _ = Describe("PostgresReconciler", func() {
 			Context("Creation is successful", func() {
 				It("should not recreate existing schema", func() {
 					// customers schema
-					pg.EXPECT().CreateSchema(name, name+"-group", "customers").Return(nil).Times(1)
+					pg.EXPECT().CreateSchema(name, name+"-group", "customers", gomock.Any()).Return(nil).Times(1)
 					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).AnyTimes()
 					// stores schema already exists
-					pg.EXPECT().CreateSchema(name, name+"-group", "stores").Times(0)
+					pg.EXPECT().CreateSchema(name, name+"-group", "stores", gomock.Any()).Times(0)
 					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(0)
 					// Call reconcile
 					err := runReconcile(rp, ctx, req)
@@ -711,4 +1142,1397 @@ var _ = Describe("PostgresReconciler", func() {
 			})
 		})
 	})
+
+	Describe("Checking table grants logic", func() {
+		var postgresCR *v1alpha1.Postgres
+		BeforeEach(func() {
+			// Verified by the default privileges tests
+			pg.EXPECT().GetDefaultPrivileges(name, gomock.Any()).Return(nil, nil).AnyTimes()
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database: name,
+					Schemas:  []string{"public"},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+					Schemas: []string{"public"},
+				},
+			}
+		})
+
+		Context("Postgres has table grants", func() {
+			BeforeEach(func() {
+				grantPostgres := postgresCR.DeepCopy()
+				grantPostgres.Spec.TableGrants = []v1alpha1.PostgresTableGrant{{
+					Schema:        "public",
+					Table:         "customers",
+					Role:          "reader",
+					Privileges:    []v1alpha1.PostgresTablePrivilege{"SELECT"},
+					ExceptColumns: []string{"email"},
+				}}
+				initClient(grantPostgres, false)
+			})
+
+			It("should exclude the table from schema privileges of the role", func() {
+				pg.EXPECT().SetSchemaPrivileges(gomock.Cond(func(p postgres.PostgresSchemaPrivileges) bool {
+					return p.Role == name+"-reader"
+				})).DoAndReturn(func(p postgres.PostgresSchemaPrivileges) error {
+					Expect(p.ExcludedTables).To(ConsistOf("customers"))
+					return nil
+				})
+				pg.EXPECT().SetSchemaPrivileges(gomock.Cond(func(p postgres.PostgresSchemaPrivileges) bool {
+					return p.Role != name+"-reader"
+				})).DoAndReturn(func(p postgres.PostgresSchemaPrivileges) error {
+					Expect(p.ExcludedTables).To(BeEmpty())
+					return nil
+				}).Times(2)
+				pg.EXPECT().SetTablePrivileges(postgres.PostgresTableGrant{
+					DB:            name,
+					Role:          name + "-reader",
+					Schema:        "public",
+					Table:         "customers",
+					Privs:         []string{"SELECT"},
+					ExceptColumns: []string{"email"},
+				}).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.TableGrants).To(ConsistOf(v1alpha1.PostgresTableGrantRef{
+					Schema: "public",
+					Table:  "customers",
+					Role:   "reader",
+				}))
+			})
+
+			It("should not record grants that failed", func() {
+				pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(3)
+				pg.EXPECT().SetTablePrivileges(gomock.Any()).Return(fmt.Errorf("relation does not exist"))
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.TableGrants).To(BeEmpty())
+			})
+		})
+
+		Context("Table grant was removed", func() {
+			BeforeEach(func() {
+				grantPostgres := postgresCR.DeepCopy()
+				grantPostgres.Status.TableGrants = []v1alpha1.PostgresTableGrantRef{{
+					Schema: "public",
+					Table:  "customers",
+					Role:   "writer",
+				}}
+				initClient(grantPostgres, false)
+			})
+
+			It("should revoke it and restore schema privileges", func() {
+				gomock.InOrder(
+					pg.EXPECT().RevokeTablePrivileges(name, "public", "customers", name+"-writer").Return(nil),
+					pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(3),
+				)
+				pg.EXPECT().SetTablePrivileges(gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.TableGrants).To(BeEmpty())
+			})
+
+			It("should keep it in status when revoking fails", func() {
+				pg.EXPECT().RevokeTablePrivileges(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("connection refused"))
+				pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).Times(3)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.TableGrants).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("Checking row security logic", func() {
+		var (
+			postgresCR *v1alpha1.Postgres
+			desired    postgres.PostgresPolicy
+			stored     postgres.PostgresPolicy
+		)
+		BeforeEach(func() {
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database: name,
+					RowSecurity: []v1alpha1.PostgresRowSecurity{{
+						Schema: "public",
+						Table:  "orders",
+						Policies: []v1alpha1.PostgresPolicy{{
+							Name:    "tenant",
+							Command: "ALL",
+							Roles:   []v1alpha1.PostgresPolicyRole{"writer", "reader"},
+							Using:   "tenant_id = current_setting('app.tenant')::int",
+						}},
+					}},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+				},
+			}
+			desired = postgres.PostgresPolicy{
+				Schema:  "public",
+				Table:   "orders",
+				Name:    "tenant",
+				Command: "ALL",
+				Roles:   []string{name + "-reader", name + "-writer"},
+				Using:   "tenant_id = current_setting('app.tenant')::int",
+			}
+			stored = desired
+			stored.Using = "(tenant_id = (current_setting('app.tenant'::text))::integer)"
+		})
+
+		Context("Policy was not created yet", func() {
+			BeforeEach(func() {
+				initClient(postgresCR, false)
+			})
+
+			It("should enable row security and create the policy", func() {
+				pg.EXPECT().EnableRowSecurity(name, "public", "orders", false).Return(nil)
+				pg.EXPECT().GetPolicies(name, "public", "orders").Return(nil, nil)
+				pg.EXPECT().ReplacePolicy(name, desired).Return(stored, nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Policies).To(HaveLen(1))
+				Expect(foundPostgres.Status.Policies[0].Name).To(Equal("tenant"))
+				Expect(foundPostgres.Status.Policies[0].Using).To(Equal(stored.Using))
+				Expect(foundPostgres.Status.Policies[0].Hash).To(Equal(policyHash(desired)))
+				Expect(foundPostgres.Status.RowSecurityTables).To(ConsistOf(v1alpha1.PostgresTableRef{Schema: "public", Table: "orders"}))
+			})
+
+			It("should not record a policy that could not be created", func() {
+				pg.EXPECT().EnableRowSecurity(name, "public", "orders", false).Return(nil)
+				pg.EXPECT().GetPolicies(name, "public", "orders").Return(nil, nil)
+				pg.EXPECT().ReplacePolicy(name, desired).Return(postgres.PostgresPolicy{}, fmt.Errorf("syntax error"))
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Policies).To(BeEmpty())
+			})
+		})
+
+		Context("Policy was created before", func() {
+			BeforeEach(func() {
+				appliedPostgres := postgresCR.DeepCopy()
+				appliedPostgres.Status.Policies = []v1alpha1.PostgresPolicyStatus{{
+					Schema: "public",
+					Table:  "orders",
+					Name:   "tenant",
+					Hash:   policyHash(desired),
+					Using:  stored.Using,
+				}}
+				initClient(appliedPostgres, false)
+				pg.EXPECT().EnableRowSecurity(name, "public", "orders", false).Return(nil)
+			})
+
+			It("should leave an unchanged policy alone", func() {
+				pg.EXPECT().GetPolicies(name, "public", "orders").Return([]postgres.PostgresPolicy{stored}, nil)
+				pg.EXPECT().ReplacePolicy(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should recreate a policy that was altered", func() {
+				altered := stored
+				altered.Using = "true"
+				pg.EXPECT().GetPolicies(name, "public", "orders").Return([]postgres.PostgresPolicy{altered}, nil)
+				pg.EXPECT().ReplacePolicy(name, desired).Return(stored, nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should recreate a policy that was dropped", func() {
+				pg.EXPECT().GetPolicies(name, "public", "orders").Return(nil, nil)
+				pg.EXPECT().ReplacePolicy(name, desired).Return(stored, nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+
+		Context("Policy was removed from spec", func() {
+			BeforeEach(func() {
+				removedPostgres := postgresCR.DeepCopy()
+				removedPostgres.Spec.RowSecurity[0].Policies = nil
+				removedPostgres.Status.Policies = []v1alpha1.PostgresPolicyStatus{{
+					Schema: "public",
+					Table:  "orders",
+					Name:   "tenant",
+					Hash:   policyHash(desired),
+				}}
+				initClient(removedPostgres, false)
+				pg.EXPECT().EnableRowSecurity(name, "public", "orders", false).Return(nil)
+				pg.EXPECT().GetPolicies(name, "public", "orders").Return([]postgres.PostgresPolicy{stored}, nil)
+			})
+
+			It("should drop the policy", func() {
+				pg.EXPECT().DropPolicy(name, "public", "orders", "tenant").Return(nil)
+				pg.EXPECT().DisableRowSecurity(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Policies).To(BeEmpty())
+			})
+		})
+
+		Context("Table was removed from spec", func() {
+			var removedPostgres *v1alpha1.Postgres
+
+			BeforeEach(func() {
+				removedPostgres = postgresCR.DeepCopy()
+				removedPostgres.Spec.RowSecurity = nil
+				removedPostgres.Status.RowSecurityTables = []v1alpha1.PostgresTableRef{{Schema: "public", Table: "orders"}}
+				removedPostgres.Status.Policies = []v1alpha1.PostgresPolicyStatus{{
+					Schema: "public",
+					Table:  "orders",
+					Name:   "tenant",
+					Hash:   policyHash(desired),
+				}}
+				pg.EXPECT().EnableRowSecurity(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			})
+
+			It("should drop its policies and disable row security", func() {
+				initClient(removedPostgres, false)
+				gomock.InOrder(
+					pg.EXPECT().DropPolicy(name, "public", "orders", "tenant").Return(nil),
+					pg.EXPECT().DisableRowSecurity(name, "public", "orders").Return(nil),
+				)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Policies).To(BeEmpty())
+				Expect(foundPostgres.Status.RowSecurityTables).To(BeEmpty())
+			})
+
+			It("should disable row security on tables only known from their policies", func() {
+				removedPostgres.Status.RowSecurityTables = nil
+				initClient(removedPostgres, false)
+				pg.EXPECT().DropPolicy(name, "public", "orders", "tenant").Return(nil)
+				pg.EXPECT().DisableRowSecurity(name, "public", "orders").Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should retry when row security cannot be disabled", func() {
+				initClient(removedPostgres, false)
+				pg.EXPECT().DropPolicy(name, "public", "orders", "tenant").Return(nil)
+				pg.EXPECT().DisableRowSecurity(name, "public", "orders").Return(fmt.Errorf("connection refused"))
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.RowSecurityTables).To(ConsistOf(v1alpha1.PostgresTableRef{Schema: "public", Table: "orders"}))
+			})
+		})
+	})
+
+	Describe("Checking replication slot logic", func() {
+		var postgresCR *v1alpha1.Postgres
+
+		BeforeEach(func() {
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database: name,
+					ReplicationSlots: []v1alpha1.PostgresReplicationSlot{{
+						Name:              "orders_cdc",
+						Plugin:            "pgoutput",
+						DropInactiveAfter: &metav1.Duration{Duration: time.Hour},
+					}},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+				},
+			}
+		})
+
+		Context("Slot does not exist", func() {
+			BeforeEach(func() {
+				initClient(postgresCR, false)
+			})
+
+			It("should create the slot and poll its state", func() {
+				pg.EXPECT().GetReplicationSlots(name).Return(nil, nil)
+				pg.EXPECT().CreateReplicationSlot(name, "orders_cdc", "pgoutput").Return(nil)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(Equal(pollInterval))
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ReplicationSlots).To(HaveLen(1))
+				Expect(foundPostgres.Status.ReplicationSlots[0].Name).To(Equal("orders_cdc"))
+				Expect(foundPostgres.Status.ReplicationSlots[0].InactiveSince).NotTo(BeNil())
+			})
+
+			It("should not record a slot that could not be created", func() {
+				pg.EXPECT().GetReplicationSlots(name).Return(nil, nil)
+				pg.EXPECT().CreateReplicationSlot(name, "orders_cdc", "pgoutput").Return(fmt.Errorf("wal_level is not logical"))
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ReplicationSlots).To(BeEmpty())
+			})
+		})
+
+		Context("Slot exists", func() {
+			var inactiveSince metav1.Time
+
+			BeforeEach(func() {
+				inactiveSince = metav1.NewTime(time.Now().Add(-2 * time.Hour).Truncate(time.Second))
+				createdPostgres := postgresCR.DeepCopy()
+				createdPostgres.Status.ReplicationSlots = []v1alpha1.PostgresReplicationSlotStatus{{
+					Name:          "orders_cdc",
+					Plugin:        "pgoutput",
+					InactiveSince: &inactiveSince,
+				}}
+				initClient(createdPostgres, false)
+			})
+
+			It("should report the state of an active slot", func() {
+				pg.EXPECT().GetReplicationSlots(name).Return([]postgres.PostgresReplicationSlot{{
+					Name: "orders_cdc", Plugin: "pgoutput", Active: true, LagBytes: 4096,
+				}}, nil)
+				pg.EXPECT().CreateReplicationSlot(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ReplicationSlots).To(HaveLen(1))
+				Expect(foundPostgres.Status.ReplicationSlots[0].Active).To(BeTrue())
+				Expect(foundPostgres.Status.ReplicationSlots[0].LagBytes).To(Equal(int64(4096)))
+				Expect(foundPostgres.Status.ReplicationSlots[0].InactiveSince).To(BeNil())
+			})
+
+			It("should recreate a slot inactive for longer than the threshold", func() {
+				pg.EXPECT().GetReplicationSlots(name).Return([]postgres.PostgresReplicationSlot{{
+					Name: "orders_cdc", Plugin: "pgoutput", LagBytes: 1 << 30,
+				}}, nil)
+				gomock.InOrder(
+					pg.EXPECT().DropReplicationSlot(name, "orders_cdc").Return(nil),
+					pg.EXPECT().CreateReplicationSlot(name, "orders_cdc", "pgoutput").Return(nil),
+				)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ReplicationSlots[0].LagBytes).To(BeZero())
+				Expect(foundPostgres.Status.ReplicationSlots[0].InactiveSince.Time).To(BeTemporally(">", inactiveSince.Time))
+			})
+
+			It("should keep an inactive slot without a threshold", func() {
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				foundPostgres.Spec.ReplicationSlots[0].DropInactiveAfter = nil
+				Expect(cl.Update(ctx, foundPostgres)).To(Succeed())
+				pg.EXPECT().GetReplicationSlots(name).Return([]postgres.PostgresReplicationSlot{{
+					Name: "orders_cdc", Plugin: "pgoutput", LagBytes: 1 << 30,
+				}}, nil)
+				pg.EXPECT().DropReplicationSlot(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ReplicationSlots[0].InactiveSince.Time).To(BeTemporally("==", inactiveSince.Time))
+			})
+
+			It("should drop a slot removed from spec", func() {
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				foundPostgres.Spec.ReplicationSlots = nil
+				Expect(cl.Update(ctx, foundPostgres)).To(Succeed())
+				pg.EXPECT().GetReplicationSlots(name).Return([]postgres.PostgresReplicationSlot{{
+					Name: "orders_cdc", Plugin: "pgoutput",
+				}}, nil)
+				pg.EXPECT().DropReplicationSlot(name, "orders_cdc").Return(nil)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeZero())
+
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ReplicationSlots).To(BeEmpty())
+			})
+		})
+
+		Context("Postgres is deleted", func() {
+			BeforeEach(func() {
+				deletedPostgres := postgresCR.DeepCopy()
+				deletedPostgres.Status.ReplicationSlots = []v1alpha1.PostgresReplicationSlotStatus{{
+					Name:   "orders_cdc",
+					Plugin: "pgoutput",
+				}}
+				initClient(deletedPostgres, true)
+			})
+
+			It("should drop the slots", func() {
+				pg.EXPECT().DropReplicationSlot(name, "orders_cdc").Return(nil)
+				pg.EXPECT().GetTag(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should keep the finalizer when a slot cannot be dropped", func() {
+				pg.EXPECT().DropReplicationSlot(name, "orders_cdc").Return(fmt.Errorf("replication slot is active"))
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+			})
+		})
+	})
+
+	Describe("Checking foreign server logic", func() {
+		var (
+			postgresCR *v1alpha1.Postgres
+			user       *v1alpha1.PostgresUser
+			secret     *corev1.Secret
+		)
+
+		BeforeEach(func() {
+			rp.pgHost = "db.example.com:6432"
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database: name,
+					ForeignServers: []v1alpha1.PostgresForeignServer{{
+						Name:     "reports",
+						Postgres: "reports",
+						UserMappings: []v1alpha1.PostgresUserMapping{{
+							Role: "reader",
+							User: "reports-reader",
+						}},
+					}},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+				},
+			}
+			user = &v1alpha1.PostgresUser{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "reports-reader",
+					Namespace: namespace,
+					UID:       "5c7fbbd2-5d7e-4e0e-9f0e-3bfbd5d0a0c1",
+				},
+				Spec: v1alpha1.PostgresUserSpec{
+					Role:       "reporting",
+					Database:   "reports",
+					SecretName: "reports",
+					Privileges: "READ",
+				},
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "reports-reports-reader", Namespace: namespace},
+				Data: map[string][]byte{
+					"ROLE":     []byte("reporting-abc123"),
+					"PASSWORD": []byte("secret"),
+				},
+			}
+			Expect(controllerutil.SetControllerReference(user, secret, sc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(cl.Delete(ctx, secret))).To(Succeed())
+			Expect(client.IgnoreNotFound(cl.Delete(ctx, user))).To(Succeed())
+		})
+
+		readyTarget := func() {
+			initClient(&v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: namespace},
+				Spec:       v1alpha1.PostgresSpec{Database: "reports-db"},
+				Status:     v1alpha1.PostgresStatus{Succeeded: true},
+			}, false)
+		}
+
+		readyUser := func() {
+			Expect(cl.Create(ctx, user)).To(Succeed())
+			user.Status.Succeeded = true
+			Expect(cl.Status().Update(ctx, user)).To(Succeed())
+			Expect(cl.Create(ctx, secret)).To(Succeed())
+		}
+
+		expectServer := func() {
+			pg.EXPECT().CreateOrUpdateForeignServer(name, postgres.PostgresForeignServer{
+				Name:   "reports",
+				Host:   "db.example.com",
+				Port:   "6432",
+				DBName: "reports-db",
+			}).Return(nil)
+		}
+
+		Context("Server was not created yet", func() {
+			BeforeEach(func() {
+				initClient(postgresCR, false)
+				readyTarget()
+			})
+
+			It("should create the server and the user mapping", func() {
+				readyUser()
+				expectServer()
+				pg.EXPECT().SetUserMapping(name, postgres.PostgresUserMapping{
+					Server:   "reports",
+					Role:     name + "-reader",
+					User:     "reporting-abc123",
+					Password: "secret",
+				}).Return(nil)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeZero())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ForeignServers).To(ConsistOf(v1alpha1.PostgresForeignServerStatus{Name: "reports", Database: "reports-db"}))
+				Expect(foundPostgres.Status.UserMappings).To(HaveLen(1))
+				Expect(foundPostgres.Status.UserMappings[0].Role).To(Equal(name + "-reader"))
+				Expect(foundPostgres.Status.UserMappings[0].CredentialsHash).NotTo(BeEmpty())
+			})
+
+			It("should retry the user mapping while the user is not ready", func() {
+				expectServer()
+				pg.EXPECT().SetUserMapping(gomock.Any(), gomock.Any()).Times(0)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(Equal(pollInterval))
+			})
+
+			It("should map the secret of the user to the database", func() {
+				readyUser()
+				Expect(rp.databasesUsingSecret(ctx, secret)).To(ConsistOf(req))
+			})
+		})
+
+		Context("Server has an address of its own", func() {
+			BeforeEach(func() {
+				postgresCR.Spec.ForeignServers[0].Host = "reports.db.svc"
+				postgresCR.Spec.ForeignServers[0].Port = 5433
+				initClient(postgresCR, false)
+				readyTarget()
+				readyUser()
+			})
+
+			It("should connect the server to that address", func() {
+				pg.EXPECT().CreateOrUpdateForeignServer(name, postgres.PostgresForeignServer{
+					Name:   "reports",
+					Host:   "reports.db.svc",
+					Port:   "5433",
+					DBName: "reports-db",
+				}).Return(nil)
+				pg.EXPECT().SetUserMapping(name, gomock.Any()).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should default the port to the one of the operator", func() {
+				postgresCR.Spec.ForeignServers[0].Port = 0
+				host, port := rp.foreignServerAddress(postgresCR.Spec.ForeignServers[0])
+				Expect(host).To(Equal("reports.db.svc"))
+				Expect(port).To(Equal("6432"))
+			})
+		})
+
+		Context("Mapping was created before", func() {
+			var applied *v1alpha1.Postgres
+
+			BeforeEach(func() {
+				applied = postgresCR.DeepCopy()
+				applied.Status.ForeignServers = []v1alpha1.PostgresForeignServerStatus{{Name: "reports", Database: "reports-db"}}
+				readyTarget()
+				readyUser()
+			})
+
+			It("should not set the mapping again when the credentials did not change", func() {
+				initClient(applied, false)
+				expectServer()
+				pg.EXPECT().SetUserMapping(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				expectServer()
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should set the mapping again when the password was rotated", func() {
+				initClient(applied, false)
+				expectServer()
+				pg.EXPECT().SetUserMapping(gomock.Any(), gomock.Any()).Return(nil)
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				secret.Data["PASSWORD"] = []byte("rotated")
+				Expect(cl.Update(ctx, secret)).To(Succeed())
+				expectServer()
+				pg.EXPECT().SetUserMapping(name, gomock.Any()).DoAndReturn(func(db string, mapping postgres.PostgresUserMapping) error {
+					Expect(mapping.Password).To(Equal("rotated"))
+					return nil
+				})
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should drop mappings and servers removed from the spec", func() {
+				applied.Spec.ForeignServers = nil
+				applied.Status.UserMappings = []v1alpha1.PostgresUserMappingStatus{{
+					Server: "reports", Role: name + "-reader", CredentialsHash: "abc",
+				}}
+				initClient(applied, false)
+				gomock.InOrder(
+					pg.EXPECT().DropUserMapping(name, "reports", name+"-reader").Return(nil),
+					pg.EXPECT().DropForeignServer(name, "reports").Return(nil),
+				)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ForeignServers).To(BeEmpty())
+				Expect(foundPostgres.Status.UserMappings).To(BeEmpty())
+			})
+
+			It("should keep the server while its mapping cannot be dropped", func() {
+				applied.Spec.ForeignServers = nil
+				applied.Status.UserMappings = []v1alpha1.PostgresUserMappingStatus{{
+					Server: "reports", Role: name + "-reader", CredentialsHash: "abc",
+				}}
+				initClient(applied, false)
+				pg.EXPECT().DropUserMapping(name, "reports", name+"-reader").Return(fmt.Errorf("could not drop"))
+				pg.EXPECT().DropForeignServer(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.ForeignServers).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("Checking migration logic", func() {
+		var (
+			postgresCR *v1alpha1.Postgres
+			init       *corev1.ConfigMap
+			seed       *corev1.ConfigMap
+		)
+
+		checksum := func(script string) string {
+			sum := sha256.Sum256([]byte(script))
+			return hex.EncodeToString(sum[:])
+		}
+
+		BeforeEach(func() {
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database:   name,
+					Migrations: []v1alpha1.PostgresMigrationSource{{ConfigMap: "init"}, {ConfigMap: "seed"}},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+				},
+			}
+			init = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "init", Namespace: namespace},
+				Data: map[string]string{
+					"002_tenants.sql": "CREATE TABLE tenants (id int PRIMARY KEY);",
+					"001_schema.sql":  "CREATE SCHEMA app;",
+				},
+			}
+			seed = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: namespace},
+				Data:       map[string]string{"001_lookup.sql": "INSERT INTO tenants VALUES (1);"},
+			}
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(cl.Delete(ctx, init))).To(Succeed())
+			Expect(client.IgnoreNotFound(cl.Delete(ctx, seed))).To(Succeed())
+		})
+
+		expectApplied := func(name, script string) *gomock.Call {
+			return pg.EXPECT().ApplyMigration(postgresCR.Spec.Database, postgresCR.Status.Roles.Owner, postgres.PostgresMigration{
+				Name:     name,
+				Checksum: checksum(script),
+				SQL:      script,
+			}).Return(nil)
+		}
+
+		Context("ConfigMaps exist", func() {
+			BeforeEach(func() {
+				Expect(cl.Create(ctx, init)).To(Succeed())
+				Expect(cl.Create(ctx, seed)).To(Succeed())
+			})
+
+			It("should apply the scripts in order", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().GetMigrations(name).Return(nil, nil)
+				gomock.InOrder(
+					expectApplied("init/001_schema.sql", init.Data["001_schema.sql"]),
+					expectApplied("init/002_tenants.sql", init.Data["002_tenants.sql"]),
+					expectApplied("seed/001_lookup.sql", seed.Data["001_lookup.sql"]),
+				)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Migrations).To(HaveLen(3))
+				Expect(foundPostgres.Status.Migrations[2].Name).To(Equal("seed/001_lookup.sql"))
+				Expect(meta.IsStatusConditionTrue(foundPostgres.Status.Conditions, v1alpha1.ConditionMigrationsApplied)).To(BeTrue())
+			})
+
+			It("should only apply scripts that were not applied yet", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().GetMigrations(name).Return([]postgres.PostgresMigration{
+					{Name: "init/001_schema.sql", Checksum: checksum(init.Data["001_schema.sql"]), AppliedAt: time.Now()},
+					{Name: "init/002_tenants.sql", Checksum: checksum(init.Data["002_tenants.sql"]), AppliedAt: time.Now()},
+				}, nil)
+				expectApplied("seed/001_lookup.sql", seed.Data["001_lookup.sql"])
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should stop at a script changed after it was applied", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().GetMigrations(name).Return([]postgres.PostgresMigration{
+					{Name: "init/001_schema.sql", Checksum: checksum("CREATE SCHEMA legacy;"), AppliedAt: time.Now()},
+				}, nil)
+				pg.EXPECT().ApplyMigration(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeZero())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionMigrationsApplied)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Reason).To(Equal("ChecksumMismatch"))
+				Expect(foundPostgres.Status.Migrations).To(HaveLen(1))
+			})
+
+			It("should retry after a script failed", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().GetMigrations(name).Return(nil, nil)
+				expectApplied("init/001_schema.sql", init.Data["001_schema.sql"])
+				pg.EXPECT().ApplyMigration(name, name+"-group", gomock.Any()).Return(fmt.Errorf("relation \"tenants\" already exists"))
+
+				res, err := rp.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(Equal(pollInterval))
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionMigrationsApplied)
+				Expect(condition.Reason).To(Equal("Failed"))
+				Expect(foundPostgres.Status.Migrations).To(HaveLen(1))
+			})
+
+			It("should map the ConfigMap to the database", func() {
+				initClient(postgresCR, false)
+				Expect(rp.databasesUsingConfigMap(ctx, seed)).To(ConsistOf(req))
+			})
+		})
+
+		Context("ConfigMap is missing", func() {
+			BeforeEach(func() {
+				initClient(postgresCR, false)
+			})
+
+			It("should not apply any script", func() {
+				pg.EXPECT().GetMigrations(gomock.Any()).Times(0)
+				pg.EXPECT().ApplyMigration(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(meta.IsStatusConditionFalse(foundPostgres.Status.Conditions, v1alpha1.ConditionMigrationsApplied)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("Checking schema owner logic", func() {
+		var postgresCR *v1alpha1.Postgres
+
+		billingRoles := v1alpha1.PostgresRoles{
+			Owner:  name + "-billing-group",
+			Reader: name + "-billing-reader",
+			Writer: name + "-billing-writer",
+		}
+
+		BeforeEach(func() {
+			// Verified by the default privileges tests
+			pg.EXPECT().GetDefaultPrivileges(name, gomock.Any()).Return(nil, nil).AnyTimes()
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database:     name,
+					Schemas:      []string{"billing", "shipping"},
+					SchemaOwners: []v1alpha1.PostgresSchemaOwner{{Schema: "billing"}},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+					Schemas: []string{"billing", "shipping"},
+				},
+			}
+		})
+
+		expectSchemaPrivileges := func(schema string, roles v1alpha1.PostgresRoles) {
+			for _, role := range []string{roles.Reader, roles.Writer, roles.Owner} {
+				pg.EXPECT().SetSchemaPrivileges(gomock.Cond(func(p postgres.PostgresSchemaPrivileges) bool {
+					return p.Schema == schema && p.Role == role
+				})).Return(nil)
+			}
+		}
+
+		Context("Schema roles were not created yet", func() {
+			It("should create the roles and hand the schema over", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().GetUser().Return("postgres").AnyTimes()
+				gomock.InOrder(
+					pg.EXPECT().CreateGroupRole(billingRoles.Owner, gomock.Any()).Return(nil),
+					pg.EXPECT().CreateGroupRole(billingRoles.Reader, gomock.Any()).Return(nil),
+					pg.EXPECT().CreateGroupRole(billingRoles.Writer, gomock.Any()).Return(nil),
+					pg.EXPECT().GrantRole(billingRoles.Owner, "postgres").Return(nil),
+					pg.EXPECT().CreateSchema(name, billingRoles.Owner, "billing", gomock.Any()).Return(nil),
+					pg.EXPECT().AlterSchemaOwner(name, "billing", billingRoles.Owner).Return(nil),
+				)
+				expectSchemaPrivileges("billing", billingRoles)
+				expectSchemaPrivileges("shipping", postgresCR.Status.Roles)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.SchemaRoles).To(ConsistOf(v1alpha1.PostgresSchemaRoles{Schema: "billing", Roles: billingRoles}))
+			})
+
+			It("should retry when the schema cannot be handed over", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().GetUser().Return("postgres").AnyTimes()
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				pg.EXPECT().GrantRole(billingRoles.Owner, "postgres").Return(nil)
+				pg.EXPECT().CreateSchema(name, billingRoles.Owner, "billing", gomock.Any()).Return(nil)
+				pg.EXPECT().AlterSchemaOwner(name, "billing", billingRoles.Owner).Return(fmt.Errorf("must be member of role"))
+				expectSchemaPrivileges("billing", postgresCR.Status.Roles)
+				expectSchemaPrivileges("shipping", postgresCR.Status.Roles)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.SchemaRoles).To(BeEmpty())
+			})
+
+			It("should skip a schema that is not listed in schemas", func() {
+				postgresCR.Spec.SchemaOwners = []v1alpha1.PostgresSchemaOwner{{Schema: "audit"}}
+				initClient(postgresCR, false)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Times(0)
+				expectSchemaPrivileges("billing", postgresCR.Status.Roles)
+				expectSchemaPrivileges("shipping", postgresCR.Status.Roles)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+
+		Context("Schema roles exist", func() {
+			BeforeEach(func() {
+				postgresCR.Status.SchemaRoles = []v1alpha1.PostgresSchemaRoles{{Schema: "billing", Roles: billingRoles}}
+			})
+
+			It("should grant privileges on the schema to its own roles", func() {
+				initClient(postgresCR, false)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Times(0)
+				expectSchemaPrivileges("billing", billingRoles)
+				expectSchemaPrivileges("shipping", postgresCR.Status.Roles)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should rename the owner role", func() {
+				postgresCR.Spec.SchemaOwners = []v1alpha1.PostgresSchemaOwner{{Schema: "billing", Role: "billing-team"}}
+				initClient(postgresCR, false)
+				renamed := billingRoles
+				renamed.Owner = "billing-team"
+				pg.EXPECT().RenameGroupRole(billingRoles.Owner, "billing-team").Return(nil)
+				expectSchemaPrivileges("billing", renamed)
+				expectSchemaPrivileges("shipping", postgresCR.Status.Roles)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.SchemaRoles[0].Roles.Owner).To(Equal("billing-team"))
+			})
+
+			It("should drop the roles of a schema removed from schemaOwners", func() {
+				postgresCR.Spec.SchemaOwners = nil
+				initClient(postgresCR, false)
+				gomock.InOrder(
+					pg.EXPECT().DropRole(billingRoles.Owner, postgresCR.Status.Roles.Owner, name).Return(nil),
+					pg.EXPECT().DropRole(billingRoles.Reader, postgresCR.Status.Roles.Owner, name).Return(nil),
+					pg.EXPECT().DropRole(billingRoles.Writer, postgresCR.Status.Roles.Owner, name).Return(nil),
+				)
+				expectSchemaPrivileges("billing", postgresCR.Status.Roles)
+				expectSchemaPrivileges("shipping", postgresCR.Status.Roles)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.SchemaRoles).To(BeEmpty())
+			})
+
+			It("should drop the schema roles before the database roles on deletion", func() {
+				postgresCR.Spec.DropOnDelete = true
+				// Another Postgres using the same database keeps it from being dropped
+				Expect(clearPgs("another-namespace")).To(Succeed())
+				initClient(postgresCR, true)
+				pg.EXPECT().GetUser().Return("postgres").AnyTimes()
+				pg.EXPECT().GetTag("DATABASE", name).Return(nil, nil)
+				gomock.InOrder(
+					pg.EXPECT().DropRole(billingRoles.Owner, "postgres", name).Return(nil),
+					pg.EXPECT().DropRole(billingRoles.Reader, "postgres", name).Return(nil),
+					pg.EXPECT().DropRole(billingRoles.Writer, "postgres", name).Return(nil),
+					pg.EXPECT().DropRole(postgresCR.Status.Roles.Owner, "postgres", name).Return(nil),
+				)
+				pg.EXPECT().DropRole(gomock.Any(), "postgres", name).Return(nil).Times(2)
+				pg.EXPECT().DropDatabase(name).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+	})
+
+	Describe("Checking default privileges logic", func() {
+		var (
+			postgresCR *v1alpha1.Postgres
+			set        []postgres.PostgresSchemaPrivileges
+		)
+
+		BeforeEach(func() {
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database:             name,
+					Schemas:              []string{"app"},
+					DefaultPrivilegesFor: []string{name + "-reader"},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+					Schemas: []string{"app"},
+				},
+			}
+			set = nil
+			initClient(postgresCR, false)
+		})
+
+		// observed returns what pg_default_acl holds after the captured privileges were set
+		observed := func(skip func(postgres.PostgresDefaultPrivilege) bool) []postgres.PostgresDefaultPrivilege {
+			var privileges []postgres.PostgresDefaultPrivilege
+			for _, p := range set {
+				for _, creator := range p.Creators {
+					if creator == p.Role {
+						continue
+					}
+					for kind, privs := range map[string]string{"TABLES": p.Privs, "SEQUENCES": p.SequencePrivs, "FUNCTIONS": p.FunctionPrivs} {
+						for _, privilege := range expandPrivileges(kind, privs) {
+							privilege := postgres.PostgresDefaultPrivilege{Creator: creator, Grantee: p.Role, ObjectType: kind, Privilege: privilege}
+							if !skip(privilege) {
+								privileges = append(privileges, privilege)
+							}
+						}
+					}
+				}
+			}
+			return privileges
+		}
+
+		expectSet := func() {
+			pg.EXPECT().SetSchemaPrivileges(gomock.Any()).DoAndReturn(func(p postgres.PostgresSchemaPrivileges) error {
+				set = append(set, p)
+				return nil
+			}).Times(3)
+		}
+
+		It("should set default privileges for the owner, writer and additional roles", func() {
+			expectSet()
+			pg.EXPECT().GetDefaultPrivileges(name, "app").DoAndReturn(func(db, schema string) ([]postgres.PostgresDefaultPrivilege, error) {
+				return observed(func(postgres.PostgresDefaultPrivilege) bool { return false }), nil
+			})
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			Expect(set).To(HaveLen(3))
+			for _, p := range set {
+				Expect(p.Creators).To(ConsistOf(name+"-group", name+"-writer", name+"-reader"))
+			}
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(foundPostgres.Status.Conditions, v1alpha1.ConditionDefaultPrivilegesApplied)).To(BeTrue())
+		})
+
+		It("should report default privileges missing from pg_default_acl", func() {
+			expectSet()
+			pg.EXPECT().GetDefaultPrivileges(name, "app").DoAndReturn(func(db, schema string) ([]postgres.PostgresDefaultPrivilege, error) {
+				return observed(func(p postgres.PostgresDefaultPrivilege) bool {
+					return p.Creator == name+"-reader" && p.Grantee == name+"-writer" && p.ObjectType == "TABLES" && p.Privilege == "SELECT"
+				}), nil
+			})
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionDefaultPrivilegesApplied)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("Missing"))
+			Expect(condition.Message).To(Equal("SELECT on tables of " + name + "-reader in schema app for " + name + "-writer"))
+		})
+
+		It("should not set default privileges for roles the Postgres does not manage", func() {
+			Expect(cl.Get(ctx, req.NamespacedName, postgresCR)).To(Succeed())
+			postgresCR.Spec.DefaultPrivilegesFor = []string{"flyway"}
+			Expect(cl.Update(ctx, postgresCR)).To(Succeed())
+			expectSet()
+			pg.EXPECT().GetDefaultPrivileges(name, "app").DoAndReturn(func(db, schema string) ([]postgres.PostgresDefaultPrivilege, error) {
+				return observed(func(postgres.PostgresDefaultPrivilege) bool { return false }), nil
+			})
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			for _, p := range set {
+				Expect(p.Creators).To(ConsistOf(name+"-group", name+"-writer"))
+			}
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionDefaultPrivilegesApplied)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("Missing"))
+			Expect(condition.Message).To(Equal("flyway is not a role of this Postgres"))
+		})
+
+		It("should not verify a schema whose privileges could not be set", func() {
+			pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(fmt.Errorf("permission denied to change default privileges"))
+			pg.EXPECT().GetDefaultPrivileges(gomock.Any(), gomock.Any()).Times(0)
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			condition := meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionDefaultPrivilegesApplied)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("Failed"))
+		})
+	})
+
+	Describe("Checking drift detection logic", func() {
+		var (
+			postgresCR *v1alpha1.Postgres
+			state      postgres.PostgresDatabaseState
+		)
+
+		BeforeEach(func() {
+			rp.resyncPeriod = time.Hour
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database: name,
+					Schemas:  []string{"app"},
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  name + "-group",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+					Schemas: []string{"app"},
+				},
+			}
+			state = postgres.PostgresDatabaseState{
+				Exists:       true,
+				Owner:        name + "-group",
+				Roles:        []string{name + "-group", name + "-reader", name + "-writer"},
+				SchemaOwners: map[string]string{"app": name + "-group"},
+				SchemaGrants: []postgres.PostgresSchemaGrant{
+					{Schema: "app", Role: name + "-reader", Privilege: "USAGE"},
+					{Schema: "app", Role: name + "-writer", Privilege: "USAGE"},
+					{Schema: "app", Role: name + "-writer", Privilege: "CREATE"},
+				},
+			}
+			pg.EXPECT().SetSchemaPrivileges(gomock.Any()).Return(nil).AnyTimes()
+			pg.EXPECT().GetDefaultPrivileges(name, gomock.Any()).Return(nil, nil).AnyTimes()
+			initClient(postgresCR, false)
+		})
+
+		expectState := func() {
+			pg.EXPECT().GetDatabaseState(name, []string{name + "-group", name + "-reader", name + "-writer"}, []string{"app"}).Return(state, nil)
+		}
+
+		inSync := func() *metav1.Condition {
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			return meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionInSync)
+		}
+
+		It("should report a database without drift and requeue after the resync period", func() {
+			expectState()
+
+			res, err := rp.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(time.Hour))
+
+			Expect(inSync().Status).To(Equal(metav1.ConditionTrue))
+			Expect(testutil.ToFloat64(driftDetected.WithLabelValues(namespace, name))).To(BeZero())
+		})
+
+		It("should report drift without remediation", func() {
+			state.Roles = state.Roles[:2]
+			state.SchemaOwners["app"] = "dba"
+			expectState()
+			pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Times(0)
+			pg.EXPECT().AlterSchemaOwner(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			condition := inSync()
+			Expect(condition.Reason).To(Equal("Drifted"))
+			Expect(condition.Message).To(Equal("role " + name + "-writer does not exist; schema app is owned by dba instead of " + name + "-group"))
+			Expect(testutil.ToFloat64(driftDetected.WithLabelValues(namespace, name))).To(Equal(1.0))
+		})
+
+		Context("Remediation is enabled", func() {
+			BeforeEach(func() {
+				rp.driftRemediation = true
+			})
+
+			It("should recreate dropped roles and restore the schema owner", func() {
+				state.Roles = state.Roles[:2]
+				state.SchemaOwners["app"] = "dba"
+				expectState()
+				remediations := testutil.ToFloat64(driftRemediations.WithLabelValues(namespace, name))
+				pg.EXPECT().AlterSchemaOwner(name, "app", name+"-group").Return(nil)
+				pg.EXPECT().CreateGroupRole(name+"-group", gomock.Any()).Return(nil)
+				pg.EXPECT().CreateDB(name, name+"-group", gomock.Any()).Return(nil)
+				pg.EXPECT().CreateGroupRole(name+"-reader", gomock.Any()).Return(nil)
+				pg.EXPECT().CreateGroupRole(name+"-writer", gomock.Any()).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				Expect(inSync().Reason).To(Equal("Remediating"))
+				Expect(testutil.ToFloat64(driftRemediations.WithLabelValues(namespace, name))).To(Equal(remediations + 1))
+			})
+
+			It("should recreate a dropped database and its schemas", func() {
+				pg.EXPECT().GetDatabaseState(name, gomock.Any(), gomock.Any()).Return(postgres.PostgresDatabaseState{}, nil)
+				pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				pg.EXPECT().CreateDB(name, name+"-group", gomock.Any()).Return(nil)
+				pg.EXPECT().CreateSchema(name, name+"-group", "app", gomock.Any()).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				foundPostgres := &v1alpha1.Postgres{}
+				Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+				Expect(foundPostgres.Status.Succeeded).To(BeTrue())
+				Expect(foundPostgres.Status.Schemas).To(ConsistOf("app"))
+			})
+		})
+	})
+
+	Describe("Checking database rename logic", func() {
+		const newDatabase = "renamed-db"
+		var postgresCR *v1alpha1.Postgres
+
+		BeforeEach(func() {
+			postgresCR = &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					UID:       types.UID("rename-uid"),
+				},
+				Spec: v1alpha1.PostgresSpec{
+					Database:   newDatabase,
+					MasterRole: "dba",
+				},
+				Status: v1alpha1.PostgresStatus{
+					// So it doesn't run creation logic
+					Succeeded: true,
+					Database:  name,
+					Roles: v1alpha1.PostgresRoles{
+						Owner:  "dba",
+						Reader: name + "-reader",
+						Writer: name + "-writer",
+					},
+				},
+			}
+		})
+
+		renamed := func() *metav1.Condition {
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			return meta.FindStatusCondition(foundPostgres.Status.Conditions, v1alpha1.ConditionDatabaseRenamed)
+		}
+
+		It("should reject a change of the database without rename policy", func() {
+			initClient(postgresCR, false)
+			pg.EXPECT().RenameDatabase(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			Expect(renamed().Reason).To(Equal("Rejected"))
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			Expect(foundPostgres.Status.Database).To(Equal(name))
+			Expect(foundPostgres.Status.Succeeded).To(BeTrue())
+		})
+
+		It("should rename the database and the roles named after it", func() {
+			postgresCR.Spec.DatabaseRename = &v1alpha1.PostgresDatabaseRename{Policy: "Rename", TerminateConnections: true}
+			initClient(postgresCR, false)
+			pg.EXPECT().RenameDatabase(name, newDatabase, true, gomock.Any()).DoAndReturn(
+				func(_, _ string, _ bool, tag postgres.ObjectTag) error {
+					Expect(tag.Database).To(Equal(newDatabase))
+					return nil
+				})
+			pg.EXPECT().RenameGroupRole(name+"-reader", newDatabase+"-reader").Return(nil)
+			pg.EXPECT().RenameGroupRole(name+"-writer", newDatabase+"-writer").Return(nil)
+			pg.EXPECT().CreateGroupRole(newDatabase+"-reader", gomock.Any()).Return(nil)
+			pg.EXPECT().CreateGroupRole(newDatabase+"-writer", gomock.Any()).Return(nil)
+			// The owner role was not named after the database
+			pg.EXPECT().RenameGroupRole("dba", gomock.Any()).Times(0)
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			Expect(renamed().Status).To(Equal(metav1.ConditionTrue))
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			Expect(foundPostgres.Status.Database).To(Equal(newDatabase))
+			Expect(foundPostgres.Status.Roles).To(Equal(v1alpha1.PostgresRoles{
+				Owner:  "dba",
+				Reader: newDatabase + "-reader",
+				Writer: newDatabase + "-writer",
+			}))
+		})
+
+		It("should recognise the database of resources created before it was recorded", func() {
+			postgresCR.Status.Database = ""
+			postgresCR.Spec.DatabaseRename = &v1alpha1.PostgresDatabaseRename{Policy: "Rename"}
+			initClient(postgresCR, false)
+			pg.EXPECT().RenameDatabase(name, newDatabase, false, gomock.Any()).Return(nil)
+			pg.EXPECT().RenameGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			pg.EXPECT().CreateGroupRole(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+		})
+
+		It("should keep the old names when the database is in use", func() {
+			postgresCR.Spec.DatabaseRename = &v1alpha1.PostgresDatabaseRename{Policy: "Rename"}
+			initClient(postgresCR, false)
+			pg.EXPECT().RenameDatabase(name, newDatabase, false, gomock.Any()).Return(postgres.ErrDatabaseInUse)
+			pg.EXPECT().RenameGroupRole(gomock.Any(), gomock.Any()).Times(0)
+
+			Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+			Expect(renamed().Reason).To(Equal("Failed"))
+			foundPostgres := &v1alpha1.Postgres{}
+			Expect(cl.Get(ctx, req.NamespacedName, foundPostgres)).To(Succeed())
+			Expect(foundPostgres.Status.Database).To(Equal(name))
+			Expect(foundPostgres.Status.Roles.Reader).To(Equal(name + "-reader"))
+		})
+	})
 })
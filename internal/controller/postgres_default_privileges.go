@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// allPrivileges are the privileges ALL stands for in pg_default_acl, by object type
+var allPrivileges = map[string][]string{
+	"TABLES":    {"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	"SEQUENCES": {"USAGE", "SELECT", "UPDATE"},
+	"FUNCTIONS": {"EXECUTE"},
+}
+
+// verifyDefaultPrivileges checks pg_default_acl for the default privileges set for the creators of
+// objects in the schemas of cr, and reports the outcome in the DefaultPrivilegesApplied condition.
+// applied holds the privileges that were set successfully.
+func (r *PostgresReconciler) verifyDefaultPrivileges(reqLogger logr.Logger, cr *dbv1alpha1.Postgres, applied []postgres.PostgresSchemaPrivileges) {
+	if len(cr.Spec.Schemas) == 0 {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, dbv1alpha1.ConditionDefaultPrivilegesApplied)
+		return
+	}
+	setCondition := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionDefaultPrivilegesApplied,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+
+	var failed, missing []string
+	_, unmanaged := additionalCreators(cr)
+	for _, role := range unmanaged {
+		missing = append(missing, fmt.Sprintf("%s is not a role of this Postgres", role))
+	}
+	for _, schema := range cr.Spec.Schemas {
+		if !slices.ContainsFunc(applied, func(p postgres.PostgresSchemaPrivileges) bool { return p.Schema == schema }) {
+			failed = append(failed, schema)
+			continue
+		}
+		observed, err := r.pg.GetDefaultPrivileges(cr.Spec.Database, schema)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not read default privileges of schema %s", schema))
+			failed = append(failed, schema)
+			continue
+		}
+		for _, privileges := range applied {
+			if privileges.Schema == schema {
+				missing = append(missing, missingDefaultPrivileges(privileges, observed)...)
+			}
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		setCondition(metav1.ConditionFalse, "Failed", fmt.Sprintf("Privileges of schemas %s could not be set or read", strings.Join(failed, ", ")))
+	case len(missing) > 0:
+		reqLogger.Info(fmt.Sprintf("Default privileges are missing: %s", strings.Join(missing, "; ")))
+		setCondition(metav1.ConditionFalse, "Missing", strings.Join(missing, "; "))
+	default:
+		setCondition(metav1.ConditionTrue, "Verified", "Default privileges cover the objects of all creators")
+	}
+}
+
+// additionalCreators splits defaultPrivilegesFor of cr into the roles cr manages and others. The operator's
+// user has to join a role to set its default privileges, so it is only done for roles of cr.
+func additionalCreators(cr *dbv1alpha1.Postgres) (managed, unmanaged []string) {
+	roles := managedRoles(cr)
+	for _, role := range cr.Spec.DefaultPrivilegesFor {
+		if slices.Contains(roles, role) {
+			managed = append(managed, role)
+		} else {
+			unmanaged = append(unmanaged, role)
+		}
+	}
+	return managed, unmanaged
+}
+
+// missingDefaultPrivileges lists what observed lacks of the default privileges set by privileges
+func missingDefaultPrivileges(privileges postgres.PostgresSchemaPrivileges, observed []postgres.PostgresDefaultPrivilege) []string {
+	var missing []string
+	for _, creator := range privileges.Creators {
+		if creator == privileges.Role {
+			continue
+		}
+		for _, objects := range []struct{ kind, privs string }{
+			{"TABLES", privileges.Privs},
+			{"SEQUENCES", privileges.SequencePrivs},
+			{"FUNCTIONS", privileges.FunctionPrivs},
+		} {
+			for _, privilege := range expandPrivileges(objects.kind, objects.privs) {
+				want := postgres.PostgresDefaultPrivilege{
+					Creator:    creator,
+					Grantee:    privileges.Role,
+					ObjectType: objects.kind,
+					Privilege:  privilege,
+				}
+				if !slices.Contains(observed, want) {
+					missing = append(missing, fmt.Sprintf("%s on %s of %s in schema %s for %s",
+						privilege, strings.ToLower(objects.kind), creator, privileges.Schema, privileges.Role))
+				}
+			}
+		}
+	}
+	return missing
+}
+
+func expandPrivileges(objectType, privs string) []string {
+	var expanded []string
+	for _, privilege := range strings.Split(privs, ",") {
+		privilege = strings.ToUpper(strings.TrimSpace(privilege))
+		switch privilege {
+		case "":
+		case "ALL":
+			expanded = append(expanded, allPrivileges[objectType]...)
+		default:
+			expanded = append(expanded, privilege)
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+var (
+	driftDetected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postgres_operator_drift_detected",
+		Help: "Whether the database of a Postgres resource drifted from what the operator created (1) or not (0)",
+	}, []string{"namespace", "name"})
+	driftRemediations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "postgres_operator_drift_remediations_total",
+		Help: "Number of times drift of the database of a Postgres resource was undone",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftDetected, driftRemediations)
+}
+
+// drift is a difference between the server and what the operator created, remediate undoes it
+type drift struct {
+	message   string
+	remediate func() error
+}
+
+// reconcileDrift compares the database, roles and schemas of cr with pg_database, pg_roles and pg_namespace
+// and reports the differences in the InSync condition. With remediation enabled drifted objects are
+// forgotten from the status, so the rest of the reconcile creates them again.
+func (r *PostgresReconciler) reconcileDrift(reqLogger logr.Logger, cr *dbv1alpha1.Postgres) {
+	setCondition := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionInSync,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+
+	state, err := r.pg.GetDatabaseState(cr.Spec.Database, managedRoles(cr), cr.Status.Schemas)
+	if err != nil {
+		reqLogger.Error(err, "Could not read the state of the database")
+		setCondition(metav1.ConditionUnknown, "Failed", err.Error())
+		return
+	}
+	drifts := detectDrift(r.pg, cr, state)
+	if len(drifts) == 0 {
+		driftDetected.WithLabelValues(cr.Namespace, cr.Name).Set(0)
+		setCondition(metav1.ConditionTrue, "NoDrift", "The database matches what the operator created")
+		return
+	}
+
+	driftDetected.WithLabelValues(cr.Namespace, cr.Name).Set(1)
+	messages := make([]string, 0, len(drifts))
+	for _, d := range drifts {
+		messages = append(messages, d.message)
+	}
+	message := strings.Join(messages, "; ")
+	reqLogger.Info(fmt.Sprintf("Database drifted: %s", message))
+	if !r.driftRemediation {
+		setCondition(metav1.ConditionFalse, "Drifted", message)
+		return
+	}
+
+	for _, d := range drifts {
+		if d.remediate == nil {
+			continue
+		}
+		if err := d.remediate(); err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not undo drift: %s", d.message))
+		}
+	}
+	driftRemediations.WithLabelValues(cr.Namespace, cr.Name).Inc()
+	setCondition(metav1.ConditionFalse, "Remediating", message)
+}
+
+// detectDrift lists the differences between state and the status of cr. Remediations that only reset
+// the status leave the work to the reconcile, privileges on schemas are set again by every reconcile.
+func detectDrift(pg postgres.PG, cr *dbv1alpha1.Postgres, state postgres.PostgresDatabaseState) []drift {
+	database := cr.Spec.Database
+	// Creation logic creates the database and the roles again and hands the database over to the owner
+	recreate := func() error {
+		cr.Status.Succeeded = false
+		return nil
+	}
+
+	if !state.Exists {
+		return []drift{{
+			message: fmt.Sprintf("database %s does not exist", database),
+			remediate: func() error {
+				cr.Status.Succeeded = false
+				cr.Status.Schemas = nil
+				cr.Status.Extensions = nil
+				return nil
+			},
+		}}
+	}
+
+	var drifts []drift
+	for _, role := range []string{cr.Status.Roles.Owner, cr.Status.Roles.Reader, cr.Status.Roles.Writer} {
+		if role != "" && !slices.Contains(state.Roles, role) {
+			drifts = append(drifts, drift{message: fmt.Sprintf("role %s does not exist", role), remediate: recreate})
+		}
+	}
+	if owner := cr.Status.Roles.Owner; owner != "" && state.Owner != owner && slices.Contains(state.Roles, owner) {
+		drifts = append(drifts, drift{
+			message:   fmt.Sprintf("database %s is owned by %s instead of %s", database, state.Owner, owner),
+			remediate: func() error { return pg.AlterDatabaseOwner(database, owner) },
+		})
+	}
+
+	for _, schemaRoles := range cr.Status.SchemaRoles {
+		for _, role := range []string{schemaRoles.Roles.Owner, schemaRoles.Roles.Reader, schemaRoles.Roles.Writer} {
+			if slices.Contains(state.Roles, role) {
+				continue
+			}
+			schema := schemaRoles.Schema
+			drifts = append(drifts, drift{
+				message: fmt.Sprintf("role %s of schema %s does not exist", role, schema),
+				// Schema owners are set up again for schemas without known roles
+				remediate: func() error {
+					cr.Status.SchemaRoles = slices.DeleteFunc(cr.Status.SchemaRoles, func(s dbv1alpha1.PostgresSchemaRoles) bool {
+						return s.Schema == schema
+					})
+					return nil
+				},
+			})
+		}
+	}
+
+	for _, schema := range cr.Status.Schemas {
+		owner, exists := state.SchemaOwners[schema]
+		if !exists {
+			drifts = append(drifts, drift{
+				message: fmt.Sprintf("schema %s does not exist", schema),
+				remediate: func() error {
+					cr.Status.Schemas = slices.DeleteFunc(cr.Status.Schemas, func(s string) bool { return s == schema })
+					return nil
+				},
+			})
+			continue
+		}
+		roles := rolesForSchema(cr, schema)
+		if owner != roles.Owner && slices.Contains(state.Roles, roles.Owner) {
+			drifts = append(drifts, drift{
+				message:   fmt.Sprintf("schema %s is owned by %s instead of %s", schema, owner, roles.Owner),
+				remediate: func() error { return pg.AlterSchemaOwner(database, schema, roles.Owner) },
+			})
+		}
+		for _, grant := range []postgres.PostgresSchemaGrant{
+			{Schema: schema, Role: roles.Reader, Privilege: "USAGE"},
+			{Schema: schema, Role: roles.Writer, Privilege: "USAGE"},
+			{Schema: schema, Role: roles.Writer, Privilege: "CREATE"},
+		} {
+			if slices.Contains(state.Roles, grant.Role) && !slices.Contains(state.SchemaGrants, grant) {
+				drifts = append(drifts, drift{message: fmt.Sprintf("role %s lacks %s on schema %s", grant.Role, grant.Privilege, schema)})
+			}
+		}
+	}
+	return drifts
+}
+
+// managedRoles lists the group roles the operator created for cr
+func managedRoles(cr *dbv1alpha1.Postgres) []string {
+	var roles []string
+	for _, role := range []string{cr.Status.Roles.Owner, cr.Status.Roles.Reader, cr.Status.Roles.Writer} {
+		if role != "" {
+			roles = append(roles, role)
+		}
+	}
+	for _, schemaRoles := range cr.Status.SchemaRoles {
+		roles = append(roles, schemaRoles.Roles.Owner, schemaRoles.Roles.Reader, schemaRoles.Roles.Writer)
+	}
+	return roles
+}
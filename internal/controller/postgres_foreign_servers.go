@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// reconcileForeignServers creates the foreign servers of cr and the user mappings of its group roles.
+// Mappings are set again when the credentials in the secret of their PostgresUser changed. Servers and
+// mappings removed from the spec are dropped. Returns false if a referenced resource is not ready yet.
+func (r *PostgresReconciler) reconcileForeignServers(ctx context.Context, reqLogger logr.Logger, cr *dbv1alpha1.Postgres) bool {
+	if len(cr.Spec.ForeignServers) == 0 && len(cr.Status.ForeignServers) == 0 {
+		return true
+	}
+	ready := true
+	var (
+		servers  []dbv1alpha1.PostgresForeignServerStatus
+		mappings []dbv1alpha1.PostgresUserMappingStatus
+	)
+	for _, server := range cr.Spec.ForeignServers {
+		target, err := getReadyPostgres(ctx, r.Client, types.NamespacedName{Namespace: cr.Namespace, Name: server.Postgres}, r.instanceFilter)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not create foreign server %s", server.Name))
+			ready = false
+			continue
+		}
+		host, port := r.foreignServerAddress(server)
+		err = r.pg.CreateOrUpdateForeignServer(cr.Spec.Database, postgres.PostgresForeignServer{
+			Name:   server.Name,
+			Host:   host,
+			Port:   port,
+			DBName: target.Spec.Database,
+		})
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not create foreign server %s", server.Name))
+			continue
+		}
+		servers = append(servers, dbv1alpha1.PostgresForeignServerStatus{Name: server.Name, Database: target.Spec.Database})
+
+		for _, mapping := range server.UserMappings {
+			role := groupRole(cr.Status.Roles, mapping.Role)
+			user, password, err := r.getUserCredentials(ctx, cr.Namespace, mapping.User, server.Postgres)
+			if err != nil {
+				reqLogger.Error(err, fmt.Sprintf("Could not create user mapping for %s on %s", role, server.Name))
+				ready = false
+				continue
+			}
+			sum := sha256.Sum256([]byte(user + "\x00" + password))
+			hash := hex.EncodeToString(sum[:])
+			if applied := findUserMappingStatus(cr.Status.UserMappings, server.Name, role); applied != nil && applied.CredentialsHash == hash {
+				mappings = append(mappings, *applied)
+				continue
+			}
+			err = r.pg.SetUserMapping(cr.Spec.Database, postgres.PostgresUserMapping{
+				Server:   server.Name,
+				Role:     role,
+				User:     user,
+				Password: password,
+			})
+			if err != nil {
+				reqLogger.Error(err, fmt.Sprintf("Could not create user mapping for %s on %s", role, server.Name))
+				continue
+			}
+			mappings = append(mappings, dbv1alpha1.PostgresUserMappingStatus{Server: server.Name, Role: role, CredentialsHash: hash})
+		}
+	}
+
+	for _, applied := range cr.Status.UserMappings {
+		if findUserMappingStatus(mappings, applied.Server, applied.Role) != nil {
+			continue
+		}
+		// Still wanted but failed to reconcile, keep what is known about it
+		if slices.ContainsFunc(cr.Spec.ForeignServers, func(server dbv1alpha1.PostgresForeignServer) bool {
+			return server.Name == applied.Server && slices.ContainsFunc(server.UserMappings, func(mapping dbv1alpha1.PostgresUserMapping) bool {
+				return groupRole(cr.Status.Roles, mapping.Role) == applied.Role
+			})
+		}) {
+			mappings = append(mappings, applied)
+			continue
+		}
+		err := r.pg.DropUserMapping(cr.Spec.Database, applied.Server, applied.Role)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not drop user mapping for %s on %s", applied.Role, applied.Server))
+			mappings = append(mappings, applied)
+		}
+	}
+	for _, applied := range cr.Status.ForeignServers {
+		if slices.ContainsFunc(servers, func(server dbv1alpha1.PostgresForeignServerStatus) bool { return server.Name == applied.Name }) {
+			continue
+		}
+		// A server is only dropped once all of its mappings are
+		if slices.ContainsFunc(cr.Spec.ForeignServers, func(server dbv1alpha1.PostgresForeignServer) bool { return server.Name == applied.Name }) ||
+			slices.ContainsFunc(mappings, func(mapping dbv1alpha1.PostgresUserMappingStatus) bool { return mapping.Server == applied.Name }) {
+			servers = append(servers, applied)
+			continue
+		}
+		err := r.pg.DropForeignServer(cr.Spec.Database, applied.Name)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not drop foreign server %s", applied.Name))
+			servers = append(servers, applied)
+		}
+	}
+	cr.Status.ForeignServers = servers
+	cr.Status.UserMappings = mappings
+	return ready
+}
+
+// foreignServerAddress returns the host and port server connects to. Both default to the address of the
+// PostgreSQL server the operator connects to.
+func (r *PostgresReconciler) foreignServerAddress(server dbv1alpha1.PostgresForeignServer) (string, string) {
+	host, port, err := net.SplitHostPort(r.pgHost)
+	if err != nil {
+		host = r.pgHost
+		port = "5432"
+	}
+	if server.Host != "" {
+		host = server.Host
+	}
+	if server.Port != 0 {
+		port = strconv.Itoa(int(server.Port))
+	}
+	return host, port
+}
+
+// getUserCredentials reads the role and password from the secret of a PostgresUser of database
+func (r *PostgresReconciler) getUserCredentials(ctx context.Context, namespace, name, database string) (string, string, error) {
+	user := dbv1alpha1.PostgresUser{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &user)
+	if err != nil {
+		return "", "", err
+	}
+	if user.Spec.Database != database {
+		return "", "", fmt.Errorf("user \"%s\" does not belong to database \"%s\"", name, database)
+	}
+	if !user.Status.Succeeded {
+		return "", "", fmt.Errorf("user \"%s\" is not ready", name)
+	}
+
+	secrets := corev1.SecretList{}
+	err = r.List(ctx, &secrets, client.InNamespace(namespace))
+	if err != nil {
+		return "", "", err
+	}
+	for _, secret := range secrets.Items {
+		if !metav1.IsControlledBy(&secret, &user) {
+			continue
+		}
+		role, password := secret.Data["ROLE"], secret.Data["PASSWORD"]
+		if len(role) == 0 || len(password) == 0 {
+			return "", "", fmt.Errorf("secret \"%s\" has no credentials", secret.Name)
+		}
+		return string(role), string(password), nil
+	}
+	return "", "", fmt.Errorf("secret of user \"%s\" not found", name)
+}
+
+// databasesUsingSecret maps the secret of a PostgresUser to the Postgres resources with user mappings
+// using its credentials
+func (r *PostgresReconciler) databasesUsingSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "PostgresUser" {
+		return nil
+	}
+	databases := dbv1alpha1.PostgresList{}
+	if err := r.List(ctx, &databases, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "could not list Postgres")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, database := range databases.Items {
+		if slices.ContainsFunc(database.Spec.ForeignServers, func(server dbv1alpha1.PostgresForeignServer) bool {
+			return slices.ContainsFunc(server.UserMappings, func(mapping dbv1alpha1.PostgresUserMapping) bool {
+				return mapping.User == owner.Name
+			})
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&database)})
+		}
+	}
+	return requests
+}
+
+func findUserMappingStatus(mappings []dbv1alpha1.PostgresUserMappingStatus, server, role string) *dbv1alpha1.PostgresUserMappingStatus {
+	for i := range mappings {
+		if mappings[i].Server == server && mappings[i].Role == role {
+			return &mappings[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// applyMigrations runs the scripts of the migration ConfigMaps of cr that were not applied yet, in order,
+// each in its own transaction as the owner role. Scripts changed after they were applied are not run again,
+// they stop the migrations until they are restored. Returns false if applying should be retried.
+func (r *PostgresReconciler) applyMigrations(ctx context.Context, reqLogger logr.Logger, cr *dbv1alpha1.Postgres) bool {
+	if len(cr.Spec.Migrations) == 0 {
+		return true
+	}
+	setCondition := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionMigrationsApplied,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+
+	scripts, err := r.getMigrations(ctx, cr)
+	if err != nil {
+		reqLogger.Error(err, "Could not read migrations")
+		setCondition(metav1.ConditionFalse, "ConfigMapError", err.Error())
+		return false
+	}
+	applied, err := r.pg.GetMigrations(cr.Spec.Database)
+	if err != nil {
+		reqLogger.Error(err, "Could not read applied migrations")
+		return false
+	}
+	defer func() {
+		cr.Status.Migrations = migrationStatuses(applied)
+	}()
+
+	for _, script := range scripts {
+		idx := slices.IndexFunc(applied, func(m postgres.PostgresMigration) bool { return m.Name == script.Name })
+		if idx >= 0 {
+			if applied[idx].Checksum != script.Checksum {
+				setCondition(metav1.ConditionFalse, "ChecksumMismatch", fmt.Sprintf("%s was changed after it was applied", script.Name))
+				return true
+			}
+			continue
+		}
+		reqLogger.Info(fmt.Sprintf("Applying migration %s", script.Name))
+		err = r.pg.ApplyMigration(cr.Spec.Database, cr.Status.Roles.Owner, script)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not apply migration %s", script.Name))
+			setCondition(metav1.ConditionFalse, "Failed", err.Error())
+			return false
+		}
+		script.AppliedAt = metav1.Now().Time
+		applied = append(applied, script)
+	}
+	setCondition(metav1.ConditionTrue, "Applied", fmt.Sprintf("%d scripts applied", len(scripts)))
+	return true
+}
+
+// getMigrations reads the scripts of the migration ConfigMaps of cr in the order they are applied
+func (r *PostgresReconciler) getMigrations(ctx context.Context, cr *dbv1alpha1.Postgres) ([]postgres.PostgresMigration, error) {
+	var migrations []postgres.PostgresMigration
+	for _, source := range cr.Spec.Migrations {
+		configMap := &corev1.ConfigMap{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: source.ConfigMap}, configMap)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			script := configMap.Data[key]
+			sum := sha256.Sum256([]byte(script))
+			migrations = append(migrations, postgres.PostgresMigration{
+				Name:     fmt.Sprintf("%s/%s", source.ConfigMap, key),
+				Checksum: hex.EncodeToString(sum[:]),
+				SQL:      script,
+			})
+		}
+	}
+	return migrations, nil
+}
+
+func migrationStatuses(migrations []postgres.PostgresMigration) []dbv1alpha1.PostgresMigrationStatus {
+	statuses := make([]dbv1alpha1.PostgresMigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = dbv1alpha1.PostgresMigrationStatus{
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: metav1.NewTime(migration.AppliedAt),
+		}
+	}
+	return statuses
+}
+
+// databasesUsingConfigMap maps a ConfigMap to the Postgres resources taking migrations from it
+func (r *PostgresReconciler) databasesUsingConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	databases := dbv1alpha1.PostgresList{}
+	if err := r.List(ctx, &databases, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "could not list Postgres")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, database := range databases.Items {
+		if slices.ContainsFunc(database.Spec.Migrations, func(source dbv1alpha1.PostgresMigrationSource) bool {
+			return source.ConfigMap == obj.GetName()
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&database)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// getReadyPostgres fetches a Postgres that is managed by this operator instance and whose database was created
+func getReadyPostgres(ctx context.Context, c client.Client, key types.NamespacedName, instanceFilter string) (*dbv1alpha1.Postgres, error) {
+	database := dbv1alpha1.Postgres{}
+	err := c.Get(ctx, key, &database)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.MatchesInstanceAnnotation(database.Annotations, instanceFilter) {
+		return nil, fmt.Errorf("database \"%s\" is not managed by this operator", database.Name)
+	}
+	if !database.Status.Succeeded {
+		return nil, fmt.Errorf("database \"%s\" is not ready", database.Name)
+	}
+	return &database, nil
+}
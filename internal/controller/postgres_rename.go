@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+)
+
+// statusDatabase returns the name of the database the status of cr refers to. Resources created before
+// the name was recorded are recognised by their reader role, which is named after the database.
+func statusDatabase(cr *dbv1alpha1.Postgres) string {
+	if cr.Status.Database != "" {
+		return cr.Status.Database
+	}
+	if strings.HasSuffix(cr.Status.Roles.Reader, "-reader") {
+		return strings.TrimSuffix(cr.Status.Roles.Reader, "-reader")
+	}
+	return cr.Spec.Database
+}
+
+// reconcileDatabaseName follows a change of the database name of cr according to its rename policy.
+// The database and the roles named after it are renamed, objects and members stay with them. It returns
+// false if the rest of the reconcile has to wait, e.g. because the change was rejected.
+func (r *PostgresReconciler) reconcileDatabaseName(reqLogger logr.Logger, cr *dbv1alpha1.Postgres) (bool, error) {
+	current := statusDatabase(cr)
+	if current == cr.Spec.Database {
+		return true, nil
+	}
+	if cr.Spec.DatabaseRename == nil || cr.Spec.DatabaseRename.Policy != "Rename" {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionDatabaseRenamed,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Rejected",
+			Message: fmt.Sprintf("database %s cannot be renamed to %s without databaseRename.policy Rename", current, cr.Spec.Database),
+		})
+		return false, nil
+	}
+
+	failed := func(err error) (bool, error) {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dbv1alpha1.ConditionDatabaseRenamed,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: err.Error(),
+		})
+		return false, err
+	}
+	tag := objectTag(r.instanceFilter, r.cluster, "Postgres", cr, cr.Spec.Database)
+	err := r.pg.RenameDatabase(current, cr.Spec.Database, cr.Spec.DatabaseRename.TerminateConnections, tag)
+	if err != nil {
+		return failed(err)
+	}
+	for _, role := range derivedRoles(cr) {
+		if !strings.HasPrefix(*role, current+"-") {
+			continue
+		}
+		renamed := cr.Spec.Database + strings.TrimPrefix(*role, current)
+		err = r.pg.RenameGroupRole(*role, renamed)
+		if err != nil {
+			return failed(err)
+		}
+		// Existing roles only get their tag updated
+		err = r.pg.CreateGroupRole(renamed, tag)
+		if err != nil {
+			return failed(err)
+		}
+		*role = renamed
+	}
+	reqLogger.Info(fmt.Sprintf("Renamed database %s to %s", current, cr.Spec.Database))
+	cr.Status.Database = cr.Spec.Database
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:    dbv1alpha1.ConditionDatabaseRenamed,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Renamed",
+		Message: fmt.Sprintf("renamed from %s", current),
+	})
+	return true, nil
+}
+
+// derivedRoles returns the roles in the status of cr whose names the operator derived from the database name
+func derivedRoles(cr *dbv1alpha1.Postgres) []*string {
+	roles := []*string{&cr.Status.Roles.Reader, &cr.Status.Roles.Writer}
+	if cr.Spec.MasterRole == "" {
+		roles = append(roles, &cr.Status.Roles.Owner)
+	}
+	for i := range cr.Status.SchemaRoles {
+		schemaRoles := &cr.Status.SchemaRoles[i]
+		roles = append(roles, &schemaRoles.Roles.Reader, &schemaRoles.Roles.Writer)
+		for _, schemaOwner := range cr.Spec.SchemaOwners {
+			if schemaOwner.Schema == schemaRoles.Schema && schemaOwner.Role == "" {
+				roles = append(roles, &schemaRoles.Roles.Owner)
+			}
+		}
+	}
+	return roles
+}
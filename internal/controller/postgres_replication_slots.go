@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// pollInterval is how often state kept outside of the spec, like the state of replication slots
+// or resources that are not ready yet, is checked again
+const pollInterval = time.Minute
+
+// reconcileReplicationSlots creates the replication slots of cr and drops the ones removed from the spec.
+// The state of every slot is recorded, slots inactive for longer than their threshold are recreated so
+// the server can free the WAL they retain.
+func (r *PostgresReconciler) reconcileReplicationSlots(reqLogger logr.Logger, cr *dbv1alpha1.Postgres, now metav1.Time) {
+	if len(cr.Spec.ReplicationSlots) == 0 && len(cr.Status.ReplicationSlots) == 0 {
+		return
+	}
+	observed, err := r.pg.GetReplicationSlots(cr.Spec.Database)
+	if err != nil {
+		reqLogger.Error(err, "Could not read replication slots")
+		return
+	}
+
+	var slots []dbv1alpha1.PostgresReplicationSlotStatus
+	for _, slot := range cr.Spec.ReplicationSlots {
+		status := dbv1alpha1.PostgresReplicationSlotStatus{Name: slot.Name, Plugin: slot.Plugin}
+		if known := findSlotStatus(cr.Status.ReplicationSlots, slot.Name); known != nil {
+			status.InactiveSince = known.InactiveSince
+		}
+
+		idx := slices.IndexFunc(observed, func(s postgres.PostgresReplicationSlot) bool { return s.Name == slot.Name })
+		if idx >= 0 && observed[idx].Plugin != slot.Plugin {
+			reqLogger.Info(fmt.Sprintf("Replication slot %s uses plugin %s, recreating it with %s", slot.Name, observed[idx].Plugin, slot.Plugin))
+			err = r.pg.DropReplicationSlot(cr.Spec.Database, slot.Name)
+			if err != nil {
+				reqLogger.Error(err, fmt.Sprintf("Could not drop replication slot %s", slot.Name))
+				continue
+			}
+			idx = -1
+		}
+		if idx < 0 {
+			err = r.pg.CreateReplicationSlot(cr.Spec.Database, slot.Name, slot.Plugin)
+			if err != nil {
+				reqLogger.Error(err, fmt.Sprintf("Could not create replication slot %s", slot.Name))
+				continue
+			}
+			status.InactiveSince = &now
+			slots = append(slots, status)
+			continue
+		}
+
+		status.Active = observed[idx].Active
+		status.LagBytes = observed[idx].LagBytes
+		if status.Active {
+			status.InactiveSince = nil
+		} else if status.InactiveSince == nil {
+			status.InactiveSince = &now
+		}
+
+		if !status.Active && slot.DropInactiveAfter != nil && now.Sub(status.InactiveSince.Time) > slot.DropInactiveAfter.Duration {
+			reqLogger.Info(fmt.Sprintf("Replication slot %s was inactive since %s, recreating it", slot.Name, status.InactiveSince.UTC().Format(time.RFC3339)))
+			err = r.pg.DropReplicationSlot(cr.Spec.Database, slot.Name)
+			if err == nil {
+				err = r.pg.CreateReplicationSlot(cr.Spec.Database, slot.Name, slot.Plugin)
+			}
+			if err != nil {
+				reqLogger.Error(err, fmt.Sprintf("Could not recreate replication slot %s", slot.Name))
+			} else {
+				status.LagBytes = 0
+				status.InactiveSince = &now
+			}
+		}
+		slots = append(slots, status)
+	}
+
+	for _, status := range cr.Status.ReplicationSlots {
+		if findSlotStatus(slots, status.Name) != nil {
+			continue
+		}
+		if slices.ContainsFunc(cr.Spec.ReplicationSlots, func(s dbv1alpha1.PostgresReplicationSlot) bool { return s.Name == status.Name }) {
+			// Still wanted but failed to reconcile, keep what is known about it
+			slots = append(slots, status)
+			continue
+		}
+		err = r.pg.DropReplicationSlot(cr.Spec.Database, status.Name)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not drop replication slot %s", status.Name))
+			slots = append(slots, status)
+		}
+	}
+	cr.Status.ReplicationSlots = slots
+}
+
+// dropReplicationSlots drops all replication slots created for cr
+func (r *PostgresReconciler) dropReplicationSlots(cr *dbv1alpha1.Postgres) error {
+	for _, slot := range cr.Status.ReplicationSlots {
+		err := r.pg.DropReplicationSlot(cr.Spec.Database, slot.Name)
+		if err != nil {
+			return err
+		}
+	}
+	cr.Status.ReplicationSlots = nil
+	return nil
+}
+
+func findSlotStatus(slots []dbv1alpha1.PostgresReplicationSlotStatus, name string) *dbv1alpha1.PostgresReplicationSlotStatus {
+	for i := range slots {
+		if slots[i].Name == name {
+			return &slots[i]
+		}
+	}
+	return nil
+}
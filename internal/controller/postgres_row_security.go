@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// reconcileRowSecurity enables row level security on the tables of cr and makes their policies match
+// the spec. Policies are recreated when their spec changed or when pg_policies shows they were altered
+// since the operator created them. Policies removed from the spec are dropped, and row level security
+// is disabled again on tables removed from the spec.
+func (r *PostgresReconciler) reconcileRowSecurity(reqLogger logr.Logger, cr *dbv1alpha1.Postgres) {
+	var (
+		applied []dbv1alpha1.PostgresPolicyStatus
+		enabled []dbv1alpha1.PostgresTableRef
+	)
+	for _, table := range cr.Spec.RowSecurity {
+		ref := dbv1alpha1.PostgresTableRef{Schema: table.Schema, Table: table.Table}
+		err := r.pg.EnableRowSecurity(cr.Spec.Database, table.Schema, table.Table, table.Force)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not enable row level security on %s.%s", table.Schema, table.Table))
+			if slices.Contains(cr.Status.RowSecurityTables, ref) {
+				enabled = append(enabled, ref)
+			}
+			continue
+		}
+		enabled = append(enabled, ref)
+		observed, err := r.pg.GetPolicies(cr.Spec.Database, table.Schema, table.Table)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not read policies of %s.%s", table.Schema, table.Table))
+			continue
+		}
+
+		for _, policy := range table.Policies {
+			desired := postgres.PostgresPolicy{
+				Schema:      table.Schema,
+				Table:       table.Table,
+				Name:        policy.Name,
+				Command:     policy.Command,
+				Restrictive: policy.Restrictive,
+				Roles:       policyRoles(rolesForSchema(cr, table.Schema), policy.Roles),
+				Using:       policy.Using,
+				WithCheck:   policy.WithCheck,
+			}
+			hash := policyHash(desired)
+
+			status := findPolicyStatus(cr.Status.Policies, table.Schema, table.Table, policy.Name)
+			idx := slices.IndexFunc(observed, func(p postgres.PostgresPolicy) bool { return p.Name == policy.Name })
+			if status != nil && status.Hash == hash && idx >= 0 {
+				if !policyDrifted(desired, *status, observed[idx]) {
+					applied = append(applied, *status)
+					continue
+				}
+				reqLogger.Info(fmt.Sprintf("Policy %s on %s.%s was changed outside of the operator, recreating it", policy.Name, table.Schema, table.Table))
+			}
+
+			stored, err := r.pg.ReplacePolicy(cr.Spec.Database, desired)
+			if err != nil {
+				reqLogger.Error(err, fmt.Sprintf("Could not create policy %s on %s.%s", policy.Name, table.Schema, table.Table))
+				continue
+			}
+			applied = append(applied, dbv1alpha1.PostgresPolicyStatus{
+				Schema:    table.Schema,
+				Table:     table.Table,
+				Name:      policy.Name,
+				Hash:      hash,
+				Using:     stored.Using,
+				WithCheck: stored.WithCheck,
+			})
+		}
+	}
+
+	for _, status := range cr.Status.Policies {
+		if findPolicyStatus(applied, status.Schema, status.Table, status.Name) != nil {
+			continue
+		}
+		// Still wanted but failed to reconcile, keep what is known about it
+		if policyInSpec(cr.Spec.RowSecurity, status) {
+			applied = append(applied, status)
+			continue
+		}
+		err := r.pg.DropPolicy(cr.Spec.Database, status.Schema, status.Table, status.Name)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not drop policy %s on %s.%s", status.Name, status.Schema, status.Table))
+			applied = append(applied, status)
+		}
+	}
+
+	// Without policies nobody but the owner can access a table with row security, it is disabled on
+	// tables removed from the spec. Tables of policies recorded before tables were recorded are included.
+	previous := slices.Clone(cr.Status.RowSecurityTables)
+	for _, status := range cr.Status.Policies {
+		previous = append(previous, dbv1alpha1.PostgresTableRef{Schema: status.Schema, Table: status.Table})
+	}
+	var disabled []dbv1alpha1.PostgresTableRef
+	for _, ref := range previous {
+		if slices.Contains(enabled, ref) || slices.Contains(disabled, ref) || rowSecurityInSpec(cr.Spec.RowSecurity, ref) {
+			continue
+		}
+		err := r.pg.DisableRowSecurity(cr.Spec.Database, ref.Schema, ref.Table)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not disable row level security on %s.%s", ref.Schema, ref.Table))
+			enabled = append(enabled, ref)
+			continue
+		}
+		disabled = append(disabled, ref)
+	}
+	cr.Status.Policies = applied
+	cr.Status.RowSecurityTables = enabled
+}
+
+func rowSecurityInSpec(tables []dbv1alpha1.PostgresRowSecurity, ref dbv1alpha1.PostgresTableRef) bool {
+	return slices.ContainsFunc(tables, func(table dbv1alpha1.PostgresRowSecurity) bool {
+		return table.Schema == ref.Schema && table.Table == ref.Table
+	})
+}
+
+// policyRoles resolves the group roles a policy applies to
+func policyRoles(roles dbv1alpha1.PostgresRoles, refs []dbv1alpha1.PostgresPolicyRole) []string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, groupRole(roles, ref))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// groupRole resolves a reference to a group role of the database
+func groupRole(roles dbv1alpha1.PostgresRoles, ref dbv1alpha1.PostgresPolicyRole) string {
+	switch ref {
+	case "owner":
+		return roles.Owner
+	case "writer":
+		return roles.Writer
+	default:
+		return roles.Reader
+	}
+}
+
+func policyHash(policy postgres.PostgresPolicy) string {
+	data, _ := json.Marshal(policy)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// policyDrifted compares a policy read from the server with what the operator created.
+// Expressions are compared with their deparsed form stored when the policy was created.
+func policyDrifted(desired postgres.PostgresPolicy, status dbv1alpha1.PostgresPolicyStatus, observed postgres.PostgresPolicy) bool {
+	roles := slices.Clone(observed.Roles)
+	slices.Sort(roles)
+	return observed.Command != desired.Command ||
+		observed.Restrictive != desired.Restrictive ||
+		!slices.Equal(roles, desired.Roles) ||
+		observed.Using != status.Using ||
+		observed.WithCheck != status.WithCheck
+}
+
+func findPolicyStatus(policies []dbv1alpha1.PostgresPolicyStatus, schema, table, name string) *dbv1alpha1.PostgresPolicyStatus {
+	for i := range policies {
+		if policies[i].Schema == schema && policies[i].Table == table && policies[i].Name == name {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+func policyInSpec(tables []dbv1alpha1.PostgresRowSecurity, status dbv1alpha1.PostgresPolicyStatus) bool {
+	for _, table := range tables {
+		if table.Schema != status.Schema || table.Table != status.Table {
+			continue
+		}
+		for _, policy := range table.Policies {
+			if policy.Name == status.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
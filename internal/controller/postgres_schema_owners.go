@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+)
+
+// reconcileSchemaOwners creates the owner, reader and writer roles of the schemas of cr that have an owner
+// of their own, and hands the schemas over to their owner. Roles of schemas removed from schemaOwners are
+// dropped, objects they owned go back to the owner role of the database.
+func (r *PostgresReconciler) reconcileSchemaOwners(reqLogger logr.Logger, cr *dbv1alpha1.Postgres) {
+	var applied []dbv1alpha1.PostgresSchemaRoles
+	for _, schemaOwner := range cr.Spec.SchemaOwners {
+		if !slices.Contains(cr.Spec.Schemas, schemaOwner.Schema) {
+			reqLogger.Error(fmt.Errorf("schema %s is not listed in schemas", schemaOwner.Schema), "Could not set schema owner")
+			continue
+		}
+		owner := schemaOwner.Role
+		if owner == "" {
+			owner = fmt.Sprintf("%s-%s-group", cr.Spec.Database, schemaOwner.Schema)
+		}
+
+		if known := findSchemaRoles(cr.Status.SchemaRoles, schemaOwner.Schema); known != nil {
+			// The schema and its objects follow the renamed role
+			if known.Roles.Owner != owner {
+				err := r.pg.RenameGroupRole(known.Roles.Owner, owner)
+				if err != nil {
+					reqLogger.Error(err, fmt.Sprintf("Could not rename owner of schema %s", schemaOwner.Schema))
+					applied = append(applied, *known)
+					continue
+				}
+				known.Roles.Owner = owner
+			}
+			applied = append(applied, *known)
+			continue
+		}
+
+		roles := dbv1alpha1.PostgresRoles{
+			Owner:  owner,
+			Reader: fmt.Sprintf("%s-%s-reader", cr.Spec.Database, schemaOwner.Schema),
+			Writer: fmt.Sprintf("%s-%s-writer", cr.Spec.Database, schemaOwner.Schema),
+		}
+		err := r.createSchemaRoles(cr, schemaOwner.Schema, roles)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not set owner of schema %s", schemaOwner.Schema))
+			continue
+		}
+		applied = append(applied, dbv1alpha1.PostgresSchemaRoles{Schema: schemaOwner.Schema, Roles: roles})
+	}
+
+	for _, known := range cr.Status.SchemaRoles {
+		if findSchemaRoles(applied, known.Schema) != nil {
+			continue
+		}
+		// Still wanted but failed to reconcile, keep what is known about it
+		if slices.ContainsFunc(cr.Spec.SchemaOwners, func(o dbv1alpha1.PostgresSchemaOwner) bool { return o.Schema == known.Schema }) {
+			applied = append(applied, known)
+			continue
+		}
+		err := r.dropSchemaRoles(known, cr.Status.Roles.Owner, cr.Spec.Database)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not drop roles of schema %s", known.Schema))
+			applied = append(applied, known)
+		}
+	}
+	cr.Status.SchemaRoles = applied
+}
+
+func (r *PostgresReconciler) createSchemaRoles(cr *dbv1alpha1.Postgres, schema string, roles dbv1alpha1.PostgresRoles) error {
+	tag := objectTag(r.instanceFilter, r.cluster, "Postgres", cr, cr.Spec.Database)
+	for _, role := range []string{roles.Owner, roles.Reader, roles.Writer} {
+		err := r.pg.CreateGroupRole(role, tag)
+		if err != nil {
+			return err
+		}
+	}
+	// Only members of a role may hand a schema over to it
+	err := r.pg.GrantRole(roles.Owner, r.pg.GetUser())
+	if err != nil {
+		return err
+	}
+	err = r.pg.CreateSchema(cr.Spec.Database, roles.Owner, schema, tag)
+	if err != nil {
+		return err
+	}
+	// The schema may have existed before
+	return r.pg.AlterSchemaOwner(cr.Spec.Database, schema, roles.Owner)
+}
+
+// dropSchemaRoles drops the roles of a schema, objects owned by them are reassigned to newOwner
+func (r *PostgresReconciler) dropSchemaRoles(schemaRoles dbv1alpha1.PostgresSchemaRoles, newOwner, database string) error {
+	for _, role := range []string{schemaRoles.Roles.Owner, schemaRoles.Roles.Reader, schemaRoles.Roles.Writer} {
+		err := r.pg.DropRole(role, newOwner, database)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rolesForSchema returns the group roles privileges on objects in schema are granted to
+func rolesForSchema(cr *dbv1alpha1.Postgres, schema string) dbv1alpha1.PostgresRoles {
+	if known := findSchemaRoles(cr.Status.SchemaRoles, schema); known != nil {
+		return known.Roles
+	}
+	return cr.Status.Roles
+}
+
+func findSchemaRoles(schemaRoles []dbv1alpha1.PostgresSchemaRoles, schema string) *dbv1alpha1.PostgresSchemaRoles {
+	for i := range schemaRoles {
+		if schemaRoles[i].Schema == schema {
+			return &schemaRoles[i]
+		}
+	}
+	return nil
+}
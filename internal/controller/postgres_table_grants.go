@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+)
+
+// tableGrantRole resolves the group role a table grant refers to
+func tableGrantRole(roles dbv1alpha1.PostgresRoles, role string) string {
+	if role == "writer" {
+		return roles.Writer
+	}
+	return roles.Reader
+}
+
+// excludedTables lists the tables of schema whose privileges for role are set by table grants
+func excludedTables(grants []dbv1alpha1.PostgresTableGrant, schema, role string) []string {
+	var tables []string
+	for _, grant := range grants {
+		if grant.Schema == schema && grant.Role == role {
+			tables = append(tables, grant.Table)
+		}
+	}
+	return tables
+}
+
+// revokeRemovedTableGrants revokes the privileges of table grants that are no longer in the spec
+func (r *PostgresReconciler) revokeRemovedTableGrants(reqLogger logr.Logger, cr *dbv1alpha1.Postgres) {
+	cr.Status.TableGrants = slices.DeleteFunc(cr.Status.TableGrants, func(applied dbv1alpha1.PostgresTableGrantRef) bool {
+		if slices.ContainsFunc(cr.Spec.TableGrants, func(grant dbv1alpha1.PostgresTableGrant) bool {
+			return grant.Schema == applied.Schema && grant.Table == applied.Table && grant.Role == applied.Role
+		}) {
+			return false
+		}
+		role := tableGrantRole(rolesForSchema(cr, applied.Schema), applied.Role)
+		err := r.pg.RevokeTablePrivileges(cr.Spec.Database, applied.Schema, applied.Table, role)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not revoke privileges of %s on %s.%s", role, applied.Schema, applied.Table))
+			return false
+		}
+		return true
+	})
+}
+
+// applyTableGrants sets the privileges of all table grants in the spec
+func (r *PostgresReconciler) applyTableGrants(reqLogger logr.Logger, cr *dbv1alpha1.Postgres) {
+	for _, grant := range cr.Spec.TableGrants {
+		role := tableGrantRole(rolesForSchema(cr, grant.Schema), grant.Role)
+		privs := make([]string, len(grant.Privileges))
+		for i, priv := range grant.Privileges {
+			privs[i] = string(priv)
+		}
+		err := r.pg.SetTablePrivileges(postgres.PostgresTableGrant{
+			DB:            cr.Spec.Database,
+			Role:          role,
+			Schema:        grant.Schema,
+			Table:         grant.Table,
+			Privs:         privs,
+			Columns:       grant.Columns,
+			ExceptColumns: grant.ExceptColumns,
+		})
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Could not give %s privileges on %s.%s", role, grant.Schema, grant.Table))
+			continue
+		}
+		ref := dbv1alpha1.PostgresTableGrantRef{Schema: grant.Schema, Table: grant.Table, Role: grant.Role}
+		if !slices.Contains(cr.Status.TableGrants, ref) {
+			cr.Status.TableGrants = append(cr.Status.TableGrants, ref)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// PostgresCredentialRequestReconciler reconciles a PostgresCredentialRequest object
+type PostgresCredentialRequestReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	pg             postgres.PG
+	pgHost         string
+	pgUriArgs      string
+	instanceFilter string
+	cluster        string
+	serverCABundle string
+	now            func() time.Time
+}
+
+// NewPostgresCredentialRequestReconciler returns a new reconcile.Reconciler
+func NewPostgresCredentialRequestReconciler(mgr manager.Manager, cfg *config.Cfg, pg postgres.PG) *PostgresCredentialRequestReconciler {
+	conn := postgres.ClientConnectionArgs(cfg)
+	return &PostgresCredentialRequestReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		pg:             pg,
+		pgHost:         cfg.PostgresHost,
+		pgUriArgs:      conn.UriArgs,
+		instanceFilter: cfg.AnnotationFilter,
+		cluster:        cfg.ClusterName,
+		serverCABundle: conn.SSLRootCert,
+		now:            time.Now,
+	}
+}
+
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgrescredentialrequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgrescredentialrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgrescredentialrequests/finalizers,verbs=update
+
+// Reconcile issues a login role inheriting the group role of a PostgresUser, which is valid until
+// the lease of the request expires. Expired roles are dropped.
+func (r *PostgresCredentialRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling PostgresCredentialRequest")
+
+	// Fetch the PostgresCredentialRequest instance
+	instance := &dbv1alpha1.PostgresCredentialRequest{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !utils.MatchesInstanceAnnotation(instance.Annotations, r.instanceFilter) {
+		return ctrl.Result{}, nil
+	}
+
+	// Deletion logic
+	if instance.GetDeletionTimestamp() != nil {
+		if instance.Status.PostgresRole != "" && !instance.Status.Expired {
+			err = r.revoke(instance)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(instance, "finalizer.db.movetokube.com")
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Expired {
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.AddFinalizer(instance, "finalizer.db.movetokube.com") {
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Lease expiry
+	if expiresAt := instance.Status.ExpiresAt; expiresAt != nil && !r.now().Before(expiresAt.Time) {
+		reqLogger.Info(fmt.Sprintf("Lease of role %s expired, dropping it", instance.Status.PostgresRole))
+		err = r.revoke(instance)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		err = r.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialRequestSecretName(instance), Namespace: instance.Namespace}})
+		if err != nil && !errors.IsNotFound(err) {
+			return r.requeue(ctx, instance, err)
+		}
+		instance.Status.Expired = true
+		return r.finish(ctx, instance)
+	}
+
+	password, err := utils.GetSecureRandomString(15)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	if instance.Status.PostgresRole == "" {
+		if instance.Spec.TTL.Duration <= 0 {
+			return r.requeue(ctx, instance, fmt.Errorf("ttl %s is not positive", instance.Spec.TTL.Duration))
+		}
+		user, err := r.getPostgresUserCR(ctx, instance)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		// Requests go away along with their user
+		err = controllerutil.SetControllerReference(user, instance, r.Scheme)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+
+		// The role is recorded before it is created, a retry creates the same role and leaks none
+		expiresAt := metav1.NewTime(r.now().Add(instance.Spec.TTL.Duration).Truncate(time.Second))
+		instance.Status.PostgresRole = fmt.Sprintf("%s-lease-%s", user.Spec.Role, utils.GetRandomString(6))
+		instance.Status.PostgresGroup = user.Status.PostgresGroup
+		instance.Status.DatabaseName = user.Status.DatabaseName
+		instance.Status.ExpiresAt = &expiresAt
+		err = r.Status().Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if instance.Status.PostgresLogin == "" {
+		login, err := r.issue(instance, password)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		instance.Status.PostgresLogin = login
+		err = r.Status().Update(ctx, instance)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+	} else {
+		// The password only lives in the secret, a lost secret gets a new one
+		found := &corev1.Secret{}
+		err = r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: credentialRequestSecretName(instance)}, found)
+		if err == nil {
+			if !metav1.IsControlledBy(found, instance) {
+				return r.requeue(ctx, instance, fmt.Errorf("secret \"%s\" already exists", found.Name))
+			}
+			return r.finish(ctx, instance)
+		}
+		if !errors.IsNotFound(err) {
+			return r.requeue(ctx, instance, err)
+		}
+		err = r.pg.UpdatePassword(instance.Status.PostgresRole, password)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+	}
+
+	secret := r.newSecretForCR(instance, password)
+	if r.serverCABundle != "" {
+		bundle, err := os.ReadFile(r.serverCABundle)
+		if err != nil {
+			return r.requeue(ctx, instance, fmt.Errorf("read CA bundle of the server: %w", err))
+		}
+		secret.Data[caBundleKey] = bundle
+	}
+	if err := controllerutil.SetControllerReference(instance, secret, r.Scheme); err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	reqLogger.Info("Creating secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+	err = r.Create(ctx, secret)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	return r.finish(ctx, instance)
+}
+
+// issue creates the role recorded in the status of instance, a role left over by an earlier attempt
+// is dropped first. Postgres refuses logins after VALID UNTIL, even if dropping the role is delayed.
+func (r *PostgresCredentialRequestReconciler) issue(instance *dbv1alpha1.PostgresCredentialRequest, password string) (string, error) {
+	role := instance.Status.PostgresRole
+	group := instance.Status.PostgresGroup
+	tag := objectTag(r.instanceFilter, r.cluster, "PostgresCredentialRequest", instance, instance.Status.DatabaseName)
+	existing, err := r.pg.GetTag("ROLE", role)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		if !existing.SameOwner(tag) {
+			return "", fmt.Errorf("%w: %s belongs to %s", postgres.ErrForeignOwner, role, existing)
+		}
+		err = r.revoke(instance)
+		if err != nil {
+			return "", err
+		}
+	}
+	login, err := r.pg.CreateExpiringUserRole(role, password, instance.Status.ExpiresAt.UTC(), tag)
+	if err != nil {
+		return "", err
+	}
+	err = r.pg.GrantRole(group, role)
+	if err != nil {
+		return "", err
+	}
+	// Objects created with the role are owned by the group and outlive the lease
+	err = r.pg.AlterDefaultLoginRole(role, group)
+	if err != nil {
+		return "", err
+	}
+	return login, nil
+}
+
+// revoke drops the issued role. What it created is owned by the group role and kept.
+func (r *PostgresCredentialRequestReconciler) revoke(instance *dbv1alpha1.PostgresCredentialRequest) error {
+	return r.pg.DropRole(instance.Status.PostgresRole, instance.Status.PostgresGroup, instance.Status.DatabaseName)
+}
+
+func (r *PostgresCredentialRequestReconciler) getPostgresUserCR(ctx context.Context, instance *dbv1alpha1.PostgresCredentialRequest) (*dbv1alpha1.PostgresUser, error) {
+	user := dbv1alpha1.PostgresUser{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.User}, &user)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.MatchesInstanceAnnotation(user.Annotations, r.instanceFilter) {
+		return nil, fmt.Errorf("user \"%s\" is not managed by this operator", user.Name)
+	}
+	if !user.Status.Succeeded || user.Status.PostgresGroup == "" {
+		return nil, fmt.Errorf("user \"%s\" is not ready", user.Name)
+	}
+	return &user, nil
+}
+
+func credentialRequestSecretName(cr *dbv1alpha1.PostgresCredentialRequest) string {
+	if cr.Spec.SecretName != "" {
+		return cr.Spec.SecretName
+	}
+	return cr.Name
+}
+
+func (r *PostgresCredentialRequestReconciler) newSecretForCR(cr *dbv1alpha1.PostgresCredentialRequest, password string) *corev1.Secret {
+	hostname, port, err := net.SplitHostPort(r.pgHost)
+	if err != nil {
+		hostname = r.pgHost
+		port = "5432"
+	}
+	role := cr.Status.PostgresRole
+	data := map[string][]byte{
+		"POSTGRES_URL":  []byte(postgresURL(cr.Status.PostgresLogin, password, r.pgHost, cr.Status.DatabaseName, r.pgUriArgs)),
+		"HOST":          []byte(r.pgHost),
+		"DATABASE_NAME": []byte(cr.Status.DatabaseName),
+		"URI_ARGS":      []byte(r.pgUriArgs),
+		"ROLE":          []byte(role),
+		"PASSWORD":      []byte(password),
+		"LOGIN":         []byte(cr.Status.PostgresLogin),
+		"PORT":          []byte(port),
+		"HOSTNAME":      []byte(hostname),
+		"EXPIRES_AT":    []byte(cr.Status.ExpiresAt.UTC().Format(time.RFC3339)),
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialRequestSecretName(cr),
+			Namespace: cr.Namespace,
+			Labels:    map[string]string{"app": cr.Name},
+		},
+		Data: data,
+	}
+}
+
+func (r *PostgresCredentialRequestReconciler) requeue(ctx context.Context, cr *dbv1alpha1.PostgresCredentialRequest, reason error) (ctrl.Result, error) {
+	cr.Status.Succeeded = false
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, reason
+}
+
+// finish records success and comes back when the lease expires
+func (r *PostgresCredentialRequestReconciler) finish(ctx context.Context, cr *dbv1alpha1.PostgresCredentialRequest) (ctrl.Result, error) {
+	cr.Status.Succeeded = true
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cr.Status.Expired || cr.Status.ExpiresAt == nil {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: cr.Status.ExpiresAt.Sub(r.now())}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PostgresCredentialRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dbv1alpha1.PostgresCredentialRequest{}).
+		Owns(&corev1.Secret{}).
+		Complete(r)
+}
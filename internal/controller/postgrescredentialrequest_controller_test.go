@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	mockpg "github.com/movetokube/postgres-operator/pkg/postgres/mock"
+)
+
+var _ = Describe("PostgresCredentialRequest Controller", func() {
+	const (
+		name      = "test-lease"
+		namespace = "operator"
+		group     = "app-db-reader"
+	)
+
+	var (
+		req      reconcile.Request
+		mockCtrl *gomock.Controller
+		pg       *mockpg.MockPG
+		rp       *PostgresCredentialRequestReconciler
+		cl       client.Client
+		now      time.Time
+		user     *dbv1alpha1.PostgresUser
+		request  *dbv1alpha1.PostgresCredentialRequest
+	)
+
+	create := func(obj client.Object, status func()) {
+		Expect(cl.Create(ctx, obj)).To(Succeed())
+		if status != nil {
+			status()
+			Expect(cl.Status().Update(ctx, obj)).To(Succeed())
+		}
+	}
+
+	runReconcile := func(rp *PostgresCredentialRequestReconciler, ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		result, err := rp.Reconcile(ctx, req)
+		if k8sManager != nil {
+			k8sManager.GetCache().WaitForCacheSync(ctx)
+		}
+		return result, err
+	}
+
+	clearAll := func(list client.ObjectList, obj client.Object, namespace string) {
+		Expect(k8sClient.List(ctx, list, client.InNamespace(namespace))).To(Succeed())
+		items, err := meta.ExtractList(list)
+		Expect(err).NotTo(HaveOccurred())
+		for _, el := range items {
+			item := el.(client.Object)
+			org := item.DeepCopyObject().(client.Object)
+			item.SetFinalizers(nil)
+			Expect(k8sClient.Patch(ctx, item, client.MergeFrom(org))).To(Succeed())
+		}
+		Expect(k8sClient.DeleteAllOf(ctx, obj, client.InNamespace(namespace))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		pg = mockpg.NewMockPG(mockCtrl)
+		cl = k8sClient
+		now = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+		rp = &PostgresCredentialRequestReconciler{
+			Client: managerClient,
+			Scheme: scheme.Scheme,
+			pg:     pg,
+			pgHost: "postgres.local",
+			now:    func() time.Time { return now },
+		}
+		if k8sManager != nil {
+			rp.SetupWithManager(k8sManager)
+		}
+		req = reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+
+		user = &dbv1alpha1.PostgresUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-user", Namespace: namespace},
+			Spec: dbv1alpha1.PostgresUserSpec{
+				Role:       "app",
+				Database:   "app-db",
+				SecretName: "app",
+			},
+		}
+		request = &dbv1alpha1.PostgresCredentialRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: dbv1alpha1.PostgresCredentialRequestSpec{
+				User: "app-user",
+				TTL:  metav1.Duration{Duration: time.Hour},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		clearAll(&dbv1alpha1.PostgresCredentialRequestList{}, &dbv1alpha1.PostgresCredentialRequest{}, namespace)
+		clearAll(&dbv1alpha1.PostgresUserList{}, &dbv1alpha1.PostgresUser{}, namespace)
+		Expect(k8sClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(namespace))).To(Succeed())
+		if k8sManager != nil {
+			k8sManager.GetCache().WaitForCacheSync(ctx)
+		}
+		mockCtrl.Finish()
+	})
+
+	readyUser := func() {
+		create(user, func() {
+			user.Status = dbv1alpha1.PostgresUserStatus{
+				Succeeded:     true,
+				PostgresRole:  "app-abc123",
+				PostgresGroup: group,
+				DatabaseName:  "app-db",
+			}
+		})
+	}
+
+	issued := func(expiresAt time.Time) {
+		request.Finalizers = []string{"finalizer.db.movetokube.com"}
+		create(request, func() {
+			request.Status = dbv1alpha1.PostgresCredentialRequestStatus{
+				Succeeded:     true,
+				PostgresRole:  "app-lease-x1y2z3",
+				PostgresLogin: "app-lease-x1y2z3",
+				PostgresGroup: group,
+				DatabaseName:  "app-db",
+				ExpiresAt:     &metav1.Time{Time: expiresAt},
+			}
+		})
+	}
+
+	It("should not requeue if PostgresCredentialRequest does not exist", func() {
+		result, err := runReconcile(rp, ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("should issue a role inheriting the group of the user until the lease expires", func() {
+		readyUser()
+		create(request, nil)
+		var role string
+		pg.EXPECT().GetTag("ROLE", gomock.Any()).Return(nil, nil)
+		pg.EXPECT().CreateExpiringUserRole(gomock.Any(), gomock.Any(), now.Add(time.Hour), gomock.Any()).DoAndReturn(
+			func(r, _ string, _ time.Time, tag any) (string, error) {
+				role = r
+				return r, nil
+			})
+		pg.EXPECT().GrantRole(group, gomock.Any()).Return(nil)
+		pg.EXPECT().AlterDefaultLoginRole(gomock.Any(), group).Return(nil)
+
+		result, err := runReconcile(rp, ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+		Expect(role).To(HavePrefix("app-lease-"))
+
+		found := &dbv1alpha1.PostgresCredentialRequest{}
+		Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+		Expect(found.Status.Succeeded).To(BeTrue())
+		Expect(found.Status.PostgresRole).To(Equal(role))
+		Expect(found.Status.PostgresGroup).To(Equal(group))
+		Expect(found.Status.ExpiresAt.Time).To(BeTemporally("==", now.Add(time.Hour)))
+		Expect(found.OwnerReferences).To(HaveLen(1))
+		Expect(found.OwnerReferences[0].Name).To(Equal("app-user"))
+
+		secret := &corev1.Secret{}
+		Expect(cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)).To(Succeed())
+		Expect(string(secret.Data["ROLE"])).To(Equal(role))
+		Expect(secret.Data["PASSWORD"]).NotTo(BeEmpty())
+		Expect(string(secret.Data["EXPIRES_AT"])).To(Equal("2030-01-01T13:00:00Z"))
+		Expect(string(secret.Data["POSTGRES_URL"])).To(Equal(fmt.Sprintf("postgresql://%s:%s@postgres.local/app-db", role, secret.Data["PASSWORD"])))
+	})
+
+	It("should create the recorded role again when issuing it failed", func() {
+		readyUser()
+		create(request, nil)
+		var role string
+		pg.EXPECT().GetTag("ROLE", gomock.Any()).Return(nil, nil)
+		pg.EXPECT().CreateExpiringUserRole(gomock.Any(), gomock.Any(), now.Add(time.Hour), gomock.Any()).DoAndReturn(
+			func(r, _ string, _ time.Time, tag any) (string, error) {
+				role = r
+				return r, nil
+			})
+		pg.EXPECT().GrantRole(group, gomock.Any()).Return(fmt.Errorf("connection reset"))
+
+		_, err := runReconcile(rp, ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("connection reset")))
+		found := &dbv1alpha1.PostgresCredentialRequest{}
+		Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+		Expect(found.Status.PostgresRole).To(Equal(role))
+		Expect(found.Status.PostgresLogin).To(BeEmpty())
+
+		// The half issued role is dropped and created again under the same name
+		pg.EXPECT().GetTag("ROLE", role).Return(&postgres.ObjectTag{
+			ManagedBy: postgres.ManagedBy, Kind: "PostgresCredentialRequest", Namespace: namespace, Name: name,
+		}, nil)
+		pg.EXPECT().DropRole(role, group, "app-db").Return(nil)
+		pg.EXPECT().CreateExpiringUserRole(role, gomock.Any(), now.Add(time.Hour), gomock.Any()).Return(role, nil)
+		pg.EXPECT().GrantRole(group, role).Return(nil)
+		pg.EXPECT().AlterDefaultLoginRole(role, group).Return(nil)
+
+		Expect(runReconcile(rp, ctx, req)).Error().NotTo(HaveOccurred())
+		Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+		Expect(found.Status.Succeeded).To(BeTrue())
+		Expect(found.Status.PostgresRole).To(Equal(role))
+		Expect(found.Status.PostgresLogin).To(Equal(role))
+	})
+
+	It("should refuse to drop a role of another resource", func() {
+		readyUser()
+		request.Finalizers = []string{"finalizer.db.movetokube.com"}
+		create(request, func() {
+			request.Status = dbv1alpha1.PostgresCredentialRequestStatus{
+				PostgresRole:  "app-lease-x1y2z3",
+				PostgresGroup: group,
+				DatabaseName:  "app-db",
+				ExpiresAt:     &metav1.Time{Time: now.Add(time.Hour)},
+			}
+		})
+		pg.EXPECT().GetTag("ROLE", "app-lease-x1y2z3").Return(&postgres.ObjectTag{
+			ManagedBy: postgres.ManagedBy, Kind: "PostgresUser", Namespace: namespace, Name: "other",
+		}, nil)
+		pg.EXPECT().DropRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		pg.EXPECT().CreateExpiringUserRole(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := runReconcile(rp, ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("belongs to PostgresUser operator/other")))
+	})
+
+	It("should reject a ttl that is not positive", func() {
+		request.Spec.TTL = metav1.Duration{Duration: -time.Minute}
+		err := cl.Create(ctx, request)
+		if k8sManager != nil {
+			Expect(err).To(MatchError(ContainSubstring("ttl must be positive")))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		readyUser()
+		pg.EXPECT().CreateExpiringUserRole(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err = runReconcile(rp, ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("ttl -1m0s is not positive")))
+		found := &dbv1alpha1.PostgresCredentialRequest{}
+		Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+		Expect(found.Status.Succeeded).To(BeFalse())
+		Expect(found.Status.PostgresRole).To(BeEmpty())
+	})
+
+	It("should add the CA bundle of the server to the secret", func() {
+		bundle := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		Expect(os.WriteFile(bundle, []byte("server CA"), 0o600)).To(Succeed())
+		rp.serverCABundle = bundle
+		issued(now.Add(30 * time.Minute))
+		pg.EXPECT().UpdatePassword("app-lease-x1y2z3", gomock.Any()).Return(nil)
+
+		Expect(runReconcile(rp, ctx, req)).Error().NotTo(HaveOccurred())
+
+		secret := &corev1.Secret{}
+		Expect(cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)).To(Succeed())
+		Expect(string(secret.Data["ca.crt"])).To(Equal("server CA"))
+	})
+
+	It("should wait for the user to be ready", func() {
+		create(user, nil)
+		create(request, nil)
+		pg.EXPECT().CreateUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := runReconcile(rp, ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("user \"app-user\" is not ready")))
+		found := &dbv1alpha1.PostgresCredentialRequest{}
+		Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+		Expect(found.Status.Succeeded).To(BeFalse())
+	})
+
+	It("should drop the role once the lease expired", func() {
+		issued(now.Add(-time.Minute))
+		Expect(cl.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}})).To(Succeed())
+		pg.EXPECT().DropRole("app-lease-x1y2z3", group, "app-db").Return(nil)
+
+		result, err := runReconcile(rp, ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		found := &dbv1alpha1.PostgresCredentialRequest{}
+		Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+		Expect(found.Status.Expired).To(BeTrue())
+		Expect(cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &corev1.Secret{})).To(Satisfy(errors.IsNotFound))
+
+		// Expired requests are left alone
+		Expect(runReconcile(rp, ctx, req)).Error().NotTo(HaveOccurred())
+	})
+
+	It("should issue a new password when the secret is lost", func() {
+		issued(now.Add(30 * time.Minute))
+		pg.EXPECT().UpdatePassword("app-lease-x1y2z3", gomock.Any()).Return(nil)
+
+		result, err := runReconcile(rp, ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(30 * time.Minute))
+
+		secret := &corev1.Secret{}
+		Expect(cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)).To(Succeed())
+		Expect(string(secret.Data["LOGIN"])).To(Equal("app-lease-x1y2z3"))
+	})
+
+	It("should drop the role when the request is deleted", func() {
+		issued(now.Add(30 * time.Minute))
+		Expect(cl.Delete(ctx, request)).To(Succeed())
+		pg.EXPECT().DropRole("app-lease-x1y2z3", group, "app-db").Return(nil)
+
+		Expect(runReconcile(rp, ctx, req)).Error().NotTo(HaveOccurred())
+		Expect(cl.Get(ctx, req.NamespacedName, &dbv1alpha1.PostgresCredentialRequest{})).To(Satisfy(errors.IsNotFound))
+	})
+})
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// PostgresGrantReconciler reconciles a PostgresGrant object
+type PostgresGrantReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	pg             postgres.PG
+	instanceFilter string
+}
+
+// NewPostgresGrantReconciler returns a new reconcile.Reconciler
+func NewPostgresGrantReconciler(mgr manager.Manager, cfg *config.Cfg, pg postgres.PG) *PostgresGrantReconciler {
+	return &PostgresGrantReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		pg:             pg,
+		instanceFilter: cfg.AnnotationFilter,
+	}
+}
+
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgresgrants,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgresgrants/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgresgrants/finalizers,verbs=update
+
+// Reconcile grants the group role of the target Postgres to the role of the PostgresUser
+func (r *PostgresGrantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling PostgresGrant")
+
+	// Fetch the PostgresGrant instance
+	instance := &dbv1alpha1.PostgresGrant{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !utils.MatchesInstanceAnnotation(instance.Annotations, r.instanceFilter) {
+		return ctrl.Result{}, nil
+	}
+
+	// Deletion logic
+	if instance.GetDeletionTimestamp() != nil {
+		if instance.Status.PostgresGroup != "" {
+			exist, err := r.grantedRolesExist(instance)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if exist {
+				err = r.pg.RevokeRole(instance.Status.PostgresGroup, instance.Status.PostgresRole)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		}
+		controllerutil.RemoveFinalizer(instance, "finalizer.db.movetokube.com")
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.AddFinalizer(instance, "finalizer.db.movetokube.com") {
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	user, err := r.getPostgresUserCR(ctx, instance)
+	if err != nil {
+		return r.requeue(ctx, instance, errors.NewInternalError(err))
+	}
+	database, err := getReadyPostgres(ctx, r.Client, targetKey(instance), r.instanceFilter)
+	if err != nil {
+		return r.requeue(ctx, instance, errors.NewInternalError(err))
+	}
+
+	if !grantAllowed(database, instance.Namespace) {
+		// The target may have withdrawn its permission, take back what was granted
+		if instance.Status.PostgresGroup != "" {
+			err = r.pg.RevokeRole(instance.Status.PostgresGroup, instance.Status.PostgresRole)
+			if err != nil {
+				return r.requeue(ctx, instance, errors.NewInternalError(err))
+			}
+			instance.Status.PostgresGroup = ""
+			instance.Status.PostgresRole = ""
+			instance.Status.DatabaseName = ""
+		}
+		err = fmt.Errorf("database \"%s/%s\" does not allow grants from namespace \"%s\"", database.Namespace, database.Name, instance.Namespace)
+		return r.requeue(ctx, instance, err)
+	}
+
+	role := user.Status.PostgresRole
+	group := groupRoleFor(instance.Spec.Privileges, database.Status.Roles)
+	if instance.Status.PostgresRole != role || instance.Status.PostgresGroup != group {
+		// A recreated PostgresUser has a new role, the old one was dropped along with its memberships
+		if instance.Status.PostgresGroup != "" && instance.Status.PostgresRole == role {
+			err = r.pg.RevokeRole(instance.Status.PostgresGroup, role)
+			if err != nil {
+				return r.requeue(ctx, instance, errors.NewInternalError(err))
+			}
+		}
+		err = r.pg.GrantRole(group, role)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		instance.Status.PostgresRole = role
+		instance.Status.PostgresGroup = group
+		instance.Status.DatabaseName = database.Spec.Database
+	}
+
+	reqLogger.Info("Reconciling done")
+	return r.finish(ctx, instance)
+}
+
+func (r *PostgresGrantReconciler) getPostgresUserCR(ctx context.Context, instance *dbv1alpha1.PostgresGrant) (*dbv1alpha1.PostgresUser, error) {
+	user := dbv1alpha1.PostgresUser{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.User}, &user)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.MatchesInstanceAnnotation(user.Annotations, r.instanceFilter) {
+		return nil, fmt.Errorf("user \"%s\" is not managed by this operator", user.Name)
+	}
+	if !user.Status.Succeeded || user.Status.PostgresRole == "" {
+		return nil, fmt.Errorf("user \"%s\" is not ready", user.Name)
+	}
+	return &user, nil
+}
+
+// grantedRolesExist reports whether both roles of a granted membership still exist on the server.
+// The membership is gone along with either of them, whether or not their resources are.
+func (r *PostgresGrantReconciler) grantedRolesExist(instance *dbv1alpha1.PostgresGrant) (bool, error) {
+	roles, err := r.pg.GetExistingRoles([]string{instance.Status.PostgresGroup, instance.Status.PostgresRole})
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, instance.Status.PostgresGroup) && slices.Contains(roles, instance.Status.PostgresRole), nil
+}
+
+func targetKey(instance *dbv1alpha1.PostgresGrant) types.NamespacedName {
+	namespace := instance.Spec.Postgres.Namespace
+	if namespace == "" {
+		namespace = instance.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: instance.Spec.Postgres.Name}
+}
+
+// grantAllowed checks the allow-list of database for grants from namespace
+func grantAllowed(database *dbv1alpha1.Postgres, namespace string) bool {
+	return database.Namespace == namespace ||
+		slices.Contains(database.Spec.AllowGrantsFrom, "*") ||
+		slices.Contains(database.Spec.AllowGrantsFrom, namespace)
+}
+
+func groupRoleFor(privileges string, roles dbv1alpha1.PostgresRoles) string {
+	switch privileges {
+	case "OWNER":
+		return roles.Owner
+	case "WRITE":
+		return roles.Writer
+	default:
+		return roles.Reader
+	}
+}
+
+func (r *PostgresGrantReconciler) requeue(ctx context.Context, cr *dbv1alpha1.PostgresGrant, reason error) (ctrl.Result, error) {
+	cr.Status.Succeeded = false
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, reason
+}
+
+func (r *PostgresGrantReconciler) finish(ctx context.Context, cr *dbv1alpha1.PostgresGrant) (ctrl.Result, error) {
+	cr.Status.Succeeded = true
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// grantsReferencing maps a Postgres or PostgresUser to the grants using it
+func (r *PostgresGrantReconciler) grantsReferencing(ctx context.Context, obj client.Object) []reconcile.Request {
+	grants := dbv1alpha1.PostgresGrantList{}
+	if err := r.List(ctx, &grants); err != nil {
+		log.FromContext(ctx).Error(err, "could not list PostgresGrants")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, grant := range grants.Items {
+		var matches bool
+		switch obj.(type) {
+		case *dbv1alpha1.Postgres:
+			matches = targetKey(&grant) == client.ObjectKeyFromObject(obj)
+		case *dbv1alpha1.PostgresUser:
+			matches = grant.Namespace == obj.GetNamespace() && grant.Spec.User == obj.GetName()
+		}
+		if matches {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&grant)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PostgresGrantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dbv1alpha1.PostgresGrant{}).
+		Watches(&dbv1alpha1.Postgres{}, handler.EnqueueRequestsFromMapFunc(r.grantsReferencing)).
+		Watches(&dbv1alpha1.PostgresUser{}, handler.EnqueueRequestsFromMapFunc(r.grantsReferencing)).
+		Complete(r)
+}
@@ -0,0 +1,353 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	mockpg "github.com/movetokube/postgres-operator/pkg/postgres/mock"
+)
+
+var _ = Describe("PostgresGrant Controller", func() {
+	const (
+		name            = "test-grant"
+		namespace       = "operator"
+		targetNamespace = "reporting"
+		userRole        = "app-abc123"
+	)
+
+	var (
+		req      reconcile.Request
+		mockCtrl *gomock.Controller
+		pg       *mockpg.MockPG
+		rp       *PostgresGrantReconciler
+		cl       client.Client
+		target   *dbv1alpha1.Postgres
+		user     *dbv1alpha1.PostgresUser
+		grant    *dbv1alpha1.PostgresGrant
+	)
+
+	create := func(obj client.Object, status func()) {
+		Expect(cl.Create(ctx, obj)).To(Succeed())
+		if status != nil {
+			status()
+			Expect(cl.Status().Update(ctx, obj)).To(Succeed())
+		}
+	}
+
+	runReconcile := func(rp *PostgresGrantReconciler, ctx context.Context, req reconcile.Request) (err error) {
+		_, err = rp.Reconcile(ctx, req)
+		if k8sManager != nil {
+			k8sManager.GetCache().WaitForCacheSync(ctx)
+		}
+		return err
+	}
+
+	clearAll := func(list client.ObjectList, obj client.Object, namespace string) {
+		Expect(k8sClient.List(ctx, list, client.InNamespace(namespace))).To(Succeed())
+		items, err := meta.ExtractList(list)
+		Expect(err).NotTo(HaveOccurred())
+		for _, el := range items {
+			item := el.(client.Object)
+			org := item.DeepCopyObject().(client.Object)
+			item.SetFinalizers(nil)
+			Expect(k8sClient.Patch(ctx, item, client.MergeFrom(org))).To(Succeed())
+		}
+		Expect(k8sClient.DeleteAllOf(ctx, obj, client.InNamespace(namespace))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		pg = mockpg.NewMockPG(mockCtrl)
+		cl = k8sClient
+		rp = &PostgresGrantReconciler{
+			Client: managerClient,
+			Scheme: scheme.Scheme,
+			pg:     pg,
+		}
+		if k8sManager != nil {
+			rp.SetupWithManager(k8sManager)
+		}
+		req = reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+
+		target = &dbv1alpha1.Postgres{
+			ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: targetNamespace},
+			Spec:       dbv1alpha1.PostgresSpec{Database: "reports"},
+		}
+		user = &dbv1alpha1.PostgresUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-user", Namespace: namespace},
+			Spec: dbv1alpha1.PostgresUserSpec{
+				Role:       "app",
+				Database:   "app-db",
+				SecretName: "app",
+			},
+		}
+		grant = &dbv1alpha1.PostgresGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: dbv1alpha1.PostgresGrantSpec{
+				User:       "app-user",
+				Postgres:   dbv1alpha1.PostgresGrantTarget{Name: "reports", Namespace: targetNamespace},
+				Privileges: "READ",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		clearAll(&dbv1alpha1.PostgresGrantList{}, &dbv1alpha1.PostgresGrant{}, namespace)
+		clearAll(&dbv1alpha1.PostgresUserList{}, &dbv1alpha1.PostgresUser{}, namespace)
+		Expect(clearPgs(targetNamespace)).To(Succeed())
+		if k8sManager != nil {
+			k8sManager.GetCache().WaitForCacheSync(ctx)
+		}
+		mockCtrl.Finish()
+	})
+
+	readyTarget := func() {
+		create(target, func() {
+			target.Status = dbv1alpha1.PostgresStatus{
+				Succeeded: true,
+				Roles: dbv1alpha1.PostgresRoles{
+					Owner:  "reports-group",
+					Reader: "reports-reader",
+					Writer: "reports-writer",
+				},
+			}
+		})
+	}
+
+	readyUser := func() {
+		create(user, func() {
+			user.Status = dbv1alpha1.PostgresUserStatus{Succeeded: true, PostgresRole: userRole}
+		})
+	}
+
+	It("should not requeue if PostgresGrant does not exist", func() {
+		res, err := rp.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Requeue).To(BeFalse())
+	})
+
+	Describe("Checking creation logic", func() {
+		Context("Target allows the namespace", func() {
+			BeforeEach(func() {
+				target.Spec.AllowGrantsFrom = []string{namespace}
+				readyTarget()
+				readyUser()
+				create(grant, nil)
+			})
+
+			It("should grant the reader role", func() {
+				pg.EXPECT().GrantRole("reports-reader", userRole).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				found := &dbv1alpha1.PostgresGrant{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.Succeeded).To(BeTrue())
+				Expect(found.Status.PostgresGroup).To(Equal("reports-reader"))
+				Expect(found.Status.PostgresRole).To(Equal(userRole))
+				Expect(found.Status.DatabaseName).To(Equal("reports"))
+				Expect(found.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+			})
+
+			It("should switch the group role when privileges change", func() {
+				pg.EXPECT().GrantRole("reports-reader", userRole).Return(nil)
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				found := &dbv1alpha1.PostgresGrant{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				found.Spec.Privileges = "WRITE"
+				Expect(cl.Update(ctx, found)).To(Succeed())
+
+				pg.EXPECT().RevokeRole("reports-reader", userRole).Return(nil)
+				pg.EXPECT().GrantRole("reports-writer", userRole).Return(nil)
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.PostgresGroup).To(Equal("reports-writer"))
+			})
+
+			It("should not grant again when nothing changed", func() {
+				pg.EXPECT().GrantRole("reports-reader", userRole).Return(nil).Times(1)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+
+		Context("Target allows all namespaces", func() {
+			BeforeEach(func() {
+				target.Spec.AllowGrantsFrom = []string{"*"}
+				readyTarget()
+				readyUser()
+				create(grant, nil)
+			})
+
+			It("should grant the role", func() {
+				pg.EXPECT().GrantRole("reports-reader", userRole).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+		})
+
+		Context("Target does not allow the namespace", func() {
+			BeforeEach(func() {
+				readyTarget()
+				readyUser()
+				create(grant, nil)
+			})
+
+			It("should not grant the role", func() {
+				pg.EXPECT().GrantRole(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				found := &dbv1alpha1.PostgresGrant{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.Succeeded).To(BeFalse())
+			})
+
+			It("should revoke a role granted before", func() {
+				found := &dbv1alpha1.PostgresGrant{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				found.Status = dbv1alpha1.PostgresGrantStatus{
+					Succeeded:     true,
+					PostgresRole:  userRole,
+					PostgresGroup: "reports-reader",
+				}
+				Expect(cl.Status().Update(ctx, found)).To(Succeed())
+				pg.EXPECT().RevokeRole("reports-reader", userRole).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.PostgresGroup).To(BeEmpty())
+			})
+		})
+
+		Context("User is not ready", func() {
+			BeforeEach(func() {
+				target.Spec.AllowGrantsFrom = []string{"*"}
+				readyTarget()
+				create(user, nil)
+				create(grant, nil)
+			})
+
+			It("should not grant the role", func() {
+				pg.EXPECT().GrantRole(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+			})
+		})
+	})
+
+	Describe("Checking deletion logic", func() {
+		BeforeEach(func() {
+			target.Spec.AllowGrantsFrom = []string{namespace}
+			readyTarget()
+			grant.SetFinalizers([]string{"finalizer.db.movetokube.com"})
+			create(grant, func() {
+				grant.Status = dbv1alpha1.PostgresGrantStatus{
+					Succeeded:     true,
+					PostgresRole:  userRole,
+					PostgresGroup: "reports-reader",
+				}
+			})
+		})
+
+		deleteGrant := func() {
+			Expect(cl.Delete(ctx, grant, &client.DeleteOptions{GracePeriodSeconds: new(int64)})).To(Succeed())
+		}
+
+		expectRemoved := func() {
+			found := &dbv1alpha1.PostgresGrant{}
+			err := cl.Get(ctx, req.NamespacedName, found)
+			if err != nil {
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			} else {
+				Expect(found.GetFinalizers()).To(BeEmpty())
+			}
+		}
+
+		Context("Roles still exist", func() {
+			BeforeEach(func() {
+				readyUser()
+				deleteGrant()
+				pg.EXPECT().GetExistingRoles([]string{"reports-reader", userRole}).Return([]string{"reports-reader", userRole}, nil)
+			})
+
+			It("should revoke the role and remove the finalizer", func() {
+				pg.EXPECT().RevokeRole("reports-reader", userRole).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				expectRemoved()
+			})
+
+			It("should keep the finalizer when revoking fails", func() {
+				pg.EXPECT().RevokeRole("reports-reader", userRole).Return(fmt.Errorf("could not revoke"))
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				found := &dbv1alpha1.PostgresGrant{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+			})
+		})
+
+		Context("Postgres was deleted without dropping its roles", func() {
+			BeforeEach(func() {
+				readyUser()
+				Expect(clearPgs(targetNamespace)).To(Succeed())
+				deleteGrant()
+			})
+
+			It("should still revoke the role", func() {
+				pg.EXPECT().GetExistingRoles([]string{"reports-reader", userRole}).Return([]string{"reports-reader", userRole}, nil)
+				pg.EXPECT().RevokeRole("reports-reader", userRole).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				expectRemoved()
+			})
+		})
+
+		Context("User was deleted", func() {
+			BeforeEach(func() {
+				deleteGrant()
+			})
+
+			It("should only remove the finalizer once its role is dropped", func() {
+				pg.EXPECT().GetExistingRoles([]string{"reports-reader", userRole}).Return([]string{"reports-reader"}, nil)
+				pg.EXPECT().RevokeRole(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+				expectRemoved()
+			})
+
+			It("should keep the finalizer when the roles cannot be read", func() {
+				pg.EXPECT().GetExistingRoles(gomock.Any()).Return(nil, fmt.Errorf("connection refused"))
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				found := &dbv1alpha1.PostgresGrant{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+			})
+		})
+	})
+})
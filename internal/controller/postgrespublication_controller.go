@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// allOperations is published when a publication does not restrict its operations
+const allOperations = "insert, update, delete, truncate"
+
+// PostgresPublicationReconciler reconciles a PostgresPublication object
+type PostgresPublicationReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	pg             postgres.PG
+	instanceFilter string
+}
+
+// NewPostgresPublicationReconciler returns a new reconcile.Reconciler
+func NewPostgresPublicationReconciler(mgr manager.Manager, cfg *config.Cfg, pg postgres.PG) *PostgresPublicationReconciler {
+	return &PostgresPublicationReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		pg:             pg,
+		instanceFilter: cfg.AnnotationFilter,
+	}
+}
+
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgrespublications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgrespublications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgrespublications/finalizers,verbs=update
+
+// Reconcile creates or updates the publication in the database of the referenced Postgres
+func (r *PostgresPublicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling PostgresPublication")
+
+	// Fetch the PostgresPublication instance
+	instance := &dbv1alpha1.PostgresPublication{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !utils.MatchesInstanceAnnotation(instance.Annotations, r.instanceFilter) {
+		return ctrl.Result{}, nil
+	}
+
+	// Deletion logic
+	if instance.GetDeletionTimestamp() != nil {
+		if instance.Status.PublicationName != "" {
+			err = r.pg.DropPublication(instance.Status.DatabaseName, instance.Status.PublicationName)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(instance, "finalizer.db.movetokube.com")
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.AddFinalizer(instance, "finalizer.db.movetokube.com") {
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	database, err := getReadyPostgres(ctx, r.Client, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.Postgres}, r.instanceFilter)
+	if err != nil {
+		return r.requeue(ctx, instance, errors.NewInternalError(err))
+	}
+
+	name := instance.Spec.Name
+	if name == "" {
+		name = instance.Name
+	}
+	// Drop the publication when it was renamed or moved to another database
+	if instance.Status.PublicationName != "" &&
+		(instance.Status.PublicationName != name || instance.Status.DatabaseName != database.Spec.Database) {
+		err = r.pg.DropPublication(instance.Status.DatabaseName, instance.Status.PublicationName)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		instance.Status.PublicationName = ""
+	}
+
+	publish := allOperations
+	if len(instance.Spec.Operations) > 0 {
+		operations := make([]string, len(instance.Spec.Operations))
+		for i, operation := range instance.Spec.Operations {
+			operations[i] = string(operation)
+		}
+		publish = strings.Join(operations, ", ")
+	}
+	err = r.pg.CreateOrUpdatePublication(database.Spec.Database, postgres.PostgresPublication{
+		Name:      name,
+		AllTables: instance.Spec.AllTables,
+		Tables:    instance.Spec.Tables,
+		Schemas:   instance.Spec.Schemas,
+		Publish:   publish,
+	})
+	if err != nil {
+		return r.requeue(ctx, instance, errors.NewInternalError(err))
+	}
+	instance.Status.PublicationName = name
+	instance.Status.DatabaseName = database.Spec.Database
+
+	reqLogger.Info("Reconciling done")
+	return r.finish(ctx, instance)
+}
+
+func (r *PostgresPublicationReconciler) requeue(ctx context.Context, cr *dbv1alpha1.PostgresPublication, reason error) (ctrl.Result, error) {
+	cr.Status.Succeeded = false
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, reason
+}
+
+func (r *PostgresPublicationReconciler) finish(ctx context.Context, cr *dbv1alpha1.PostgresPublication) (ctrl.Result, error) {
+	cr.Status.Succeeded = true
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PostgresPublicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dbv1alpha1.PostgresPublication{}).
+		Complete(r)
+}
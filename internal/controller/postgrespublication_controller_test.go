@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	mockpg "github.com/movetokube/postgres-operator/pkg/postgres/mock"
+)
+
+var _ = Describe("PostgresPublication Controller", func() {
+	const (
+		name      = "test-publication"
+		namespace = "operator"
+	)
+
+	var (
+		req         reconcile.Request
+		mockCtrl    *gomock.Controller
+		pg          *mockpg.MockPG
+		rp          *PostgresPublicationReconciler
+		cl          client.Client
+		database    *dbv1alpha1.Postgres
+		publication *dbv1alpha1.PostgresPublication
+	)
+
+	runReconcile := func(rp *PostgresPublicationReconciler, ctx context.Context, req reconcile.Request) (err error) {
+		_, err = rp.Reconcile(ctx, req)
+		if k8sManager != nil {
+			k8sManager.GetCache().WaitForCacheSync(ctx)
+		}
+		return err
+	}
+
+	clearPublications := func() {
+		l := dbv1alpha1.PostgresPublicationList{}
+		Expect(k8sClient.List(ctx, &l, client.InNamespace(namespace))).To(Succeed())
+		for _, el := range l.Items {
+			org := el.DeepCopy()
+			el.SetFinalizers(nil)
+			Expect(k8sClient.Patch(ctx, &el, client.MergeFrom(org))).To(Succeed())
+		}
+		Expect(k8sClient.DeleteAllOf(ctx, &dbv1alpha1.PostgresPublication{}, client.InNamespace(namespace))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		pg = mockpg.NewMockPG(mockCtrl)
+		cl = k8sClient
+		rp = &PostgresPublicationReconciler{
+			Client: managerClient,
+			Scheme: scheme.Scheme,
+			pg:     pg,
+		}
+		if k8sManager != nil {
+			rp.SetupWithManager(k8sManager)
+		}
+		req = reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+
+		database = &dbv1alpha1.Postgres{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: namespace},
+			Spec:       dbv1alpha1.PostgresSpec{Database: "orders-db"},
+		}
+		publication = &dbv1alpha1.PostgresPublication{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: dbv1alpha1.PostgresPublicationSpec{
+				Postgres: "orders",
+				Tables:   []string{"orders", "billing.invoices"},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		clearPublications()
+		Expect(clearPgs(namespace)).To(Succeed())
+		if k8sManager != nil {
+			k8sManager.GetCache().WaitForCacheSync(ctx)
+		}
+		mockCtrl.Finish()
+	})
+
+	readyDatabase := func() {
+		Expect(cl.Create(ctx, database)).To(Succeed())
+		database.Status.Succeeded = true
+		Expect(cl.Status().Update(ctx, database)).To(Succeed())
+	}
+
+	It("should not requeue if PostgresPublication does not exist", func() {
+		res, err := rp.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Requeue).To(BeFalse())
+	})
+
+	Describe("Checking creation logic", func() {
+		Context("Database is ready", func() {
+			BeforeEach(func() {
+				readyDatabase()
+			})
+
+			It("should create the publication with all operations", func() {
+				Expect(cl.Create(ctx, publication)).To(Succeed())
+				pg.EXPECT().CreateOrUpdatePublication("orders-db", postgres.PostgresPublication{
+					Name:    name,
+					Tables:  []string{"orders", "billing.invoices"},
+					Publish: "insert, update, delete, truncate",
+				}).Return(nil)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				found := &dbv1alpha1.PostgresPublication{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.Succeeded).To(BeTrue())
+				Expect(found.Status.PublicationName).To(Equal(name))
+				Expect(found.Status.DatabaseName).To(Equal("orders-db"))
+				Expect(found.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+			})
+
+			It("should publish only the listed operations", func() {
+				publication.Spec.Operations = []dbv1alpha1.PostgresPublicationOperation{"insert", "update"}
+				Expect(cl.Create(ctx, publication)).To(Succeed())
+				pg.EXPECT().CreateOrUpdatePublication("orders-db", gomock.Any()).DoAndReturn(
+					func(db string, publication postgres.PostgresPublication) error {
+						Expect(publication.Publish).To(Equal("insert, update"))
+						return nil
+					})
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+			})
+
+			It("should drop the old publication when it is renamed", func() {
+				publication.Spec.Name = "orders_v2"
+				Expect(cl.Create(ctx, publication)).To(Succeed())
+				publication.Status = dbv1alpha1.PostgresPublicationStatus{
+					Succeeded:       true,
+					PublicationName: name,
+					DatabaseName:    "orders-db",
+				}
+				Expect(cl.Status().Update(ctx, publication)).To(Succeed())
+
+				gomock.InOrder(
+					pg.EXPECT().DropPublication("orders-db", name).Return(nil),
+					pg.EXPECT().CreateOrUpdatePublication("orders-db", gomock.Any()).Return(nil),
+				)
+
+				Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+				found := &dbv1alpha1.PostgresPublication{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.PublicationName).To(Equal("orders_v2"))
+			})
+
+			It("should mark the status as failed when the publication cannot be created", func() {
+				Expect(cl.Create(ctx, publication)).To(Succeed())
+				pg.EXPECT().CreateOrUpdatePublication("orders-db", gomock.Any()).Return(fmt.Errorf("relation does not exist"))
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+				found := &dbv1alpha1.PostgresPublication{}
+				Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+				Expect(found.Status.Succeeded).To(BeFalse())
+				Expect(found.Status.PublicationName).To(BeEmpty())
+			})
+		})
+
+		Context("Database is not ready", func() {
+			BeforeEach(func() {
+				Expect(cl.Create(ctx, database)).To(Succeed())
+				Expect(cl.Create(ctx, publication)).To(Succeed())
+			})
+
+			It("should not create the publication", func() {
+				pg.EXPECT().CreateOrUpdatePublication(gomock.Any(), gomock.Any()).Times(0)
+
+				Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+			})
+		})
+	})
+
+	Describe("Checking deletion logic", func() {
+		BeforeEach(func() {
+			publication.SetFinalizers([]string{"finalizer.db.movetokube.com"})
+			Expect(cl.Create(ctx, publication)).To(Succeed())
+			publication.Status = dbv1alpha1.PostgresPublicationStatus{
+				Succeeded:       true,
+				PublicationName: name,
+				DatabaseName:    "orders-db",
+			}
+			Expect(cl.Status().Update(ctx, publication)).To(Succeed())
+			Expect(cl.Delete(ctx, publication, &client.DeleteOptions{GracePeriodSeconds: new(int64)})).To(Succeed())
+		})
+
+		It("should drop the publication and remove the finalizer", func() {
+			pg.EXPECT().DropPublication("orders-db", name).Return(nil)
+
+			Expect(runReconcile(rp, ctx, req)).To(Succeed())
+
+			found := &dbv1alpha1.PostgresPublication{}
+			err := cl.Get(ctx, req.NamespacedName, found)
+			if err != nil {
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			} else {
+				Expect(found.GetFinalizers()).To(BeEmpty())
+			}
+		})
+
+		It("should keep the finalizer when dropping fails", func() {
+			pg.EXPECT().DropPublication("orders-db", name).Return(fmt.Errorf("could not drop"))
+
+			Expect(runReconcile(rp, ctx, req)).NotTo(Succeed())
+
+			found := &dbv1alpha1.PostgresPublication{}
+			Expect(cl.Get(ctx, req.NamespacedName, found)).To(Succeed())
+			Expect(found.GetFinalizers()).To(ContainElement("finalizer.db.movetokube.com"))
+		})
+	})
+})
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/postgres"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+// PostgresSubscriptionReconciler reconciles a PostgresSubscription object
+type PostgresSubscriptionReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	pg             postgres.PG
+	instanceFilter string
+}
+
+// NewPostgresSubscriptionReconciler returns a new reconcile.Reconciler
+func NewPostgresSubscriptionReconciler(mgr manager.Manager, cfg *config.Cfg, pg postgres.PG) *PostgresSubscriptionReconciler {
+	return &PostgresSubscriptionReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		pg:             pg,
+		instanceFilter: cfg.AnnotationFilter,
+	}
+}
+
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgressubscriptions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgressubscriptions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=db.movetokube.com,resources=postgressubscriptions/finalizers,verbs=update
+
+// Reconcile creates or updates the subscription in the database of the referenced Postgres
+func (r *PostgresSubscriptionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling PostgresSubscription")
+
+	// Fetch the PostgresSubscription instance
+	instance := &dbv1alpha1.PostgresSubscription{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !utils.MatchesInstanceAnnotation(instance.Annotations, r.instanceFilter) {
+		return ctrl.Result{}, nil
+	}
+
+	// Deletion logic
+	if instance.GetDeletionTimestamp() != nil {
+		if instance.Status.SubscriptionName != "" {
+			// Also drops the replication slot on the publisher, which has to be reachable
+			err = r.pg.DropSubscription(instance.Status.DatabaseName, instance.Status.SubscriptionName)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(instance, "finalizer.db.movetokube.com")
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.AddFinalizer(instance, "finalizer.db.movetokube.com") {
+		err = r.Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	database, err := getReadyPostgres(ctx, r.Client, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.Postgres}, r.instanceFilter)
+	if err != nil {
+		return r.requeue(ctx, instance, errors.NewInternalError(err))
+	}
+	connection, err := r.getConnection(ctx, instance)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+
+	name := instance.Spec.Name
+	if name == "" {
+		name = instance.Name
+	}
+	// Drop the subscription when it was renamed or moved to another database
+	if instance.Status.SubscriptionName != "" &&
+		(instance.Status.SubscriptionName != name || instance.Status.DatabaseName != database.Spec.Database) {
+		err = r.pg.DropSubscription(instance.Status.DatabaseName, instance.Status.SubscriptionName)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		instance.Status.SubscriptionName = ""
+		instance.Status.ConnectionHash = ""
+	}
+
+	sum := sha256.Sum256([]byte(connection))
+	connectionHash := hex.EncodeToString(sum[:])
+	err = r.pg.CreateOrUpdateSubscription(database.Spec.Database, postgres.PostgresSubscription{
+		Name:         name,
+		Connection:   connection,
+		Publications: instance.Spec.Publications,
+		Enabled:      ptr.Deref(instance.Spec.Enabled, true),
+		CopyData:     ptr.Deref(instance.Spec.CopyData, true),
+		SlotName:     instance.Spec.SlotName,
+	}, instance.Status.SubscriptionName != "" && instance.Status.ConnectionHash != connectionHash)
+	if err != nil {
+		return r.requeue(ctx, instance, errors.NewInternalError(err))
+	}
+	instance.Status.SubscriptionName = name
+	instance.Status.DatabaseName = database.Spec.Database
+	instance.Status.ConnectionHash = connectionHash
+
+	reqLogger.Info("Reconciling done")
+	return r.finish(ctx, instance)
+}
+
+// getConnection reads the connection string of the publisher from the referenced secret
+func (r *PostgresSubscriptionReconciler) getConnection(ctx context.Context, instance *dbv1alpha1.PostgresSubscription) (string, error) {
+	ref := instance.Spec.ConnectionSecret
+	key := ref.Key
+	if key == "" {
+		key = "POSTGRES_URL"
+	}
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: ref.Name}, secret)
+	if err != nil {
+		return "", err
+	}
+	connection, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret \"%s\" has no key \"%s\"", ref.Name, key)
+	}
+	return string(connection), nil
+}
+
+func (r *PostgresSubscriptionReconciler) requeue(ctx context.Context, cr *dbv1alpha1.PostgresSubscription, reason error) (ctrl.Result, error) {
+	cr.Status.Succeeded = false
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, reason
+}
+
+func (r *PostgresSubscriptionReconciler) finish(ctx context.Context, cr *dbv1alpha1.PostgresSubscription) (ctrl.Result, error) {
+	cr.Status.Succeeded = true
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// subscriptionsUsingSecret maps a secret to the subscriptions connecting with it
+func (r *PostgresSubscriptionReconciler) subscriptionsUsingSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	subscriptions := dbv1alpha1.PostgresSubscriptionList{}
+	if err := r.List(ctx, &subscriptions, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "could not list PostgresSubscriptions")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, subscription := range subscriptions.Items {
+		if subscription.Spec.ConnectionSecret.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&subscription)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PostgresSubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dbv1alpha1.PostgresSubscription{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.subscriptionsUsingSecret)).
+		Complete(r)
+}
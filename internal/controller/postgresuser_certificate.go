@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+const (
+	// Keys of the client certificate in the credentials, the same as in Kubernetes TLS secrets
+	clientCertKey = "tls.crt"
+	clientKeyKey  = "tls.key"
+	// CA bundle verifying the server, see POSTGRES_SSL_ROOT_CERT
+	caBundleKey = "ca.crt"
+	// CA that signed the client certificate, the server trusts it but clients must not
+	clientCAKey = "client-ca.crt"
+
+	defaultClientCertificateDuration  = 90 * 24 * time.Hour
+	defaultClientCertificateMountPath = "/etc/postgres-credentials"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// clientCertificateParams returns the libpq parameters pointing clients to the certificate of cr where they mount it.
+// sslrootcert is only set with the CA bundle of the server, the CA of the client certificate does not verify the server.
+func clientCertificateParams(cr *dbv1alpha1.PostgresUser, serverCABundle bool) url.Values {
+	mountPath := cr.Spec.ClientCertificate.MountPath
+	if mountPath == "" {
+		mountPath = defaultClientCertificateMountPath
+	}
+	params := url.Values{
+		"sslcert": {path.Join(mountPath, clientCertKey)},
+		"sslkey":  {path.Join(mountPath, clientKeyKey)},
+	}
+	if serverCABundle {
+		params.Set("sslrootcert", path.Join(mountPath, caBundleKey))
+	}
+	return params
+}
+
+// clientCertificate returns the certificate, key and CA of the client certificate of cr for role.
+// A certificate in current issued by the operator CA is kept until it is due for renewal.
+func (r *PostgresUserReconciler) clientCertificate(ctx context.Context, cr *dbv1alpha1.PostgresUser, role, secretName string, current map[string][]byte) (map[string][]byte, error) {
+	spec := cr.Spec.ClientCertificate
+	validity := spec.Duration.Duration
+	if validity <= 0 {
+		validity = defaultClientCertificateDuration
+	}
+	if spec.IssuerRef != nil {
+		return r.certManagerCertificate(ctx, cr, role, secretName, validity)
+	}
+
+	ca, err := r.clientCA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if current != nil && len(current[clientKeyKey]) > 0 && ca.ClientCertificateValid(current[clientCertKey], role, now, validity/3) {
+		return map[string][]byte{
+			clientCertKey: current[clientCertKey],
+			clientKeyKey:  current[clientKeyKey],
+			clientCAKey:   ca.CertPEM,
+		}, nil
+	}
+	certPEM, keyPEM, err := ca.IssueClientCertificate(role, now, validity)
+	if err != nil {
+		return nil, fmt.Errorf("issue client certificate: %w", err)
+	}
+	return map[string][]byte{
+		clientCertKey: certPEM,
+		clientKeyKey:  keyPEM,
+		clientCAKey:   ca.CertPEM,
+	}, nil
+}
+
+// clientCA reads the CA configured on the operator
+func (r *PostgresUserReconciler) clientCA(ctx context.Context) (*utils.CertificateAuthority, error) {
+	if r.clientCASecret.Name == "" {
+		return nil, fmt.Errorf("client certificates require CLIENT_CA_SECRET or an issuerRef")
+	}
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, r.clientCASecret, secret)
+	if err != nil {
+		return nil, fmt.Errorf("get client CA: %w", err)
+	}
+	return utils.ParseCertificateAuthority(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+}
+
+// certManagerCertificate requests the client certificate of cr from cert-manager and returns it once issued
+func (r *PostgresUserReconciler) certManagerCertificate(ctx context.Context, cr *dbv1alpha1.PostgresUser, role, secretName string, validity time.Duration) (map[string][]byte, error) {
+	issuer := cr.Spec.ClientCertificate.IssuerRef
+	kind := issuer.Kind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	group := issuer.Group
+	if group == "" {
+		group = certificateGVK.Group
+	}
+	name := secretName + "-client"
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	cert.SetName(name)
+	cert.SetNamespace(cr.Namespace)
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, cert, func() error {
+		err := controllerutil.SetControllerReference(cr, cert, r.Scheme)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedMap(cert.Object, map[string]any{
+			"commonName": role,
+			"secretName": name,
+			"duration":   validity.String(),
+			"usages":     []any{"client auth", "digital signature"},
+			"privateKey": map[string]any{
+				"algorithm":      "ECDSA",
+				"encoding":       "PKCS8",
+				"rotationPolicy": "Always",
+			},
+			"issuerRef": map[string]any{
+				"name":  issuer.Name,
+				"kind":  kind,
+				"group": group,
+			},
+		}, "spec")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("apply Certificate %q: %w", name, err)
+	}
+
+	issued := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: name}, issued)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if errors.IsNotFound(err) || len(issued.Data[corev1.TLSCertKey]) == 0 || len(issued.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, fmt.Errorf("client certificate %q is not issued yet", name)
+	}
+	if commonName, err := utils.CertificateCommonName(issued.Data[corev1.TLSCertKey]); err != nil || commonName != role {
+		return nil, fmt.Errorf("client certificate %q is not issued for role %s yet", name, role)
+	}
+	return map[string][]byte{
+		clientCertKey: issued.Data[corev1.TLSCertKey],
+		clientKeyKey:  issued.Data[corev1.TLSPrivateKeyKey],
+		clientCAKey:   issued.Data[caBundleKey],
+	}, nil
+}
@@ -5,21 +5,32 @@ import (
 	"fmt"
 	"maps"
 	"net"
-
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
 	"github.com/movetokube/postgres-operator/pkg/config"
+	"github.com/movetokube/postgres-operator/pkg/credentials"
 	"github.com/movetokube/postgres-operator/pkg/postgres"
 	"github.com/movetokube/postgres-operator/pkg/utils"
 )
@@ -32,27 +43,65 @@ type PostgresUserReconciler struct {
 	pgHost         string
 	pgUriArgs      string
 	instanceFilter string
+	cluster        string
 	keepSecretName bool // use secret name as defined in PostgresUserSpec
 	cloudProvider  config.CloudProvider
+	// sinks credentials can be stored in besides Secrets, by type
+	sinks                map[string]credentials.CredentialSink
+	credentialPathPrefix string
+	// namespaces secrets may be copied to
+	secretNamespaceSelector labels.Selector
+	// TLS secret of the CA signing client certificates
+	clientCASecret types.NamespacedName
+	// CA bundle of the server, added to the credentials
+	serverCABundle string
 }
 
 // NewPostgresUserReconciler returns a new reconcile.Reconciler
 func NewPostgresUserReconciler(mgr manager.Manager, cfg *config.Cfg, pg postgres.PG) *PostgresUserReconciler {
+	conn := postgres.ClientConnectionArgs(cfg)
 	return &PostgresUserReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		pg:             pg,
-		pgHost:         cfg.PostgresHost,
-		pgUriArgs:      cfg.PostgresUriArgs,
-		instanceFilter: cfg.AnnotationFilter,
-		keepSecretName: cfg.KeepSecretName,
-		cloudProvider:  cfg.CloudProvider,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		pg:                      pg,
+		pgHost:                  cfg.PostgresHost,
+		pgUriArgs:               conn.UriArgs,
+		instanceFilter:          cfg.AnnotationFilter,
+		cluster:                 cfg.ClusterName,
+		keepSecretName:          cfg.KeepSecretName,
+		cloudProvider:           cfg.CloudProvider,
+		sinks:                   newCredentialSinks(cfg),
+		credentialPathPrefix:    cfg.CredentialPathPrefix,
+		secretNamespaceSelector: cfg.SecretNamespaceSelector,
+		clientCASecret:          cfg.ClientCASecret,
+		serverCABundle:          conn.SSLRootCert,
+	}
+}
+
+// newCredentialSinks returns the sinks configured for the operator
+func newCredentialSinks(cfg *config.Cfg) map[string]credentials.CredentialSink {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	sinks := map[string]credentials.CredentialSink{}
+	if cfg.Vault.Address != "" {
+		sinks[credentials.SinkVault] = credentials.NewVaultSink(cfg.Vault, httpClient)
 	}
+	if cfg.AWS.Region != "" {
+		sink, err := credentials.NewAWSSecretsManagerSink(context.Background(), cfg.AWS, awsconfig.WithHTTPClient(httpClient))
+		if err != nil {
+			// PostgresUsers using the sink fail as it is not configured
+			ctrl.Log.Error(err, "AWS Secrets Manager sink is disabled")
+		} else {
+			sinks[credentials.SinkAWSSecretsManager] = sink
+		}
+	}
+	return sinks
 }
 
 // +kubebuilder:rbac:groups=db.movetokube.com,resources=postgresusers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=db.movetokube.com,resources=postgresusers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=db.movetokube.com,resources=postgresusers/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -99,7 +148,32 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			if postgres != nil && postgres.GetDeletionTimestamp().IsZero() {
 				db = instance.Status.DatabaseName
 			}
-			err = r.pg.DropRole(instance.Status.PostgresRole, instance.Status.PostgresGroup, db)
+			tag := objectTag(r.instanceFilter, r.cluster, "PostgresUser", instance, instance.Status.DatabaseName)
+			owner, err := foreignOwner(r.pg, "ROLE", instance.Status.PostgresRole, tag)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if owner != nil {
+				reqLogger.Info(fmt.Sprintf("Not dropping role %s, it belongs to %s", instance.Status.PostgresRole, owner))
+			} else {
+				err = r.pg.DropRole(instance.Status.PostgresRole, instance.Status.PostgresGroup, db)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		}
+		// Copies in other namespaces have no owner reference
+		err = r.deleteSecretCopies(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		// Secrets are garbage collected along with the user
+		if stored := instance.Status.CredentialSink; stored != nil && stored.Type != credentials.SinkSecret {
+			sink, err := r.credentialSink(instance, nil, stored.Type)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			err = sink.Delete(ctx, stored.Location)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
@@ -117,6 +191,7 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	// Creation logic
 	var (
 		role, login string
+		roleCreated bool
 	)
 	password, err := utils.GetSecureRandomString(15)
 	if err != nil {
@@ -129,25 +204,19 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		if err != nil {
 			return r.requeue(ctx, instance, errors.NewInternalError(err))
 		}
+		groupRole, err := userGroupRole(database, instance)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
 		// Create user role
 		suffix := utils.GetRandomString(6)
 		role = fmt.Sprintf("%s-%s", instance.Spec.Role, suffix)
-		login, err = r.pg.CreateUserRole(role, password)
+		login, err = r.pg.CreateUserRole(role, password, objectTag(r.instanceFilter, r.cluster, "PostgresUser", instance, database.Spec.Database))
 		if err != nil {
 			return r.requeue(ctx, instance, errors.NewInternalError(err))
 		}
 
 		// Grant group role to user role
-		var groupRole string
-		switch instance.Spec.Privileges {
-		case "READ":
-			groupRole = database.Status.Roles.Reader
-		case "WRITE":
-			groupRole = database.Status.Roles.Writer
-		default:
-			groupRole = database.Status.Roles.Owner
-		}
-
 		err = r.pg.GrantRole(groupRole, role)
 		if err != nil {
 			return r.requeue(ctx, instance, errors.NewInternalError(err))
@@ -160,10 +229,11 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return r.requeue(ctx, instance, errors.NewInternalError(err))
 		}
 
+		roleCreated = true
 		instance.Status.PostgresRole = role
 		instance.Status.PostgresGroup = groupRole
 		instance.Status.PostgresLogin = login
-		instance.Status.DatabaseName = database.Spec.Database
+		instance.Status.DatabaseName = statusDatabase(database)
 		err = r.Status().Update(ctx, instance)
 		if err != nil {
 			return r.requeue(ctx, instance, err)
@@ -203,6 +273,19 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		reqLogger.WithValues("role", role).Info("IAM Auth requested while we are not running with AWS cloud provider config")
 	}
 
+	// Replication lets the role connect as the publisher side of a subscription
+	if instance.Spec.Replication != instance.Status.Replication {
+		err = r.pg.SetReplication(role, instance.Spec.Replication)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
+		}
+		instance.Status.Replication = instance.Spec.Replication
+		err = r.Status().Update(ctx, instance)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+	}
+
 	// Reconcile logic for changes in group membership
 	// This is only applicable if user role is already created
 	// and privileges are changed in spec
@@ -215,22 +298,21 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 
 		// Determine desired group role
-		var desiredGroup string
-		switch instance.Spec.Privileges {
-		case "READ":
-			desiredGroup = database.Status.Roles.Reader
-		case "WRITE":
-			desiredGroup = database.Status.Roles.Writer
-		default:
-			desiredGroup = database.Status.Roles.Owner
+		desiredGroup, err := userGroupRole(database, instance)
+		if err != nil {
+			return r.requeue(ctx, instance, errors.NewInternalError(err))
 		}
 
+		// The group roles were renamed along with the database and kept their members,
+		// but the default role of the user refers to the group by name
+		databaseRenamed := instance.Status.DatabaseName != statusDatabase(database)
+
 		// Ability user to be reassigned to another group role
 		currentGroup := instance.Status.PostgresGroup
-		if desiredGroup != "" && currentGroup != desiredGroup {
+		if desiredGroup != "" && (currentGroup != desiredGroup || databaseRenamed) {
 
 			// Remove the old group membership if present
-			if currentGroup != "" {
+			if currentGroup != "" && !databaseRenamed {
 				if err := r.pg.RevokeRole(currentGroup, role); err != nil {
 					return r.requeue(ctx, instance, errors.NewInternalError(err))
 				}
@@ -247,6 +329,7 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			}
 
 			instance.Status.PostgresGroup = desiredGroup
+			instance.Status.DatabaseName = statusDatabase(database)
 			if err := r.Status().Update(ctx, instance); err != nil {
 				return r.requeue(ctx, instance, err)
 			}
@@ -261,42 +344,95 @@ func (r *PostgresUserReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	if err != nil {
 		return r.requeue(ctx, instance, err)
 	}
-
-	secret, err := r.newSecretForCR(reqLogger, instance, role, password, login)
+	database, err := r.getPostgresCR(ctx, instance)
 	if err != nil {
 		return r.requeue(ctx, instance, err)
 	}
 
-	// Set PostgresUser instance as the owner and controller
-	if err := controllerutil.SetControllerReference(instance, secret, r.Scheme); err != nil {
+	secret, err := r.newSecretForCR(reqLogger, instance, database, role, password, login)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	location, err := r.credentialLocation(instance, secret)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	sink, err := r.credentialSink(instance, secret, location.Type)
+	if err != nil {
 		return r.requeue(ctx, instance, err)
 	}
 
-	// Check if this Secret already exists
-	found := &corev1.Secret{}
-	err = r.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
+	// Check if the credentials are already stored, possibly in the sink used before
+	current, err := sink.Read(ctx, location.Location)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	stored := current
+	if stored == nil && !roleCreated {
+		stored, err = r.previousCredentials(ctx, instance, secret, location)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+	}
+	if stored != nil && !roleCreated {
+		// The password only lives in the sink, it is kept while the rest is brought up to date
+		password, err = r.storedPassword(ctx, instance, stored)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+	}
+	if password == "" {
+		// Nothing to keep from the previous sink
+		password, err = utils.GetSecureRandomString(15)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
+		}
+		stored = nil
+	}
+	// Keep the password readable before the role gets it
+	if err := r.keepPassword(ctx, instance, password); err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	if stored == nil && instance.Status.Succeeded {
 		// if role is already created, update password
-		if instance.Status.Succeeded {
-			err := r.pg.UpdatePassword(role, password)
-			if err != nil {
-				return r.requeue(ctx, instance, err)
-			}
+		err = r.pg.UpdatePassword(role, password)
+		if err != nil {
+			return r.requeue(ctx, instance, err)
 		}
-		reqLogger.Info("Creating secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
-		err = r.Create(ctx, secret)
+	}
+	secret, err = r.newSecretForCR(reqLogger, instance, database, role, password, login)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	if instance.Spec.ClientCertificate != nil {
+		certificate, err := r.clientCertificate(ctx, instance, role, secret.Name, current)
 		if err != nil {
-			return ctrl.Result{}, err
+			return r.requeue(ctx, instance, err)
+		}
+		maps.Copy(secret.Data, certificate)
+	}
+	if r.serverCABundle != "" {
+		// Clients verify the server with it
+		bundle, err := os.ReadFile(r.serverCABundle)
+		if err != nil {
+			return r.requeue(ctx, instance, fmt.Errorf("read CA bundle of the server: %w", err))
 		}
+		secret.Data[caBundleKey] = bundle
+	}
 
-		// Secret created successfully - don't requeue
-		return r.finish(ctx, instance)
-	} else if err != nil {
+	reqLogger.Info("Storing credentials", "Sink", location.Type, "Location", location.Location)
+	err = sink.Write(ctx, location.Location, current, secret.Data)
+	if err != nil {
 		return r.requeue(ctx, instance, err)
 	}
-
-	reqLogger.Info("Reconciling done")
-	return ctrl.Result{}, nil
+	err = r.syncSecretCopies(ctx, instance, secret, secret.Data, location)
+	if err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	if err := r.moveCredentials(ctx, instance, secret, location); err != nil {
+		return r.requeue(ctx, instance, err)
+	}
+	return r.finish(ctx, instance)
 }
 
 func (r *PostgresUserReconciler) getPostgresCR(ctx context.Context, instance *dbv1alpha1.PostgresUser) (*dbv1alpha1.Postgres, error) {
@@ -317,7 +453,31 @@ func (r *PostgresUserReconciler) getPostgresCR(ctx context.Context, instance *db
 	return &database, nil
 }
 
-func (r *PostgresUserReconciler) newSecretForCR(reqLogger logr.Logger, cr *dbv1alpha1.PostgresUser, role, password, login string) (*corev1.Secret, error) {
+// userGroupRole resolves the group role of database granted to the PostgresUser, which is a role
+// of the schema of the user if it has one
+func userGroupRole(database *dbv1alpha1.Postgres, instance *dbv1alpha1.PostgresUser) (string, error) {
+	roles := database.Status.Roles
+	if instance.Spec.Schema != "" {
+		idx := slices.IndexFunc(database.Status.SchemaRoles, func(s dbv1alpha1.PostgresSchemaRoles) bool {
+			return s.Schema == instance.Spec.Schema
+		})
+		if idx < 0 {
+			return "", fmt.Errorf("schema \"%s\" of database \"%s\" has no roles of its own", instance.Spec.Schema, database.Name)
+		}
+		roles = database.Status.SchemaRoles[idx].Roles
+	}
+	switch instance.Spec.Privileges {
+	case "READ":
+		return roles.Reader, nil
+	case "WRITE":
+		return roles.Writer, nil
+	default:
+		return roles.Owner, nil
+	}
+}
+
+// newSecretForCR builds the secret of cr, database may be nil when its Postgres is not known
+func (r *PostgresUserReconciler) newSecretForCR(reqLogger logr.Logger, cr *dbv1alpha1.PostgresUser, database *dbv1alpha1.Postgres, role, password, login string) (*corev1.Secret, error) {
 	hostname, port, err := net.SplitHostPort(r.pgHost)
 	if err != nil {
 		hostname = r.pgHost
@@ -325,9 +485,15 @@ func (r *PostgresUserReconciler) newSecretForCR(reqLogger logr.Logger, cr *dbv1a
 		reqLogger.Info(fmt.Sprintf("failed to parse host and port from: '%s', using default port 5432", r.pgHost))
 	}
 
-	pgUserUrl := fmt.Sprintf("postgresql://%s:%s@%s/%s", role, password, r.pgHost, cr.Status.DatabaseName)
-	pgJDBCUrl := fmt.Sprintf("jdbc:postgresql://%s/%s", r.pgHost, cr.Status.DatabaseName)
-	pgDotnetUrl := fmt.Sprintf("User ID=%s;Password=%s;Host=%s;Port=%s;Database=%s;", role, password, hostname, port, cr.Status.DatabaseName)
+	// Connection strings point clients to the client certificate, URI_ARGS stays as configured
+	uriArgs := r.pgUriArgs
+	if cr.Spec.ClientCertificate != nil {
+		uriArgs = joinUriArgs(uriArgs, clientCertificateParams(cr, r.serverCABundle != "").Encode())
+	}
+	// Clients log in with the login, which differs from the role on Azure
+	pgUserUrl := postgresURL(login, password, r.pgHost, cr.Status.DatabaseName, uriArgs)
+	pgJDBCUrl := jdbcURL(r.pgHost, cr.Status.DatabaseName, r.pgUriArgs)
+	pgDotnetUrl := dotnetConnectionString(login, password, hostname, port, cr.Status.DatabaseName)
 	labels := map[string]string{
 		"app": cr.Name,
 	}
@@ -340,14 +506,24 @@ func (r *PostgresUserReconciler) newSecretForCR(reqLogger logr.Logger, cr *dbv1a
 		name = cr.Spec.SecretName
 	}
 
+	var schemas []string
+	if database != nil {
+		schemas = database.Status.Schemas
+	}
 	templateData, err := utils.RenderTemplate(cr.Spec.SecretTemplate, utils.TemplateContext{
-		Role:     role,
-		Host:     r.pgHost,
-		UriArgs:  r.pgUriArgs,
-		Database: cr.Status.DatabaseName,
-		Password: password,
-		Hostname: hostname,
-		Port:     port,
+		Role:      role,
+		Host:      r.pgHost,
+		UriArgs:   r.pgUriArgs,
+		Database:  cr.Status.DatabaseName,
+		Password:  password,
+		Hostname:  hostname,
+		Port:      port,
+		Login:     login,
+		Group:     cr.Status.PostgresGroup,
+		Namespace: cr.Namespace,
+		Name:      cr.Name,
+		Schema:    cr.Spec.Schema,
+		Schemas:   schemas,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("render templated keys: %w", err)
@@ -366,6 +542,28 @@ func (r *PostgresUserReconciler) newSecretForCR(reqLogger logr.Logger, cr *dbv1a
 		"PORT":                []byte(port),
 		"HOSTNAME":            []byte(hostname),
 	}
+	if len(cr.Spec.ConnectionFormats) > 0 {
+		params, err := url.ParseQuery(uriArgs)
+		if err != nil {
+			return nil, fmt.Errorf("parse uri args: %w", err)
+		}
+		conn := utils.Connection{
+			Hostname: hostname,
+			Port:     port,
+			Database: cr.Status.DatabaseName,
+			User:     login,
+			Password: password,
+			Schema:   cr.Spec.Schema,
+			Params:   params,
+		}
+		for _, format := range cr.Spec.ConnectionFormats {
+			value, err := conn.Format(format)
+			if err != nil {
+				return nil, err
+			}
+			data[utils.ConnectionFormatKeys[format]] = []byte(value)
+		}
+	}
 	// templates may override standard keys
 	if len(templateData) > 0 {
 		maps.Copy(data, templateData)
@@ -382,6 +580,57 @@ func (r *PostgresUserReconciler) newSecretForCR(reqLogger logr.Logger, cr *dbv1a
 	}, nil
 }
 
+// postgresURL returns the connection URL of a role, escaping the credentials and the database
+func postgresURL(role, password, host, database, uriArgs string) string {
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(role, password),
+		Host:     host,
+		Path:     "/" + database,
+		RawQuery: uriArgs,
+	}
+	return u.String()
+}
+
+// dotnetConnectionString returns an ADO.NET connection string, values are quoted like
+// DbConnectionStringBuilder does
+func dotnetConnectionString(login, password, hostname, port, database string) string {
+	var b strings.Builder
+	for _, pair := range [][2]string{{"User ID", login}, {"Password", password}, {"Host", hostname}, {"Port", port}, {"Database", database}} {
+		fmt.Fprintf(&b, "%s=%s;", pair[0], dotnetConnectionValue(pair[1]))
+	}
+	return b.String()
+}
+
+func dotnetConnectionValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, `;='"`) && strings.TrimSpace(value) == value {
+		return value
+	}
+	if strings.Contains(value, `"`) {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return `"` + value + `"`
+}
+
+// joinUriArgs appends the query args to uriArgs
+func joinUriArgs(uriArgs, args string) string {
+	if uriArgs == "" {
+		return args
+	}
+	return uriArgs + "&" + args
+}
+
+// jdbcURL returns the JDBC URL of a database, credentials are passed to the driver separately
+func jdbcURL(host, database, uriArgs string) string {
+	u := url.URL{
+		Scheme:   "postgresql",
+		Host:     host,
+		Path:     "/" + database,
+		RawQuery: uriArgs,
+	}
+	return "jdbc:" + u.String()
+}
+
 func (r *PostgresUserReconciler) addFinalizer(ctx context.Context, reqLogger logr.Logger, m *dbv1alpha1.PostgresUser) error {
 	if len(m.GetFinalizers()) < 1 && m.GetDeletionTimestamp() == nil {
 		reqLogger.Info("adding Finalizer for Postgres")
@@ -435,5 +684,24 @@ func (r *PostgresUserReconciler) finish(ctx context.Context, cr *dbv1alpha1.Post
 func (r *PostgresUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dbv1alpha1.PostgresUser{}).
+		Owns(&corev1.Secret{}).
+		Watches(&dbv1alpha1.Postgres{}, handler.EnqueueRequestsFromMapFunc(r.usersOfDatabase)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.usersCopyingTo)).
 		Complete(r)
 }
+
+// usersOfDatabase maps a Postgres to the PostgresUsers of its database, e.g. to follow a rename
+func (r *PostgresUserReconciler) usersOfDatabase(ctx context.Context, obj client.Object) []reconcile.Request {
+	users := dbv1alpha1.PostgresUserList{}
+	if err := r.List(ctx, &users, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "could not list PostgresUsers")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, user := range users.Items {
+		if user.Spec.Database == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&user)})
+		}
+	}
+	return requests
+}
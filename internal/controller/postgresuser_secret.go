@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/credentials"
+)
+
+// fieldOwner is the field manager of objects applied by the operator
+const fieldOwner = client.FieldOwner("ext-postgres-operator")
+
+// passwordKey is the key of the password in the credentials and in the password secret
+const passwordKey = "PASSWORD"
+
+// passwordTemplated tells whether the secret template of cr replaces the password in the credentials
+func passwordTemplated(cr *dbv1alpha1.PostgresUser) bool {
+	_, templated := cr.Spec.SecretTemplate[passwordKey]
+	return templated
+}
+
+// passwordSecretName is the Secret the operator keeps the password of cr in when its template replaces PASSWORD
+func passwordSecretName(cr *dbv1alpha1.PostgresUser) string {
+	return cr.Name + "-postgres-password"
+}
+
+// storedPassword returns the password of the stored credentials of cr, empty if there is none
+func (r *PostgresUserReconciler) storedPassword(ctx context.Context, cr *dbv1alpha1.PostgresUser, data map[string][]byte) (string, error) {
+	if !passwordTemplated(cr) {
+		return string(data[passwordKey]), nil
+	}
+	found, err := r.passwordSecret(ctx, cr)
+	if err != nil || found == nil {
+		return "", err
+	}
+	return string(found.Data[passwordKey]), nil
+}
+
+// keepPassword stores password in a Secret of the operator while the template of cr replaces PASSWORD,
+// so it can be read back on later reconciles. The Secret is removed once it is not needed any longer.
+func (r *PostgresUserReconciler) keepPassword(ctx context.Context, cr *dbv1alpha1.PostgresUser, password string) error {
+	found, err := r.passwordSecret(ctx, cr)
+	if err != nil {
+		return err
+	}
+	if !passwordTemplated(cr) {
+		if found == nil {
+			return nil
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, found))
+	}
+	if found != nil && string(found.Data[passwordKey]) == password {
+		return nil
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      passwordSecretName(cr),
+			Namespace: cr.Namespace,
+			Labels:    map[string]string{"app": cr.Name},
+		},
+		Data: map[string][]byte{passwordKey: []byte(password)},
+	}
+	if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
+		return err
+	}
+	return r.applySecret(ctx, secret)
+}
+
+// passwordSecret returns the password Secret of cr, nil if there is none
+func (r *PostgresUserReconciler) passwordSecret(ctx context.Context, cr *dbv1alpha1.PostgresUser) (*corev1.Secret, error) {
+	found := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: passwordSecretName(cr)}, found)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !metav1.IsControlledBy(found, cr) {
+		return nil, fmt.Errorf("secret %s exists already and is not controlled by the PostgresUser", found.Name)
+	}
+	return found, nil
+}
+
+// secretDrifted tells whether found differs from the data of desired or lacks its labels and annotations.
+// Labels and annotations added by others are left alone.
+func secretDrifted(found, desired *corev1.Secret) bool {
+	if !maps.EqualFunc(found.Data, desired.Data, bytes.Equal) {
+		return true
+	}
+	for key, value := range desired.Labels {
+		if found.Labels[key] != value {
+			return true
+		}
+	}
+	for key, value := range desired.Annotations {
+		if found.Annotations[key] != value {
+			return true
+		}
+	}
+	return false
+}
+
+// applySecret server-side applies secret. Keys, labels and annotations applied before but no longer
+// part of secret are removed.
+func (r *PostgresUserReconciler) applySecret(ctx context.Context, secret *corev1.Secret) error {
+	ac := corev1ac.Secret(secret.Name, secret.Namespace).
+		WithLabels(secret.Labels).
+		WithAnnotations(secret.Annotations).
+		WithData(secret.Data)
+	for _, ref := range secret.OwnerReferences {
+		ownerRef := metav1ac.OwnerReference().
+			WithAPIVersion(ref.APIVersion).
+			WithKind(ref.Kind).
+			WithName(ref.Name).
+			WithUID(ref.UID)
+		if ref.Controller != nil {
+			ownerRef.WithController(*ref.Controller)
+		}
+		if ref.BlockOwnerDeletion != nil {
+			ownerRef.WithBlockOwnerDeletion(*ref.BlockOwnerDeletion)
+		}
+		ac.WithOwnerReferences(ownerRef)
+	}
+	return r.Apply(ctx, ac, fieldOwner, client.ForceOwnership)
+}
+
+// credentialLocation returns the sink of cr and the location of its credentials, secret being the
+// Secret the credentials would be stored in
+func (r *PostgresUserReconciler) credentialLocation(cr *dbv1alpha1.PostgresUser, secret *corev1.Secret) (dbv1alpha1.PostgresCredentialSinkStatus, error) {
+	spec := cr.Spec.CredentialSink
+	if spec == nil || spec.Type == "" || spec.Type == credentials.SinkSecret {
+		return dbv1alpha1.PostgresCredentialSinkStatus{Type: credentials.SinkSecret, Location: secret.Namespace + "/" + secret.Name}, nil
+	}
+	name := spec.Path
+	if name == "" {
+		name = secret.Name
+	}
+	location, err := credentials.Location(r.credentialPathPrefix, cr.Namespace, name)
+	if err != nil {
+		return dbv1alpha1.PostgresCredentialSinkStatus{}, err
+	}
+	return dbv1alpha1.PostgresCredentialSinkStatus{Type: spec.Type, Location: location}, nil
+}
+
+// credentialSink returns the sink of sinkType. Secrets are created with the metadata of secret.
+func (r *PostgresUserReconciler) credentialSink(cr *dbv1alpha1.PostgresUser, secret *corev1.Secret, sinkType string) (credentials.CredentialSink, error) {
+	if sinkType == credentials.SinkSecret {
+		return &secretSink{r: r, owner: cr, desired: secret}, nil
+	}
+	sink, ok := r.sinks[sinkType]
+	if !ok {
+		return nil, fmt.Errorf("credential sink %s is not configured", sinkType)
+	}
+	return sink, nil
+}
+
+// previousLocation returns where the credentials of cr were stored by the last reconcile
+func previousLocation(cr *dbv1alpha1.PostgresUser, secret *corev1.Secret) dbv1alpha1.PostgresCredentialSinkStatus {
+	if cr.Status.CredentialSink == nil {
+		// Users created before sinks were selectable stored their credentials in the secret
+		return dbv1alpha1.PostgresCredentialSinkStatus{Type: credentials.SinkSecret, Location: secret.Namespace + "/" + secret.Name}
+	}
+	return *cr.Status.CredentialSink
+}
+
+// previousCredentials returns the credentials of cr stored in another sink or location than current, if any
+func (r *PostgresUserReconciler) previousCredentials(ctx context.Context, cr *dbv1alpha1.PostgresUser, secret *corev1.Secret, current dbv1alpha1.PostgresCredentialSinkStatus) (map[string][]byte, error) {
+	previous := previousLocation(cr, secret)
+	if previous == current {
+		return nil, nil
+	}
+	sink, err := r.credentialSink(cr, secret, previous.Type)
+	if err != nil {
+		return nil, err
+	}
+	return sink.Read(ctx, previous.Location)
+}
+
+// moveCredentials records where the credentials of cr are stored after removing them from the previous sink
+func (r *PostgresUserReconciler) moveCredentials(ctx context.Context, cr *dbv1alpha1.PostgresUser, secret *corev1.Secret, current dbv1alpha1.PostgresCredentialSinkStatus) error {
+	previous := previousLocation(cr, secret)
+	if previous != current {
+		sink, err := r.credentialSink(cr, secret, previous.Type)
+		if err != nil {
+			return err
+		}
+		if err := sink.Delete(ctx, previous.Location); err != nil {
+			return fmt.Errorf("remove credentials from %s %s: %w", previous.Type, previous.Location, err)
+		}
+	}
+	cr.Status.CredentialSink = &current
+	return nil
+}
+
+// secretSink stores credentials in Secrets controlled by a PostgresUser. Locations are namespace/name.
+type secretSink struct {
+	r     *PostgresUserReconciler
+	owner *dbv1alpha1.PostgresUser
+	// labels and annotations of written secrets
+	desired *corev1.Secret
+	found   *corev1.Secret
+}
+
+func (s *secretSink) Read(ctx context.Context, location string) (map[string][]byte, error) {
+	found, err := s.get(ctx, location)
+	if err != nil || found == nil {
+		return nil, err
+	}
+	s.found = found
+	if found.Data == nil {
+		return map[string][]byte{}, nil
+	}
+	return found.Data, nil
+}
+
+func (s *secretSink) Write(ctx context.Context, location string, _, data map[string][]byte) error {
+	secret := s.desired.DeepCopy()
+	secret.Namespace, secret.Name, _ = strings.Cut(location, "/")
+	secret.Data = data
+	if s.found != nil && s.found.Name == secret.Name && !secretDrifted(s.found, secret) {
+		return nil
+	}
+	// Set PostgresUser instance as the owner and controller
+	if err := controllerutil.SetControllerReference(s.owner, secret, s.r.Scheme); err != nil {
+		return err
+	}
+	return s.r.applySecret(ctx, secret)
+}
+
+func (s *secretSink) Delete(ctx context.Context, location string) error {
+	found, err := s.get(ctx, location)
+	// Secrets created by others are left alone
+	if err != nil || found == nil || !metav1.IsControlledBy(found, s.owner) {
+		return err
+	}
+	return client.IgnoreNotFound(s.r.Delete(ctx, found))
+}
+
+func (s *secretSink) get(ctx context.Context, location string) (*corev1.Secret, error) {
+	namespace, name, _ := strings.Cut(location, "/")
+	found := &corev1.Secret{}
+	err := s.r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, found)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dbv1alpha1 "github.com/movetokube/postgres-operator/api/v1alpha1"
+	"github.com/movetokube/postgres-operator/pkg/credentials"
+)
+
+const (
+	// secretCopyLabel holds the UID of the PostgresUser a secret in another namespace is a copy for.
+	// Owner references cannot cross namespaces, copies are garbage collected by this label instead.
+	secretCopyLabel = "postgres.db.movetokube.com/copy-of"
+	// secretSourceAnnotation names the PostgresUser of a copied secret as namespace/name
+	secretSourceAnnotation = "postgres.db.movetokube.com/source"
+)
+
+// syncSecretCopies copies secret with data to the secretNamespaces of cr allowed by the namespace selector.
+// Copies in namespaces no longer listed or allowed are deleted. Secrets that are no copy of cr are never
+// overwritten, their namespaces are reported as denied.
+func (r *PostgresUserReconciler) syncSecretCopies(ctx context.Context, cr *dbv1alpha1.PostgresUser, secret *corev1.Secret, data map[string][]byte, location dbv1alpha1.PostgresCredentialSinkStatus) error {
+	var targets []string
+	if location.Type == credentials.SinkSecret {
+		for _, namespace := range cr.Spec.SecretNamespaces {
+			if namespace != cr.Namespace && !slices.Contains(targets, namespace) {
+				targets = append(targets, namespace)
+			}
+		}
+	}
+	var denied []string
+	for _, namespace := range targets {
+		allowed, err := r.secretNamespaceAllowed(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			denied = append(denied, namespace)
+		}
+	}
+	targets = slices.DeleteFunc(targets, func(namespace string) bool {
+		return slices.Contains(denied, namespace)
+	})
+
+	copies, err := r.secretCopies(ctx, cr)
+	if err != nil {
+		return err
+	}
+	found := map[string]*corev1.Secret{}
+	for i := range copies {
+		existing := &copies[i]
+		if slices.Contains(targets, existing.Namespace) && existing.Name == secret.Name {
+			found[existing.Namespace] = existing
+			continue
+		}
+		err = r.Delete(ctx, existing)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	var occupied []string
+	for _, namespace := range targets {
+		desired := secret.DeepCopy()
+		desired.Namespace = namespace
+		desired.Data = data
+		desired.OwnerReferences = nil
+		if desired.Labels == nil {
+			desired.Labels = map[string]string{}
+		}
+		desired.Labels[secretCopyLabel] = string(cr.UID)
+		if desired.Annotations == nil {
+			desired.Annotations = map[string]string{}
+		}
+		desired.Annotations[secretSourceAnnotation] = cr.Namespace + "/" + cr.Name
+		existing := found[namespace]
+		if existing == nil {
+			existing = &corev1.Secret{}
+			err = r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: desired.Name}, existing)
+			if err == nil && existing.Labels[secretCopyLabel] != string(cr.UID) {
+				occupied = append(occupied, namespace)
+				continue
+			}
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		if !secretDrifted(existing, desired) {
+			continue
+		}
+		err = r.applySecret(ctx, desired)
+		if err != nil {
+			return err
+		}
+	}
+
+	var reasons []string
+	if len(denied) > 0 {
+		reasons = append(reasons, fmt.Sprintf("namespaces %s do not match the namespace selector", strings.Join(denied, ", ")))
+	}
+	if len(occupied) > 0 {
+		reasons = append(reasons, fmt.Sprintf("namespaces %s have a secret %q already", strings.Join(occupied, ", "), secret.Name))
+	}
+	if len(reasons) > 0 {
+		return fmt.Errorf("secret cannot be copied, %s", strings.Join(reasons, "; "))
+	}
+	return nil
+}
+
+// deleteSecretCopies removes the copies of the secret of cr from other namespaces
+func (r *PostgresUserReconciler) deleteSecretCopies(ctx context.Context, cr *dbv1alpha1.PostgresUser) error {
+	copies, err := r.secretCopies(ctx, cr)
+	if err != nil {
+		return err
+	}
+	for i := range copies {
+		err = r.Delete(ctx, &copies[i])
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresUserReconciler) secretCopies(ctx context.Context, cr *dbv1alpha1.PostgresUser) ([]corev1.Secret, error) {
+	if cr.UID == "" {
+		return nil, nil
+	}
+	copies := &corev1.SecretList{}
+	err := r.List(ctx, copies, client.MatchingLabels{secretCopyLabel: string(cr.UID)})
+	if err != nil {
+		return nil, err
+	}
+	return copies.Items, nil
+}
+
+// secretNamespaceAllowed checks the labels of namespace against the namespace selector
+func (r *PostgresUserReconciler) secretNamespaceAllowed(ctx context.Context, namespace string) (bool, error) {
+	// Without a selector nothing is allowed
+	if r.secretNamespaceSelector == nil {
+		return false, nil
+	}
+	ns := &corev1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return r.secretNamespaceSelector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// usersCopyingTo maps a Namespace to the PostgresUsers copying their secret there, e.g. to
+// remove copies when the namespace no longer matches the selector
+func (r *PostgresUserReconciler) usersCopyingTo(ctx context.Context, obj client.Object) []reconcile.Request {
+	users := dbv1alpha1.PostgresUserList{}
+	if err := r.List(ctx, &users); err != nil {
+		log.FromContext(ctx).Error(err, "could not list PostgresUsers")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, user := range users.Items {
+		if slices.Contains(user.Spec.SecretNamespaces, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&user)})
+		}
+	}
+	return requests
+}
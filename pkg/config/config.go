@@ -1,11 +1,17 @@
 package config
 
 import (
+	"log"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/movetokube/postgres-operator/pkg/credentials"
 	"github.com/movetokube/postgres-operator/pkg/utils"
 )
 
@@ -15,9 +21,43 @@ type Cfg struct {
 	PostgresPass      string
 	PostgresUriArgs   string
 	PostgresDefaultDb string
-	CloudProvider     CloudProvider
-	AnnotationFilter  string
-	KeepSecretName    bool
+	// sslmode of the operator's connection, overrides sslmode in PostgresUriArgs
+	PostgresSSLMode string
+	// Paths of the CA bundle of the server and of the client certificate and key of the operator
+	PostgresSSLRootCert string
+	PostgresSSLCert     string
+	PostgresSSLKey      string
+	// Connections open at once to each database other than the default database
+	PostgresMaxConnsPerDatabase int
+	// Databases other than the default database with open connections at once
+	PostgresMaxDatabasePools int
+	// Connections to databases other than the default database are closed after being idle this long
+	PostgresConnIdleTimeout time.Duration
+	CloudProvider           CloudProvider
+	AnnotationFilter        string
+	KeepSecretName          bool
+	// Reconcile every Postgres this often to detect drift, disabled if zero
+	ResyncPeriod time.Duration
+	// Undo drift detected during a resync
+	DriftRemediation bool
+	// Look for roles and databases whose resource is gone this often, disabled if zero
+	OrphanScanInterval time.Duration
+	// What to do with orphaned roles and databases
+	OrphanPolicy OrphanPolicy
+	// Name of the Kubernetes cluster recorded in the comments of created objects
+	ClusterName string
+	// Vault server credentials of PostgresUsers can be stored in, disabled without address
+	Vault credentials.VaultConfig
+	// AWS Secrets Manager credentials of PostgresUsers can be stored in, disabled without region
+	AWS credentials.AWSConfig
+	// Prefix of the credential locations in Vault and AWS Secrets Manager
+	CredentialPathPrefix string
+	// Namespaces PostgresUser secrets may be copied to, none by default
+	SecretNamespaceSelector labels.Selector
+	// Kubernetes TLS secret of the CA signing client certificates of PostgresUsers
+	ClientCASecret types.NamespacedName
+	// Databases without Postgres resource that may be cloned, none by default
+	CloneSourceDatabases []string
 }
 
 var (
@@ -42,15 +82,96 @@ func Get() *Cfg {
 		config.PostgresPass = url.PathEscape(utils.MustGetEnv("POSTGRES_PASS"))
 		config.PostgresUriArgs = utils.MustGetEnv("POSTGRES_URI_ARGS")
 		config.PostgresDefaultDb = utils.GetEnv("POSTGRES_DEFAULT_DATABASE")
+		config.PostgresSSLMode = utils.GetEnv("POSTGRES_SSL_MODE")
+		config.PostgresSSLRootCert = utils.GetEnv("POSTGRES_SSL_ROOT_CERT")
+		config.PostgresSSLCert = utils.GetEnv("POSTGRES_SSL_CERT")
+		config.PostgresSSLKey = utils.GetEnv("POSTGRES_SSL_KEY")
+		if (config.PostgresSSLCert == "") != (config.PostgresSSLKey == "") {
+			log.Fatalf("environment variables POSTGRES_SSL_CERT and POSTGRES_SSL_KEY must be set together")
+		}
+		config.PostgresMaxConnsPerDatabase = 2
+		if value, err := strconv.Atoi(utils.GetEnv("POSTGRES_MAX_CONNS_PER_DATABASE")); err == nil && value > 0 {
+			config.PostgresMaxConnsPerDatabase = value
+		}
+		config.PostgresMaxDatabasePools = 10
+		if value, err := strconv.Atoi(utils.GetEnv("POSTGRES_MAX_DATABASE_POOLS")); err == nil && value > 0 {
+			config.PostgresMaxDatabasePools = value
+		}
+		config.PostgresConnIdleTimeout = 5 * time.Minute
+		if value, err := time.ParseDuration(utils.GetEnv("POSTGRES_CONN_IDLE_TIMEOUT")); err == nil && value > 0 {
+			config.PostgresConnIdleTimeout = value
+		}
 		config.CloudProvider = ParseCloudProvider(utils.GetEnv("POSTGRES_CLOUD_PROVIDER"))
 		config.AnnotationFilter = utils.GetEnv("POSTGRES_INSTANCE")
 		if value, err := strconv.ParseBool(utils.GetEnv("KEEP_SECRET_NAME")); err == nil {
 			config.KeepSecretName = value
 		}
+		if value, err := time.ParseDuration(utils.GetEnv("RESYNC_PERIOD")); err == nil {
+			config.ResyncPeriod = value
+		}
+		if value, err := strconv.ParseBool(utils.GetEnv("DRIFT_REMEDIATION")); err == nil {
+			config.DriftRemediation = value
+		}
+		if value, err := time.ParseDuration(utils.GetEnv("ORPHAN_SCAN_INTERVAL")); err == nil {
+			config.OrphanScanInterval = value
+		}
+		config.OrphanPolicy = ParseOrphanPolicy(utils.GetEnv("ORPHAN_POLICY"))
+		config.ClusterName = utils.GetEnv("CLUSTER_NAME")
+		config.Vault = credentials.VaultConfig{
+			Address:   utils.GetEnv("VAULT_ADDR"),
+			Token:     utils.GetEnv("VAULT_TOKEN"),
+			Namespace: utils.GetEnv("VAULT_NAMESPACE"),
+			Mount:     utils.GetEnv("VAULT_KV_MOUNT"),
+			AuthRole:  utils.GetEnv("VAULT_AUTH_ROLE"),
+			AuthMount: utils.GetEnv("VAULT_AUTH_MOUNT"),
+		}
+		config.AWS = credentials.AWSConfig{
+			Region:   utils.GetEnv("AWS_REGION"),
+			Endpoint: utils.GetEnv("AWS_SECRETS_MANAGER_ENDPOINT"),
+		}
+		config.CredentialPathPrefix = utils.GetEnv("CREDENTIAL_PATH_PREFIX")
+		if config.CredentialPathPrefix == "" {
+			config.CredentialPathPrefix = "postgres-operator"
+		}
+		config.SecretNamespaceSelector = labels.Nothing()
+		if value := utils.GetEnv("SECRET_NAMESPACE_SELECTOR"); value != "" {
+			selector, err := labels.Parse(value)
+			if err != nil {
+				log.Fatalf("environment variable SECRET_NAMESPACE_SELECTOR is invalid: %s", err)
+			}
+			config.SecretNamespaceSelector = selector
+		}
+		if value := utils.GetEnv("CLIENT_CA_SECRET"); value != "" {
+			namespace, name, found := strings.Cut(value, "/")
+			if !found || namespace == "" || name == "" {
+				log.Fatalf("environment variable CLIENT_CA_SECRET must be namespace/name: %s", value)
+			}
+			config.ClientCASecret = types.NamespacedName{Namespace: namespace, Name: name}
+		}
+		for _, database := range strings.Split(utils.GetEnv("CLONE_SOURCE_DATABASES"), ",") {
+			if database = strings.TrimSpace(database); database != "" {
+				config.CloneSourceDatabases = append(config.CloneSourceDatabases, database)
+			}
+		}
 	})
 	return config
 }
 
+// OrphanPolicy decides what happens to roles and databases whose resource was deleted without cleaning up
+type OrphanPolicy string
+
+const (
+	OrphanPolicyReport OrphanPolicy = "Report"
+	OrphanPolicyDrop   OrphanPolicy = "Drop"
+)
+
+func ParseOrphanPolicy(s string) OrphanPolicy {
+	if strings.EqualFold(s, string(OrphanPolicyDrop)) {
+		return OrphanPolicyDrop
+	}
+	return OrphanPolicyReport
+}
+
 // CloudProvider is an enum for supported cloud providers.
 
 func ParseCloudProvider(s string) CloudProvider {
@@ -0,0 +1,96 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// AWSConfig configures the AWS Secrets Manager sink. Credentials are taken from the default
+// credential chain of the AWS SDK, e.g. environment variables, IAM roles for service accounts
+// or the instance profile.
+type AWSConfig struct {
+	// Region of Secrets Manager, the sink is disabled if empty
+	Region string
+	// Endpoint of a Secrets Manager compatible API, defaults to the AWS endpoint of the region
+	Endpoint string
+}
+
+type awsSink struct {
+	client *secretsmanager.Client
+}
+
+// NewAWSSecretsManagerSink returns a CredentialSink storing credentials as JSON in AWS Secrets Manager
+// or a compatible API. Locations are secret names. optFns are applied after the default configuration.
+func NewAWSSecretsManagerSink(ctx context.Context, cfg AWSConfig, optFns ...func(*awsconfig.LoadOptions) error) (CredentialSink, error) {
+	optFns = append([]func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}, optFns...)
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS configuration: %w", err)
+	}
+	client := secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	})
+	return &awsSink{client: client}, nil
+}
+
+func (a *awsSink) Read(ctx context.Context, location string) (map[string][]byte, error) {
+	out, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(location)})
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(aws.ToString(out.SecretString)), &values); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object: %w", location, err)
+	}
+	return toBytes(values), nil
+}
+
+func (a *awsSink) Write(ctx context.Context, location string, current, data map[string][]byte) error {
+	// Every write adds a version
+	if equal(current, data) {
+		return nil
+	}
+	value, err := json.Marshal(toStrings(data))
+	if err != nil {
+		return err
+	}
+	_, err = a.client.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(location),
+		SecretString: aws.String(string(value)),
+	})
+	if isNotFound(err) {
+		_, err = a.client.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String(location),
+			SecretString: aws.String(string(value)),
+		})
+	}
+	return err
+}
+
+func (a *awsSink) Delete(ctx context.Context, location string) error {
+	_, err := a.client.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+		SecretId:                   aws.String(location),
+		ForceDeleteWithoutRecovery: aws.Bool(true),
+	})
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	var notFound *types.ResourceNotFoundException
+	return errors.As(err, &notFound)
+}
@@ -0,0 +1,201 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeSecretsManager implements the parts of the Secrets Manager API used by the sink
+type fakeSecretsManager struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	calls   []string
+}
+
+func (f *fakeSecretsManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager.")
+	f.calls = append(f.calls, action)
+	var in map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&in)
+	notFound := func() {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+	}
+	switch action {
+	case "GetSecretValue":
+		value, ok := f.secrets[in["SecretId"].(string)]
+		if !ok {
+			notFound()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": value})
+	case "PutSecretValue":
+		if _, ok := f.secrets[in["SecretId"].(string)]; !ok {
+			notFound()
+			return
+		}
+		f.secrets[in["SecretId"].(string)] = in["SecretString"].(string)
+		_, _ = w.Write([]byte("{}"))
+	case "CreateSecret":
+		f.secrets[in["Name"].(string)] = in["SecretString"].(string)
+		_, _ = w.Write([]byte("{}"))
+	case "DeleteSecret":
+		if _, ok := f.secrets[in["SecretId"].(string)]; !ok {
+			notFound()
+			return
+		}
+		delete(f.secrets, in["SecretId"].(string))
+		_, _ = w.Write([]byte("{}"))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+var _ = Describe("AWS Secrets Manager sink", func() {
+	var (
+		ctx     context.Context
+		manager *fakeSecretsManager
+		server  *httptest.Server
+		sink    CredentialSink
+		data    map[string][]byte
+	)
+
+	// isolate keeps the AWS configuration of the environment out of the tests
+	isolate := func(env map[string]string) {
+		dir := GinkgoT().TempDir()
+		env["AWS_CONFIG_FILE"] = filepath.Join(dir, "config")
+		env["AWS_SHARED_CREDENTIALS_FILE"] = filepath.Join(dir, "credentials")
+		env["AWS_EC2_METADATA_DISABLED"] = "true"
+		for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE",
+			"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME", "AWS_ENDPOINT_URL_STS"} {
+			if _, ok := env[key]; !ok {
+				env[key] = ""
+			}
+		}
+		for key, value := range env {
+			previous, found := os.LookupEnv(key)
+			Expect(os.Setenv(key, value)).To(Succeed())
+			DeferCleanup(func() {
+				if found {
+					os.Setenv(key, previous)
+				} else {
+					os.Unsetenv(key)
+				}
+			})
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		manager = &fakeSecretsManager{secrets: map[string]string{}}
+		server = httptest.NewServer(manager)
+		DeferCleanup(server.Close)
+		data = map[string][]byte{"LOGIN": []byte("app-user"), "PASSWORD": []byte("secret")}
+	})
+
+	Context("with static credentials", func() {
+		BeforeEach(func() {
+			isolate(map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "secret"})
+			var err error
+			sink, err = NewAWSSecretsManagerSink(ctx, AWSConfig{Region: "eu-central-1", Endpoint: server.URL})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("creates, updates, reads and deletes secrets", func() {
+			current, err := sink.Read(ctx, "postgres-operator/app/user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current).To(BeNil())
+
+			Expect(sink.Write(ctx, "postgres-operator/app/user", current, data)).To(Succeed())
+			Expect(manager.calls).To(Equal([]string{"GetSecretValue", "PutSecretValue", "CreateSecret"}))
+			Expect(manager.secrets["postgres-operator/app/user"]).To(MatchJSON(`{"LOGIN":"app-user","PASSWORD":"secret"}`))
+
+			current, err = sink.Read(ctx, "postgres-operator/app/user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current).To(Equal(data))
+			Expect(sink.Write(ctx, "postgres-operator/app/user", current, data)).To(Succeed())
+			Expect(manager.calls).To(HaveLen(4))
+
+			data["PASSWORD"] = []byte("rotated")
+			Expect(sink.Write(ctx, "postgres-operator/app/user", current, data)).To(Succeed())
+			Expect(manager.secrets["postgres-operator/app/user"]).To(MatchJSON(`{"LOGIN":"app-user","PASSWORD":"rotated"}`))
+
+			Expect(sink.Delete(ctx, "postgres-operator/app/user")).To(Succeed())
+			Expect(manager.secrets).To(BeEmpty())
+			Expect(sink.Delete(ctx, "postgres-operator/app/user")).To(Succeed())
+		})
+
+		It("reports errors", func() {
+			manager.secrets["app/user"] = "not json"
+			_, err := sink.Read(ctx, "app/user")
+			Expect(err).To(MatchError(ContainSubstring("not a JSON object")))
+		})
+	})
+
+	Context("with a web identity", func() {
+		var (
+			tokenFile string
+			exchanges int
+		)
+
+		BeforeEach(func() {
+			tokenFile = filepath.Join(GinkgoT().TempDir(), "token")
+			Expect(os.WriteFile(tokenFile, []byte("jwt"), 0o600)).To(Succeed())
+			exchanges = 0
+			expiry := time.Now().Add(time.Hour)
+			sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.ParseForm()).To(Succeed())
+				w.Header().Set("Content-Type", "text/xml")
+				if r.PostForm.Get("Action") != "AssumeRoleWithWebIdentity" || r.PostForm.Get("WebIdentityToken") != "jwt" ||
+					r.PostForm.Get("RoleArn") != "arn:aws:iam::123456789012:role/postgres-operator" {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>denied</Message></Error></ErrorResponse>`))
+					return
+				}
+				exchanges++
+				_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>` +
+					`<AccessKeyId>AKID</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>session</SessionToken>` +
+					`<Expiration>` + expiry.UTC().Format(time.RFC3339) + `</Expiration>` +
+					`</Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`))
+			}))
+			DeferCleanup(sts.Close)
+			isolate(map[string]string{
+				"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile,
+				"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/postgres-operator",
+				"AWS_ENDPOINT_URL_STS":        sts.URL,
+			})
+			var err error
+			sink, err = NewAWSSecretsManagerSink(ctx, AWSConfig{Region: "eu-central-1", Endpoint: server.URL})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("signs requests with temporary credentials of the role", func() {
+			Expect(sink.Write(ctx, "app/user", nil, data)).To(Succeed())
+			_, err := sink.Read(ctx, "app/user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exchanges).To(Equal(1))
+		})
+
+		It("reports failed exchanges", func() {
+			Expect(os.WriteFile(tokenFile, []byte("expired"), 0o600)).To(Succeed())
+			_, err := sink.Read(ctx, "app/user")
+			Expect(err).To(MatchError(ContainSubstring("AccessDenied")))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Sink types a PostgresUser can store its credentials in
+const (
+	SinkSecret            = "Secret"
+	SinkVault             = "Vault"
+	SinkAWSSecretsManager = "AWSSecretsManager"
+)
+
+// CredentialSink stores the credentials of a PostgresUser, e.g. the connection details and password
+// of its role. Locations are opaque to the operator, their format depends on the sink.
+type CredentialSink interface {
+	// Read returns the credentials stored at location, nil if there are none
+	Read(ctx context.Context, location string) (map[string][]byte, error)
+	// Write stores data at location unless current, as returned by Read, is up to date
+	Write(ctx context.Context, location string, current, data map[string][]byte) error
+	// Delete removes the credentials at location, missing credentials are not an error
+	Delete(ctx context.Context, location string) error
+}
+
+// Location returns the location of the credentials called name of a namespace below prefix.
+// Every namespace gets a location of its own so PostgresUsers cannot overwrite credentials of other namespaces.
+func Location(prefix, namespace, name string) (string, error) {
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." || segment == "." {
+			return "", fmt.Errorf("invalid credential path %s", name)
+		}
+	}
+	location := path.Join(prefix, namespace, name)
+	if !strings.HasPrefix(location, path.Join(prefix, namespace)+"/") {
+		return "", fmt.Errorf("invalid credential path %s", name)
+	}
+	return location, nil
+}
+
+func toStrings(data map[string][]byte) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		values[key] = string(value)
+	}
+	return values
+}
+
+func toBytes(values map[string]string) map[string][]byte {
+	data := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data[key] = []byte(value)
+	}
+	return data
+}
+
+func equal(current, data map[string][]byte) bool {
+	if current == nil || len(current) != len(data) {
+		return false
+	}
+	for key, value := range data {
+		if string(current[key]) != string(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+package credentials
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Location", func() {
+	It("nests names below the prefix and namespace", func() {
+		location, err := Location("postgres-operator", "app", "db/user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(location).To(Equal("postgres-operator/app/db/user"))
+	})
+
+	It("rejects paths leaving the namespace", func() {
+		for _, name := range []string{"../other/user", "db/../../other", "./user", ""} {
+			_, err := Location("postgres-operator", "app", name)
+			Expect(err).To(HaveOccurred(), name)
+		}
+	})
+})
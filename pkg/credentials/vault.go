@@ -0,0 +1,179 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// serviceAccountTokenFile is presented to Vault when logging in with the Kubernetes auth method
+const serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultConfig configures the Vault KV version 2 sink
+type VaultConfig struct {
+	// Address of the Vault server, the sink is disabled if empty
+	Address string
+	// Token to authenticate with, if empty the operator logs in with the Kubernetes auth method
+	Token string
+	// Enterprise namespace, optional
+	Namespace string
+	// Mount path of the KV version 2 secrets engine
+	Mount string
+	// Role and mount path of the Kubernetes auth method
+	AuthRole  string
+	AuthMount string
+}
+
+type vaultSink struct {
+	cfg       VaultConfig
+	client    *http.Client
+	tokenFile string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewVaultSink returns a CredentialSink writing to the KV version 2 secrets engine of a Vault server.
+// Locations are paths below the mount of the engine.
+func NewVaultSink(cfg VaultConfig, client *http.Client) CredentialSink {
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.AuthMount == "" {
+		cfg.AuthMount = "kubernetes"
+	}
+	return &vaultSink{cfg: cfg, client: client, tokenFile: serviceAccountTokenFile, token: cfg.Token}
+}
+
+func (v *vaultSink) Read(ctx context.Context, location string) (map[string][]byte, error) {
+	var secret struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	found, err := v.do(ctx, http.MethodGet, v.path("data", location), nil, &secret)
+	if err != nil || !found || secret.Data.Data == nil {
+		return nil, err
+	}
+	return toBytes(secret.Data.Data), nil
+}
+
+func (v *vaultSink) Write(ctx context.Context, location string, current, data map[string][]byte) error {
+	// Every write adds a version
+	if equal(current, data) {
+		return nil
+	}
+	body := map[string]any{"data": toStrings(data)}
+	_, err := v.do(ctx, http.MethodPost, v.path("data", location), body, nil)
+	return err
+}
+
+func (v *vaultSink) Delete(ctx context.Context, location string) error {
+	// Deleting the metadata removes all versions
+	_, err := v.do(ctx, http.MethodDelete, v.path("metadata", location), nil, nil)
+	return err
+}
+
+func (v *vaultSink) path(kind, location string) string {
+	return fmt.Sprintf("/v1/%s/%s/%s", strings.Trim(v.cfg.Mount, "/"), kind, location)
+}
+
+// do sends a request to Vault and decodes the response into out, it returns false if nothing was found
+func (v *vaultSink) do(ctx context.Context, method, path string, body, out any) (bool, error) {
+	token, err := v.getToken(ctx, false)
+	if err != nil {
+		return false, err
+	}
+	status, err := v.send(ctx, method, path, token, body, out)
+	// The token may have expired
+	if status == http.StatusForbidden && v.cfg.Token == "" {
+		token, err = v.getToken(ctx, true)
+		if err != nil {
+			return false, err
+		}
+		status, err = v.send(ctx, method, path, token, body, out)
+	}
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (v *vaultSink) send(ctx context.Context, method, path, token string, body, out any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(v.cfg.Address, "/")+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("vault %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("vault %s %s: %w", method, path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// getToken returns the token to authenticate with, logging in with the Kubernetes auth method if needed
+func (v *vaultSink) getToken(ctx context.Context, renew bool) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token != "" && !renew {
+		return v.token, nil
+	}
+	if v.cfg.AuthRole == "" {
+		return "", fmt.Errorf("vault token or auth role required")
+	}
+	jwt, err := os.ReadFile(v.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read service account token: %w", err)
+	}
+	var login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role": v.cfg.AuthRole, "jwt": strings.TrimSpace(string(jwt))}
+	_, err = v.send(ctx, http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", strings.Trim(v.cfg.AuthMount, "/")), "", body, &login)
+	if err != nil {
+		return "", err
+	}
+	if login.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login with role %s returned no token", v.cfg.AuthRole)
+	}
+	v.token = login.Auth.ClientToken
+	return v.token, nil
+}
@@ -0,0 +1,140 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeVault implements the parts of the KV version 2 and Kubernetes auth APIs used by the sink
+type fakeVault struct {
+	mu       sync.Mutex
+	token    string
+	secrets  map[string]map[string]string
+	versions map[string]int
+	logins   int
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path == "/v1/auth/kubernetes/login" {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role"] != "operator" || body["jwt"] != "sa-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		f.logins++
+		f.token = "login-token"
+		_ = json.NewEncoder(w).Encode(map[string]any{"auth": map[string]string{"client_token": f.token}})
+		return
+	}
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		location := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := f.secrets[location]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+		case http.MethodPost:
+			var body struct {
+				Data map[string]string `json:"data"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			f.secrets[location] = body.Data
+			f.versions[location]++
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]int{"version": f.versions[location]}})
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/") && r.Method == http.MethodDelete:
+		delete(f.secrets, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+var _ = Describe("Vault sink", func() {
+	var (
+		ctx    context.Context
+		vault  *fakeVault
+		server *httptest.Server
+		sink   CredentialSink
+		data   map[string][]byte
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		vault = &fakeVault{token: "root", secrets: map[string]map[string]string{}, versions: map[string]int{}}
+		server = httptest.NewServer(vault)
+		DeferCleanup(server.Close)
+		sink = NewVaultSink(VaultConfig{Address: server.URL, Token: "root"}, server.Client())
+		data = map[string][]byte{"LOGIN": []byte("app-user"), "PASSWORD": []byte("secret")}
+	})
+
+	It("writes, reads and deletes credentials", func() {
+		current, err := sink.Read(ctx, "postgres-operator/app/user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(BeNil())
+
+		Expect(sink.Write(ctx, "postgres-operator/app/user", current, data)).To(Succeed())
+		Expect(vault.secrets).To(HaveKeyWithValue("postgres-operator/app/user", map[string]string{"LOGIN": "app-user", "PASSWORD": "secret"}))
+
+		current, err = sink.Read(ctx, "postgres-operator/app/user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(Equal(data))
+
+		Expect(sink.Delete(ctx, "postgres-operator/app/user")).To(Succeed())
+		Expect(vault.secrets).To(BeEmpty())
+		Expect(sink.Delete(ctx, "postgres-operator/app/user")).To(Succeed())
+	})
+
+	It("does not add versions for unchanged credentials", func() {
+		Expect(sink.Write(ctx, "app/user", nil, data)).To(Succeed())
+		current, err := sink.Read(ctx, "app/user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sink.Write(ctx, "app/user", current, data)).To(Succeed())
+		Expect(vault.versions["app/user"]).To(Equal(1))
+
+		data["PASSWORD"] = []byte("rotated")
+		Expect(sink.Write(ctx, "app/user", current, data)).To(Succeed())
+		Expect(vault.versions["app/user"]).To(Equal(2))
+	})
+
+	It("logs in with the Kubernetes auth method and again once the token expired", func() {
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("sa-token\n"), 0o600)).To(Succeed())
+		vault.token = ""
+		sink = NewVaultSink(VaultConfig{Address: server.URL, AuthRole: "operator"}, server.Client())
+		sink.(*vaultSink).tokenFile = tokenFile
+
+		Expect(sink.Write(ctx, "app/user", nil, data)).To(Succeed())
+		Expect(vault.logins).To(Equal(1))
+
+		vault.token = "rotated"
+		_, err := sink.Read(ctx, "app/user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vault.logins).To(Equal(2))
+	})
+
+	It("reports errors", func() {
+		sink = NewVaultSink(VaultConfig{Address: server.URL, Token: "wrong"}, server.Client())
+		Expect(sink.Write(ctx, "app/user", nil, data)).To(MatchError(ContainSubstring("403")))
+	})
+})
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lib/pq"
 )
@@ -28,18 +29,28 @@ func (c *awspg) AlterDefaultLoginRole(role, setRole string) error {
 	return c.pg.AlterDefaultLoginRole(role, setRole)
 }
 
-func (c *awspg) CreateDB(dbname, role string) error {
+func (c *awspg) CreateDB(dbname, role string, tag ObjectTag) error {
 	// Have to add the master role to the group role before we can transfer the database owner
 	err := c.GrantRole(role, c.user)
 	if err != nil {
 		return err
 	}
 
-	return c.pg.CreateDB(dbname, role)
+	return c.pg.CreateDB(dbname, role, tag)
 }
 
-func (c *awspg) CreateUserRole(role, password string) (string, error) {
-	returnedRole, err := c.pg.CreateUserRole(role, password)
+func (c *awspg) CreateDBFromTemplate(dbname, role, template string, terminateConnections bool, tag ObjectTag) error {
+	// Have to add the master role to the group role before we can transfer the database owner
+	err := c.GrantRole(role, c.user)
+	if err != nil {
+		return err
+	}
+
+	return c.pg.CreateDBFromTemplate(dbname, role, template, terminateConnections, tag)
+}
+
+func (c *awspg) CreateUserRole(role, password string, tag ObjectTag) (string, error) {
+	returnedRole, err := c.pg.CreateUserRole(role, password, tag)
 	if err != nil {
 		return "", err
 	}
@@ -53,6 +64,28 @@ func (c *awspg) CreateUserRole(role, password string) (string, error) {
 	return returnedRole, nil
 }
 
+func (c *awspg) CreateExpiringUserRole(role, password string, until time.Time, tag ObjectTag) (string, error) {
+	returnedRole, err := c.pg.CreateExpiringUserRole(role, password, until, tag)
+	if err != nil {
+		return "", err
+	}
+	// See CreateUserRole
+	err = c.GrantRole(role, c.user)
+	if err != nil {
+		return "", err
+	}
+
+	return returnedRole, nil
+}
+
+func (c *awspg) SetReplication(role string, enabled bool) error {
+	// RDS does not allow the REPLICATION attribute, membership in rds_replication grants it instead
+	if enabled {
+		return c.GrantRole("rds_replication", role)
+	}
+	return c.RevokeRole("rds_replication", role)
+}
+
 func (c *awspg) DropRole(role, newOwner, database string) error {
 	// On AWS RDS the postgres user isn't really superuser so he doesn't have permissions
 	// to REASSIGN OWNED BY unless he belongs to both roles
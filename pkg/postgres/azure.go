@@ -16,22 +16,32 @@ func newAzurePG(postgres *pg) PG {
 	}
 }
 
-func (azpg *azurepg) CreateUserRole(role, password string) (string, error) {
-	returnedRole, err := azpg.pg.CreateUserRole(role, password)
+func (azpg *azurepg) CreateUserRole(role, password string, tag ObjectTag) (string, error) {
+	returnedRole, err := azpg.pg.CreateUserRole(role, password, tag)
 	if err != nil {
 		return "", err
 	}
 	return returnedRole, nil
 }
 
-func (azpg *azurepg) CreateDB(dbname, role string) error {
+func (azpg *azurepg) CreateDB(dbname, role string, tag ObjectTag) error {
 	// This step is necessary before we can set the specified role as the database owner
 	err := azpg.GrantRole(role, azpg.user)
 	if err != nil {
 		return err
 	}
 
-	return azpg.pg.CreateDB(dbname, role)
+	return azpg.pg.CreateDB(dbname, role, tag)
+}
+
+func (azpg *azurepg) CreateDBFromTemplate(dbname, role, template string, terminateConnections bool, tag ObjectTag) error {
+	// This step is necessary before we can set the specified role as the database owner
+	err := azpg.GrantRole(role, azpg.user)
+	if err != nil {
+		return err
+	}
+
+	return azpg.pg.CreateDBFromTemplate(dbname, role, template, terminateConnections, tag)
 }
 
 func (azpg *azurepg) DropRole(role, newOwner, database string) error {
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	connectionPoolsOpened = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "postgres_operator_connection_pools_opened_total",
+		Help: "Number of connection pools opened to a database",
+	})
+	connectionPoolsEvicted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "postgres_operator_connection_pools_evicted_total",
+		Help: "Number of connection pools closed, by reason: idle, limit or database (dropped or renamed)",
+	}, []string{"reason"})
+	connectionsDesc = prometheus.NewDesc(
+		"postgres_operator_connections",
+		"Connections of the operator by database and state (in_use or idle)",
+		[]string{"database", "state"}, nil)
+	connectionWaitsDesc = prometheus.NewDesc(
+		"postgres_operator_connection_waits_total",
+		"Number of times the operator waited for a connection of a database because of the open connection limit",
+		[]string{"database"}, nil)
+)
+
+func init() {
+	metrics.Registry.MustRegister(connectionPoolsOpened, connectionPoolsEvicted)
+}
+
+// connectionLimits bound the connections the operator opens to databases other than the default database
+type connectionLimits struct {
+	// Connections open at once per database
+	MaxOpen int
+	// Databases with a connection pool at once, the least recently used pool is closed beyond it
+	MaxDatabases int
+	// Pools unused for this long are closed, as are idle connections within a pool
+	IdleTimeout time.Duration
+}
+
+// connectionCache keeps a connection pool per database, so statements on the same database share
+// connections instead of opening a new one each
+type connectionCache struct {
+	mu     sync.Mutex
+	limits connectionLimits
+	open   func(database string) (*sql.DB, error)
+	pools  map[string]*connectionPool
+	now    func() time.Time
+}
+
+type connectionPool struct {
+	db       *sql.DB
+	lastUsed time.Time
+	// users of the pool, it is closed once evicted and no longer used
+	refs    int
+	evicted bool
+}
+
+func newConnectionCache(limits connectionLimits, open func(database string) (*sql.DB, error)) *connectionCache {
+	return &connectionCache{
+		limits: limits,
+		open:   open,
+		pools:  map[string]*connectionPool{},
+		now:    time.Now,
+	}
+}
+
+// get returns the connection pool of database and a function to call when done with it
+func (c *connectionCache) get(database string) (*sql.DB, func(), error) {
+	c.mu.Lock()
+	c.evictIdle()
+	pool, found := c.pools[database]
+	if found {
+		pool.refs++
+		pool.lastUsed = c.now()
+		c.mu.Unlock()
+		return pool.db, c.release(pool), nil
+	}
+	c.mu.Unlock()
+
+	// Connecting may take a while, other databases are not blocked meanwhile
+	db, err := c.open(database)
+	if err != nil {
+		if db != nil {
+			db.Close()
+		}
+		return nil, nil, err
+	}
+	db.SetMaxOpenConns(c.limits.MaxOpen)
+	db.SetMaxIdleConns(c.limits.MaxOpen)
+	db.SetConnMaxIdleTime(c.limits.IdleTimeout)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, found := c.pools[database]; found {
+		// Opened concurrently, keep the first one
+		db.Close()
+		pool = existing
+		pool.refs++
+		pool.lastUsed = c.now()
+	} else {
+		connectionPoolsOpened.Inc()
+		pool = &connectionPool{db: db, refs: 1, lastUsed: c.now()}
+		c.pools[database] = pool
+		c.evictLeastRecentlyUsed(database)
+	}
+	return pool.db, c.release(pool), nil
+}
+
+func (c *connectionCache) release(pool *connectionPool) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			pool.refs--
+			pool.lastUsed = c.now()
+			if pool.evicted && pool.refs == 0 {
+				pool.db.Close()
+			}
+		})
+	}
+}
+
+// evict closes the pool of database, e.g. before it is dropped or renamed, which PostgreSQL refuses while
+// connections to it are open
+func (c *connectionCache) evict(database string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pool, found := c.pools[database]; found {
+		c.remove(database, pool, "database")
+	}
+}
+
+// evictIdle closes the pools unused for longer than the idle timeout, c.mu must be held
+func (c *connectionCache) evictIdle() {
+	if c.limits.IdleTimeout <= 0 {
+		return
+	}
+	deadline := c.now().Add(-c.limits.IdleTimeout)
+	for database, pool := range c.pools {
+		if pool.refs == 0 && pool.lastUsed.Before(deadline) {
+			c.remove(database, pool, "idle")
+		}
+	}
+}
+
+// evictLeastRecentlyUsed closes pools beyond the database limit except the pool of keep, c.mu must be held
+func (c *connectionCache) evictLeastRecentlyUsed(keep string) {
+	for c.limits.MaxDatabases > 0 && len(c.pools) > c.limits.MaxDatabases {
+		var (
+			oldest     string
+			oldestPool *connectionPool
+		)
+		for database, pool := range c.pools {
+			if database == keep {
+				continue
+			}
+			if oldestPool == nil || pool.lastUsed.Before(oldestPool.lastUsed) {
+				oldest, oldestPool = database, pool
+			}
+		}
+		if oldestPool == nil {
+			return
+		}
+		c.remove(oldest, oldestPool, "limit")
+	}
+}
+
+// remove takes the pool of database out of the cache and closes it unless it is in use, c.mu must be held
+func (c *connectionCache) remove(database string, pool *connectionPool, reason string) {
+	delete(c.pools, database)
+	pool.evicted = true
+	connectionPoolsEvicted.WithLabelValues(reason).Inc()
+	if pool.refs == 0 {
+		pool.db.Close()
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *connectionCache) Describe(ch chan<- *prometheus.Desc) {
+	ch <- connectionsDesc
+	ch <- connectionWaitsDesc
+}
+
+// Collect implements prometheus.Collector with the statistics of the cached pools
+func (c *connectionCache) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for database, pool := range c.pools {
+		stats := pool.db.Stats()
+		ch <- prometheus.MustNewConstMetric(connectionsDesc, prometheus.GaugeValue, float64(stats.InUse), database, "in_use")
+		ch <- prometheus.MustNewConstMetric(connectionsDesc, prometheus.GaugeValue, float64(stats.Idle), database, "idle")
+		ch <- prometheus.MustNewConstMetric(connectionWaitsDesc, prometheus.CounterValue, float64(stats.WaitCount), database)
+	}
+}
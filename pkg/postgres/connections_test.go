@@ -0,0 +1,383 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeConnector opens connections that accept any statement without a server
+type fakeConnector struct {
+	mu    sync.Mutex
+	conns []*fakeConn
+	// exec is called with every statement executed
+	exec func(query string)
+	// result, if set, returns the result of every statement executed
+	result func(query string) (driver.Result, error)
+	// rows, if set, returns the rows of every query
+	rows func(query string, args []driver.NamedValue) ([][]driver.Value, error)
+}
+
+func (f *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	conn := &fakeConn{connector: f}
+	f.conns = append(f.conns, conn)
+	return conn, nil
+}
+
+func (f *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (f *fakeConnector) closed() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	closed := 0
+	for _, conn := range f.conns {
+		if conn.closed {
+			closed++
+		}
+	}
+	return closed
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+	closed    bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Close() error {
+	c.connector.mu.Lock()
+	defer c.connector.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if c.connector.exec != nil {
+		c.connector.exec(query)
+	}
+	if c.connector.result != nil {
+		return c.connector.result(query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.connector.rows == nil {
+		return nil, errors.New("not supported")
+	}
+	values, err := c.connector.rows(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{values: values}, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.values) == 0 {
+		return nil
+	}
+	return make([]string, len(r.values[0]))
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func isClosed(db *sql.DB) bool {
+	err := db.Ping()
+	return err != nil && err.Error() == "sql: database is closed"
+}
+
+var _ = Describe("connectionCache", func() {
+	var (
+		now    time.Time
+		opened map[string][]*sql.DB
+		open   func(database string) (*sql.DB, error)
+		mu     sync.Mutex
+	)
+
+	newCache := func(limits connectionLimits) *connectionCache {
+		cache := newConnectionCache(limits, func(database string) (*sql.DB, error) {
+			return open(database)
+		})
+		cache.now = func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}
+		return cache
+	}
+
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+		opened = map[string][]*sql.DB{}
+		open = func(database string) (*sql.DB, error) {
+			db := sql.OpenDB(&fakeConnector{})
+			mu.Lock()
+			defer mu.Unlock()
+			opened[database] = append(opened[database], db)
+			return db, nil
+		}
+	})
+
+	It("reuses the pool of a database", func() {
+		cache := newCache(connectionLimits{})
+		first, release, err := cache.get("app")
+		Expect(err).NotTo(HaveOccurred())
+		release()
+		second, release, err := cache.get("app")
+		Expect(err).NotTo(HaveOccurred())
+		defer release()
+
+		Expect(second).To(BeIdenticalTo(first))
+		Expect(opened["app"]).To(HaveLen(1))
+		Expect(isClosed(first)).To(BeFalse())
+	})
+
+	It("keeps the first pool of a database opened concurrently", func() {
+		cache := newCache(connectionLimits{})
+		var opening sync.WaitGroup
+		opening.Add(2)
+		proceed := make(chan struct{})
+		connect := open
+		open = func(database string) (*sql.DB, error) {
+			opening.Done()
+			<-proceed
+			return connect(database)
+		}
+
+		dbs := make([]*sql.DB, 2)
+		var done sync.WaitGroup
+		for i := range dbs {
+			done.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer done.Done()
+				db, release, err := cache.get("app")
+				Expect(err).NotTo(HaveOccurred())
+				defer release()
+				dbs[i] = db
+			}()
+		}
+		opening.Wait()
+		close(proceed)
+		done.Wait()
+
+		Expect(dbs[1]).To(BeIdenticalTo(dbs[0]))
+		Expect(opened["app"]).To(HaveLen(2))
+		closed := 0
+		for _, db := range opened["app"] {
+			if isClosed(db) {
+				closed++
+			}
+		}
+		Expect(closed).To(Equal(1))
+		Expect(cache.pools).To(HaveLen(1))
+	})
+
+	It("closes an evicted pool once it is no longer used", func() {
+		cache := newCache(connectionLimits{})
+		db, release, err := cache.get("app")
+		Expect(err).NotTo(HaveOccurred())
+
+		cache.evict("app")
+		Expect(cache.pools).To(BeEmpty())
+		Expect(isClosed(db)).To(BeFalse())
+
+		release()
+		Expect(isClosed(db)).To(BeTrue())
+		// Releasing twice does not count twice
+		release()
+
+		// The database gets a new pool
+		reopened, release, err := cache.get("app")
+		Expect(err).NotTo(HaveOccurred())
+		defer release()
+		Expect(reopened).NotTo(BeIdenticalTo(db))
+	})
+
+	It("evicts the least recently used pool beyond the database limit", func() {
+		cache := newCache(connectionLimits{MaxDatabases: 2})
+		use := func(database string) *sql.DB {
+			db, release, err := cache.get(database)
+			Expect(err).NotTo(HaveOccurred())
+			release()
+			advance(time.Second)
+			return db
+		}
+		first := use("first")
+		second := use("second")
+		use("first")
+		third := use("third")
+
+		Expect(cache.pools).To(HaveLen(2))
+		Expect(cache.pools).To(HaveKey("first"))
+		Expect(cache.pools).To(HaveKey("third"))
+		Expect(isClosed(second)).To(BeTrue())
+		Expect(isClosed(first)).To(BeFalse())
+		Expect(isClosed(third)).To(BeFalse())
+	})
+
+	It("does not close a pool in use beyond the database limit", func() {
+		cache := newCache(connectionLimits{MaxDatabases: 1})
+		busy, release, err := cache.get("busy")
+		Expect(err).NotTo(HaveOccurred())
+		advance(time.Second)
+		_, releaseOther, err := cache.get("other")
+		Expect(err).NotTo(HaveOccurred())
+		defer releaseOther()
+
+		Expect(cache.pools).NotTo(HaveKey("busy"))
+		Expect(isClosed(busy)).To(BeFalse())
+		release()
+		Expect(isClosed(busy)).To(BeTrue())
+	})
+
+	It("evicts pools unused for longer than the idle timeout", func() {
+		cache := newCache(connectionLimits{IdleTimeout: time.Minute})
+		idle, release, err := cache.get("idle")
+		Expect(err).NotTo(HaveOccurred())
+		release()
+		busy, releaseBusy, err := cache.get("busy")
+		Expect(err).NotTo(HaveOccurred())
+		defer releaseBusy()
+
+		advance(30 * time.Second)
+		_, release, err = cache.get("other")
+		Expect(err).NotTo(HaveOccurred())
+		release()
+		Expect(isClosed(idle)).To(BeFalse())
+
+		advance(time.Minute)
+		_, release, err = cache.get("other")
+		Expect(err).NotTo(HaveOccurred())
+		release()
+		Expect(cache.pools).NotTo(HaveKey("idle"))
+		Expect(isClosed(idle)).To(BeTrue())
+		// Pools in use are kept
+		Expect(cache.pools).To(HaveKey("busy"))
+		Expect(isClosed(busy)).To(BeFalse())
+	})
+
+	It("does not cache a pool that failed to open", func() {
+		cache := newCache(connectionLimits{})
+		open = func(string) (*sql.DB, error) {
+			return nil, errors.New("connection refused")
+		}
+		_, _, err := cache.get("app")
+		Expect(err).To(MatchError("connection refused"))
+		Expect(cache.pools).To(BeEmpty())
+	})
+
+	Context("pg", func() {
+		var (
+			c       *pg
+			admin   *fakeConnector
+			pooled  map[string]bool
+			tenants map[string]*fakeConnector
+		)
+
+		BeforeEach(func() {
+			tenants = map[string]*fakeConnector{}
+			pooled = map[string]bool{}
+			cache := newCache(connectionLimits{MaxOpen: 2})
+			open = func(database string) (*sql.DB, error) {
+				tenants[database] = &fakeConnector{}
+				return sql.OpenDB(tenants[database]), nil
+			}
+			admin = &fakeConnector{exec: func(query string) {
+				for database := range tenants {
+					_, found := cache.pools[database]
+					pooled[query+" with pool of "+database] = found
+				}
+			}}
+			c = &pg{db: sql.OpenDB(admin), connections: cache, log: logr.Discard()}
+		})
+
+		It("closes the pool of a database before dropping it", func() {
+			db, release, err := c.connection("app")
+			Expect(err).NotTo(HaveOccurred())
+			release()
+
+			Expect(c.DropDatabase("app")).To(Succeed())
+			Expect(pooled).To(HaveKeyWithValue(`DROP DATABASE "app" with pool of app`, false))
+			Expect(isClosed(db)).To(BeTrue())
+		})
+
+		It("closes the pool of a database before renaming it", func() {
+			db, release, err := c.connection("app")
+			Expect(err).NotTo(HaveOccurred())
+			release()
+
+			Expect(c.RenameDatabase("app", "app-v2", false, ObjectTag{})).To(Succeed())
+			Expect(pooled).To(HaveKeyWithValue(`ALTER DATABASE "app" RENAME TO "app-v2" with pool of app`, false))
+			Expect(isClosed(db)).To(BeTrue())
+		})
+
+		It("closes dedicated connections instead of returning them to the pool", func() {
+			conn, release, err := c.dedicatedConnection("app")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = conn.ExecContext(context.Background(), "SET search_path = tenant")
+			Expect(err).NotTo(HaveOccurred())
+			release()
+
+			db, releasePool, err := c.connection("app")
+			Expect(err).NotTo(HaveOccurred())
+			defer releasePool()
+			Expect(db.Stats().OpenConnections).To(BeZero())
+			Expect(tenants["app"].closed()).To(Equal(1))
+
+			// Pooled connections stay open
+			_, err = db.Exec("SELECT 1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.Stats().Idle).To(Equal(1))
+			Expect(tenants["app"].closed()).To(Equal(1))
+		})
+	})
+})
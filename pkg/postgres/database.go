@@ -1,14 +1,21 @@
 package postgres
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/lib/pq"
 )
 
 const (
 	CREATE_DB               = `CREATE DATABASE "%s"`
+	CREATE_DB_FROM_TEMPLATE = `CREATE DATABASE "%s" TEMPLATE "%s"`
+	RENAME_DB               = `ALTER DATABASE "%s" RENAME TO "%s"`
 	CREATE_SCHEMA           = `CREATE SCHEMA IF NOT EXISTS "%s" AUTHORIZATION "%s"`
+	ALTER_SCHEMA_OWNER      = `ALTER SCHEMA "%s" OWNER TO "%s"`
 	CREATE_EXTENSION        = `CREATE EXTENSION IF NOT EXISTS "%s"`
 	ALTER_DB_OWNER          = `ALTER DATABASE "%s" OWNER TO "%s"`
 	REASSIGN_DB_OWNER       = `REASSIGN OWNED BY "%s" TO "%s"`
@@ -25,18 +32,100 @@ const (
 	TERMINATE_BACKEND       = `SELECT pg_terminate_backend(pg_stat_activity.pid) FROM pg_stat_activity	WHERE pg_stat_activity.datname = '%s' AND pid <> pg_backend_pid()`
 	GET_DB_OWNER            = `SELECT pg_catalog.pg_get_userbyid(d.datdba) FROM pg_catalog.pg_database d WHERE d.datname = '%s'`
 	GRANT_CREATE_SCHEMA     = `GRANT CREATE ON DATABASE "%s" TO "%s"`
+	GRANT_TABLE             = `GRANT %s ON TABLE "%s"."%s" TO "%s"`
+	REVOKE_TABLE            = `REVOKE ALL ON TABLE "%s"."%s" FROM "%s"`
+	LIST_TABLES             = `SELECT c.relname FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'f')`
+	LIST_COLUMNS            = `SELECT attname FROM pg_catalog.pg_attribute WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped ORDER BY attnum`
 )
 
-func (c *pg) CreateDB(dbname, role string) error {
+// ErrDatabaseInUse is returned when a template database or a database to rename has other sessions connected to it
+var ErrDatabaseInUse = errors.New("database is being accessed by other users")
+
+// ErrDatabaseExists is returned when a database to be created as a copy exists already
+var ErrDatabaseExists = errors.New("database already exists")
+
+func (c *pg) CreateDB(dbname, role string, tag ObjectTag) error {
 	_, err := c.db.Exec(fmt.Sprintf(CREATE_DB, dbname))
 	if err != nil {
 		// eat DUPLICATE DATABASE ERROR
 		if err.(*pq.Error).Code != "42P04" {
 			return err
 		}
+		err = claim(c.db, GET_DB_COMMENT, dbname, tag)
+		if err != nil {
+			return err
+		}
 	}
+	err = c.setDatabaseOwner(dbname, role)
+	if err != nil {
+		return err
+	}
+	return c.tag(COMMENT_ON_DATABASE, dbname, tag)
+}
 
-	_, err = c.db.Exec(fmt.Sprintf(ALTER_DB_OWNER, dbname, role))
+// CreateDBFromTemplate creates dbname as a copy of template. PostgreSQL refuses to copy a database
+// other sessions are connected to, in which case ErrDatabaseInUse is returned unless
+// terminateConnections allowed closing those sessions first. An existing dbname is not
+// overwritten and cannot be told apart from a copy, ErrDatabaseExists is returned then.
+func (c *pg) CreateDBFromTemplate(dbname, role, template string, terminateConnections bool, tag ObjectTag) error {
+	c.connections.evict(template)
+	if terminateConnections {
+		_, err := c.db.Exec(fmt.Sprintf(TERMINATE_BACKEND, template))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := c.db.Exec(fmt.Sprintf(CREATE_DB_FROM_TEMPLATE, dbname, template))
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		switch {
+		// Error code 55006 is object_in_use
+		case ok && pqErr.Code == "55006":
+			return fmt.Errorf("%w: %s", ErrDatabaseInUse, template)
+		// Error code 42P04 is duplicate_database
+		case ok && pqErr.Code == "42P04":
+			return fmt.Errorf("%w: %s", ErrDatabaseExists, dbname)
+		default:
+			return err
+		}
+	}
+	err = c.setDatabaseOwner(dbname, role)
+	if err != nil {
+		return err
+	}
+	// Comments are not copied from the template
+	return c.tag(COMMENT_ON_DATABASE, dbname, tag)
+}
+
+// RenameDatabase renames currentName to newName and tags it. PostgreSQL refuses to rename a database other
+// sessions are connected to, in which case ErrDatabaseInUse is returned unless terminateConnections allowed
+// closing those sessions first.
+func (c *pg) RenameDatabase(currentName, newName string, terminateConnections bool, tag ObjectTag) error {
+	c.connections.evict(currentName)
+	if terminateConnections {
+		_, err := c.db.Exec(fmt.Sprintf(TERMINATE_BACKEND, currentName))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := c.db.Exec(fmt.Sprintf(RENAME_DB, currentName, newName))
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		switch {
+		// Error code 55006 is object_in_use
+		case ok && pqErr.Code == "55006":
+			return fmt.Errorf("%w: %s", ErrDatabaseInUse, currentName)
+		// 3D000 => database does not exist, it may have been renamed already
+		case ok && pqErr.Code == "3D000":
+		default:
+			return err
+		}
+	}
+	return c.tag(COMMENT_ON_DATABASE, newName, tag)
+}
+
+func (c *pg) setDatabaseOwner(dbname, role string) error {
+	_, err := c.db.Exec(fmt.Sprintf(ALTER_DB_OWNER, dbname, role))
 	if err != nil {
 		return err
 	}
@@ -62,11 +151,11 @@ func (c *pg) ReassignDatabaseOwner(dbName, currentOwner, newOwner string) error
 		return nil
 	}
 
-	tmpDb, err := GetConnection(c.user, c.pass, c.host, dbName, c.args)
+	tmpDb, release, err := c.connection(dbName)
 	if err != nil {
 		return err
 	}
-	defer tmpDb.Close()
+	defer release()
 
 	_, err = tmpDb.Exec(fmt.Sprintf(REASSIGN_DB_OWNER, currentOwner, newOwner))
 	if err != nil {
@@ -78,21 +167,39 @@ func (c *pg) ReassignDatabaseOwner(dbName, currentOwner, newOwner string) error
 	return nil
 }
 
-func (c *pg) CreateSchema(db, role, schema string) error {
-	tmpDb, err := GetConnection(c.user, c.pass, c.host, db, c.args)
+func (c *pg) CreateSchema(db, role, schema string, tag ObjectTag) error {
+	tmpDb, release, err := c.connection(db)
 	if err != nil {
 		return err
 	}
-	defer tmpDb.Close()
+	defer release()
 
+	// The schema may exist already
+	err = claim(tmpDb, GET_SCHEMA_COMMENT, schema, tag)
+	if err != nil {
+		return err
+	}
 	_, err = tmpDb.Exec(fmt.Sprintf(CREATE_SCHEMA, schema, role))
 	if err != nil {
 		return err
 	}
-	return nil
+	return setTag(tmpDb, COMMENT_ON_SCHEMA, schema, tag)
+}
+
+// AlterSchemaOwner hands schema over to owner, objects in the schema keep their owner
+func (c *pg) AlterSchemaOwner(db, schema, owner string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(ALTER_SCHEMA_OWNER, schema, owner))
+	return err
 }
 
 func (c *pg) DropDatabase(database string) error {
+	c.connections.evict(database)
 	_, err := c.db.Exec(fmt.Sprintf(REVOKE_CONNECT, database))
 	// Error code 3D000 is returned if database doesn't exist
 	if err != nil && err.(*pq.Error).Code != "3D000" {
@@ -116,11 +223,11 @@ func (c *pg) DropDatabase(database string) error {
 }
 
 func (c *pg) CreateExtension(db, extension string) error {
-	tmpDb, err := GetConnection(c.user, c.pass, c.host, db, c.args)
+	tmpDb, release, err := c.connection(db)
 	if err != nil {
 		return err
 	}
-	defer tmpDb.Close()
+	defer release()
 
 	_, err = tmpDb.Exec(fmt.Sprintf(CREATE_EXTENSION, extension))
 	if err != nil {
@@ -130,11 +237,11 @@ func (c *pg) CreateExtension(db, extension string) error {
 }
 
 func (c *pg) SetSchemaPrivileges(schemaPrivileges PostgresSchemaPrivileges) error {
-	tmpDb, err := GetConnection(c.user, c.pass, c.host, schemaPrivileges.DB, c.args)
+	tmpDb, release, err := c.connection(schemaPrivileges.DB)
 	if err != nil {
 		return err
 	}
-	defer tmpDb.Close()
+	defer release()
 
 	// Grant role usage on schema
 	_, err = tmpDb.Exec(fmt.Sprintf(GRANT_USAGE_SCHEMA, schemaPrivileges.Schema, schemaPrivileges.Role))
@@ -143,9 +250,25 @@ func (c *pg) SetSchemaPrivileges(schemaPrivileges PostgresSchemaPrivileges) erro
 	}
 
 	// Grant role privs on existing tables in schema
-	_, err = tmpDb.Exec(fmt.Sprintf(GRANT_ALL_TABLES, schemaPrivileges.Privs, schemaPrivileges.Schema, schemaPrivileges.Role))
-	if err != nil {
-		return err
+	if len(schemaPrivileges.ExcludedTables) == 0 {
+		_, err = tmpDb.Exec(fmt.Sprintf(GRANT_ALL_TABLES, schemaPrivileges.Privs, schemaPrivileges.Schema, schemaPrivileges.Role))
+		if err != nil {
+			return err
+		}
+	} else {
+		tables, err := queryStrings(tmpDb, LIST_TABLES, schemaPrivileges.Schema)
+		if err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if slices.Contains(schemaPrivileges.ExcludedTables, table) {
+				continue
+			}
+			_, err = tmpDb.Exec(fmt.Sprintf(GRANT_TABLE, schemaPrivileges.Privs, schemaPrivileges.Schema, table, schemaPrivileges.Role))
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Grant role privs on future tables in schema
@@ -182,6 +305,12 @@ func (c *pg) SetSchemaPrivileges(schemaPrivileges PostgresSchemaPrivileges) erro
 		}
 	}
 
+	// Grant role privs on future objects of other roles in schema
+	err = c.setCreatorDefaultPrivileges(tmpDb, schemaPrivileges)
+	if err != nil {
+		return err
+	}
+
 	// Grant role usage on schema if createSchema
 	if schemaPrivileges.CreateSchema {
 		_, err = tmpDb.Exec(fmt.Sprintf(GRANT_CREATE_TABLE, schemaPrivileges.Schema, schemaPrivileges.Role))
@@ -192,3 +321,93 @@ func (c *pg) SetSchemaPrivileges(schemaPrivileges PostgresSchemaPrivileges) erro
 
 	return nil
 }
+
+// SetTablePrivileges replaces the privileges of a role on a table. Revoking and granting happen
+// in one transaction, so the role never holds more or less than one of both states.
+func (c *pg) SetTablePrivileges(tableGrant PostgresTableGrant) error {
+	tmpDb, release, err := c.connection(tableGrant.DB)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tx, err := tmpDb.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	columns := tableGrant.Columns
+	if len(tableGrant.ExceptColumns) > 0 {
+		all, err := queryStrings(tx, LIST_COLUMNS, fmt.Sprintf(`"%s"."%s"`, tableGrant.Schema, tableGrant.Table))
+		if err != nil {
+			return err
+		}
+		columns = slices.DeleteFunc(all, func(column string) bool {
+			return slices.Contains(tableGrant.ExceptColumns, column)
+		})
+		if len(columns) == 0 {
+			return fmt.Errorf("no columns of table %s.%s left to grant", tableGrant.Schema, tableGrant.Table)
+		}
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(REVOKE_TABLE, tableGrant.Schema, tableGrant.Table, tableGrant.Role))
+	if err != nil {
+		return err
+	}
+
+	privs := tableGrant.Privs
+	if len(columns) > 0 {
+		columnList := fmt.Sprintf(`("%s")`, strings.Join(columns, `", "`))
+		privs = make([]string, len(tableGrant.Privs))
+		for i, priv := range tableGrant.Privs {
+			privs[i] = priv + " " + columnList
+		}
+	}
+	_, err = tx.Exec(fmt.Sprintf(GRANT_TABLE, strings.Join(privs, ", "), tableGrant.Schema, tableGrant.Table, tableGrant.Role))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *pg) RevokeTablePrivileges(db, schema, table, role string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(REVOKE_TABLE, schema, table, role))
+	// Error code 42P01 is returned if the table doesn't exist (anymore)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42P01" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// queryStrings returns the single column result of query
+func queryStrings(db queryer, query string, args ...any) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
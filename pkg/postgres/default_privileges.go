@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+const (
+	DEFAULT_PRIVS_FOR_ROLE = `ALTER DEFAULT PRIVILEGES FOR ROLE "%s" IN SCHEMA "%s" GRANT %s ON %s TO "%s"`
+	GET_DEFAULT_PRIVS      = `SELECT pg_catalog.pg_get_userbyid(d.defaclrole),
+		CASE a.grantee WHEN 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(a.grantee) END,
+		CASE d.defaclobjtype WHEN 'r' THEN 'TABLES' WHEN 'S' THEN 'SEQUENCES' WHEN 'f' THEN 'FUNCTIONS' WHEN 'T' THEN 'TYPES' ELSE 'SCHEMAS' END,
+		a.privilege_type
+		FROM pg_catalog.pg_default_acl d
+		JOIN pg_catalog.pg_namespace n ON n.oid = d.defaclnamespace
+		CROSS JOIN LATERAL pg_catalog.aclexplode(d.defaclacl) a
+		WHERE n.nspname = $1`
+	IS_ROLE_MEMBER = `SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_auth_members m
+		JOIN pg_catalog.pg_roles r ON r.oid = m.roleid
+		JOIN pg_catalog.pg_roles u ON u.oid = m.member
+		WHERE r.rolname = $1 AND u.rolname = $2)`
+)
+
+// setCreatorDefaultPrivileges grants the role of schemaPrivileges its privileges on objects the
+// creators of schemaPrivileges make in the schema in the future
+func (c *pg) setCreatorDefaultPrivileges(db *sql.DB, schemaPrivileges PostgresSchemaPrivileges) error {
+	for _, creator := range schemaPrivileges.Creators {
+		// A role has all privileges on its own objects anyway
+		if creator == schemaPrivileges.Role {
+			continue
+		}
+		err := c.setDefaultPrivilegesFor(db, creator, schemaPrivileges)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDefaultPrivilegesFor grants the role of schemaPrivileges its privileges on objects creator makes in the
+// schema in the future. Only members of a role may alter its default privileges, so the operator's user joins
+// creator for the time it takes, unless it is a member already.
+func (c *pg) setDefaultPrivilegesFor(db *sql.DB, creator string, schemaPrivileges PostgresSchemaPrivileges) (err error) {
+	var member bool
+	err = db.QueryRow(IS_ROLE_MEMBER, creator, c.user).Scan(&member)
+	if err != nil {
+		return err
+	}
+	if !member {
+		_, err = db.Exec(fmt.Sprintf(GRANT_ROLE, creator, c.user))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_, revokeErr := db.Exec(fmt.Sprintf(REVOKE_ROLE, creator, c.user))
+			err = errors.Join(err, revokeErr)
+		}()
+	}
+
+	for _, objects := range []struct{ kind, privs string }{
+		{"TABLES", schemaPrivileges.Privs},
+		{"SEQUENCES", schemaPrivileges.SequencePrivs},
+		{"FUNCTIONS", schemaPrivileges.FunctionPrivs},
+	} {
+		if objects.privs == "" {
+			continue
+		}
+		_, err = db.Exec(fmt.Sprintf(DEFAULT_PRIVS_FOR_ROLE, creator, schemaPrivileges.Schema, objects.privs, objects.kind, schemaPrivileges.Role))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDefaultPrivileges lists the privileges pg_default_acl grants on objects created in schema in the future
+func (c *pg) GetDefaultPrivileges(db, schema string) ([]PostgresDefaultPrivilege, error) {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := tmpDb.Query(GET_DEFAULT_PRIVS, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var privileges []PostgresDefaultPrivilege
+	for rows.Next() {
+		var privilege PostgresDefaultPrivilege
+		err = rows.Scan(&privilege.Creator, &privilege.Grantee, &privilege.ObjectType, &privilege.Privilege)
+		if err != nil {
+			return nil, err
+		}
+		privileges = append(privileges, privilege)
+	}
+	return privileges, rows.Err()
+}
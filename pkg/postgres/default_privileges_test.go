@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("setCreatorDefaultPrivileges", func() {
+	var (
+		c        *pg
+		db       *sql.DB
+		executed []string
+		// roles the operator's user is a member of
+		memberOf []string
+		failing  map[string]error
+		mu       sync.Mutex
+	)
+
+	privileges := PostgresSchemaPrivileges{
+		DB:       "app",
+		Role:     "app-reader",
+		Schema:   "app",
+		Privs:    "SELECT",
+		Creators: []string{"app-group", "app-writer", "app-reader"},
+	}
+
+	BeforeEach(func() {
+		executed = nil
+		memberOf = nil
+		failing = map[string]error{}
+		db = sql.OpenDB(&fakeConnector{
+			result: func(query string) (driver.Result, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				executed = append(executed, query)
+				for prefix, err := range failing {
+					if strings.HasPrefix(query, prefix) {
+						return nil, err
+					}
+				}
+				return driver.RowsAffected(0), nil
+			},
+			rows: func(query string, args []driver.NamedValue) ([][]driver.Value, error) {
+				Expect(query).To(Equal(IS_ROLE_MEMBER))
+				Expect(args[1].Value).To(Equal("operator"))
+				for _, role := range memberOf {
+					if role == args[0].Value {
+						return [][]driver.Value{{true}}, nil
+					}
+				}
+				return [][]driver.Value{{false}}, nil
+			},
+		})
+		c = &pg{db: db, log: logr.Discard(), user: "operator"}
+	})
+
+	It("should join the creators only while setting their default privileges", func() {
+		Expect(c.setCreatorDefaultPrivileges(db, privileges)).To(Succeed())
+		Expect(executed).To(Equal([]string{
+			`GRANT "app-group" TO "operator"`,
+			`ALTER DEFAULT PRIVILEGES FOR ROLE "app-group" IN SCHEMA "app" GRANT SELECT ON TABLES TO "app-reader"`,
+			`REVOKE "app-group" FROM "operator"`,
+			`GRANT "app-writer" TO "operator"`,
+			`ALTER DEFAULT PRIVILEGES FOR ROLE "app-writer" IN SCHEMA "app" GRANT SELECT ON TABLES TO "app-reader"`,
+			`REVOKE "app-writer" FROM "operator"`,
+		}))
+	})
+
+	It("should keep memberships the operator's user had before", func() {
+		memberOf = []string{"app-group"}
+		Expect(c.setCreatorDefaultPrivileges(db, privileges)).To(Succeed())
+		Expect(executed).NotTo(ContainElement(`GRANT "app-group" TO "operator"`))
+		Expect(executed).NotTo(ContainElement(`REVOKE "app-group" FROM "operator"`))
+		Expect(executed).To(ContainElement(`REVOKE "app-writer" FROM "operator"`))
+	})
+
+	It("should leave a creator when its default privileges cannot be set", func() {
+		failing[`ALTER DEFAULT PRIVILEGES FOR ROLE "app-group"`] = errors.New("permission denied")
+		Expect(c.setCreatorDefaultPrivileges(db, privileges)).To(MatchError("permission denied"))
+		Expect(executed).To(ContainElement(`REVOKE "app-group" FROM "operator"`))
+	})
+})
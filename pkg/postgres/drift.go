@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	GET_EXISTING_ROLES   = `SELECT rolname FROM pg_catalog.pg_roles WHERE rolname = ANY($1)`
+	GET_DB_OWNER_BY_NAME = `SELECT pg_catalog.pg_get_userbyid(datdba) FROM pg_catalog.pg_database WHERE datname = $1`
+	GET_SCHEMA_OWNERS    = `SELECT nspname, pg_catalog.pg_get_userbyid(nspowner) FROM pg_catalog.pg_namespace WHERE nspname = ANY($1)`
+	GET_SCHEMA_GRANTS    = `SELECT n.nspname, r.rolname, p.privilege
+		FROM pg_catalog.pg_namespace n
+		CROSS JOIN pg_catalog.pg_roles r
+		CROSS JOIN unnest(ARRAY['USAGE', 'CREATE']) AS p(privilege)
+		WHERE n.nspname = ANY($1) AND r.rolname = ANY($2) AND pg_catalog.has_schema_privilege(r.oid, n.oid, p.privilege)`
+)
+
+// PostgresDatabaseState is what the server knows about a database and the roles and schemas the operator manages
+type PostgresDatabaseState struct {
+	Exists bool
+	Owner  string
+	// Roles that exist of those asked for
+	Roles []string
+	// Owners of the schemas that exist of those asked for
+	SchemaOwners map[string]string
+	// USAGE and CREATE privileges the roles hold on the schemas
+	SchemaGrants []PostgresSchemaGrant
+}
+
+// PostgresSchemaGrant is a privilege of Role on Schema
+type PostgresSchemaGrant struct {
+	Schema    string
+	Role      string
+	Privilege string
+}
+
+// GetDatabaseState reads the state of db, roles and schemas from pg_database, pg_roles and pg_namespace
+func (c *pg) GetDatabaseState(db string, roles, schemas []string) (PostgresDatabaseState, error) {
+	state := PostgresDatabaseState{SchemaOwners: map[string]string{}}
+	err := c.db.QueryRow(GET_DB_OWNER_BY_NAME, db).Scan(&state.Owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	state.Exists = true
+
+	state.Roles, err = queryStrings(c.db, GET_EXISTING_ROLES, pq.Array(roles))
+	if err != nil {
+		return state, err
+	}
+	if len(schemas) == 0 {
+		return state, nil
+	}
+
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return state, err
+	}
+	defer release()
+
+	rows, err := tmpDb.Query(GET_SCHEMA_OWNERS, pq.Array(schemas))
+	if err != nil {
+		return state, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, owner string
+		if err := rows.Scan(&schema, &owner); err != nil {
+			return state, err
+		}
+		state.SchemaOwners[schema] = owner
+	}
+	if err := rows.Err(); err != nil {
+		return state, err
+	}
+
+	grants, err := tmpDb.Query(GET_SCHEMA_GRANTS, pq.Array(schemas), pq.Array(roles))
+	if err != nil {
+		return state, err
+	}
+	defer grants.Close()
+	for grants.Next() {
+		var grant PostgresSchemaGrant
+		if err := grants.Scan(&grant.Schema, &grant.Role, &grant.Privilege); err != nil {
+			return state, err
+		}
+		state.SchemaGrants = append(state.SchemaGrants, grant)
+	}
+	return state, grants.Err()
+}
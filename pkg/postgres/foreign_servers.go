@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+const (
+	CREATE_FOREIGN_SERVER = `CREATE SERVER IF NOT EXISTS "%s" FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host %s, port %s, dbname %s)`
+	ALTER_FOREIGN_SERVER  = `ALTER SERVER "%s" OPTIONS (SET host %s, SET port %s, SET dbname %s)`
+	DROP_FOREIGN_SERVER   = `DROP SERVER IF EXISTS "%s"`
+	CREATE_USER_MAPPING   = `CREATE USER MAPPING IF NOT EXISTS FOR "%s" SERVER "%s" OPTIONS (user %s, password %s)`
+	ALTER_USER_MAPPING    = `ALTER USER MAPPING FOR "%s" SERVER "%s" OPTIONS (SET user %s, SET password %s)`
+	DROP_USER_MAPPING     = `DROP USER MAPPING IF EXISTS FOR "%s" SERVER "%s"`
+	GRANT_FOREIGN_SERVER  = `GRANT USAGE ON FOREIGN SERVER "%s" TO "%s"`
+	REVOKE_FOREIGN_SERVER = `REVOKE USAGE ON FOREIGN SERVER "%s" FROM "%s"`
+	CREATE_FDW_EXTENSION  = `CREATE EXTENSION IF NOT EXISTS postgres_fdw`
+)
+
+// PostgresForeignServer is a postgres_fdw server pointing at another database
+type PostgresForeignServer struct {
+	Name   string
+	Host   string
+	Port   string
+	DBName string
+}
+
+// PostgresUserMapping maps a local role to the credentials used on a foreign server
+type PostgresUserMapping struct {
+	Server   string
+	Role     string
+	User     string
+	Password string
+}
+
+// CreateOrUpdateForeignServer creates the postgres_fdw extension and the server in db,
+// options of an existing server are overwritten.
+func (c *pg) CreateOrUpdateForeignServer(db string, server PostgresForeignServer) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(CREATE_FDW_EXTENSION)
+	if err != nil {
+		return err
+	}
+	host, port, dbname := pq.QuoteLiteral(server.Host), pq.QuoteLiteral(server.Port), pq.QuoteLiteral(server.DBName)
+	_, err = tmpDb.Exec(fmt.Sprintf(CREATE_FOREIGN_SERVER, server.Name, host, port, dbname))
+	if err != nil {
+		return err
+	}
+	_, err = tmpDb.Exec(fmt.Sprintf(ALTER_FOREIGN_SERVER, server.Name, host, port, dbname))
+	return err
+}
+
+// DropForeignServer drops a server without its user mappings, it fails while foreign tables use it
+func (c *pg) DropForeignServer(db, name string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		// Error code 3D000 is returned if database doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "3D000" {
+			return nil
+		}
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(DROP_FOREIGN_SERVER, name))
+	return err
+}
+
+// SetUserMapping creates or updates the user mapping of a role and allows the role to use the server
+func (c *pg) SetUserMapping(db string, mapping PostgresUserMapping) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tx, err := tmpDb.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	user, password := pq.QuoteLiteral(mapping.User), pq.QuoteLiteral(mapping.Password)
+	_, err = tx.Exec(fmt.Sprintf(CREATE_USER_MAPPING, mapping.Role, mapping.Server, user, password))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf(ALTER_USER_MAPPING, mapping.Role, mapping.Server, user, password))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf(GRANT_FOREIGN_SERVER, mapping.Server, mapping.Role))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DropUserMapping drops the user mapping of a role and revokes its usage of the server
+func (c *pg) DropUserMapping(db, server, role string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		// Error code 3D000 is returned if database doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "3D000" {
+			return nil
+		}
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(DROP_USER_MAPPING, role, server))
+	if err != nil {
+		return err
+	}
+	_, err = tmpDb.Exec(fmt.Sprintf(REVOKE_FOREIGN_SERVER, server, role))
+	if err != nil {
+		// Error code 42704 is returned if the server or role doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42704" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -16,18 +16,26 @@ func newGCPPG(postgres *pg) PG {
 	}
 }
 
-func (c *gcppg) CreateDB(dbname, role string) error {
+func (c *gcppg) CreateDB(dbname, role string, tag ObjectTag) error {
 	err := c.GrantRole(role, c.user)
 	if err != nil {
 		return err
 	}
-	err = c.pg.CreateDB(dbname, role)
+	err = c.pg.CreateDB(dbname, role, tag)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func (c *gcppg) CreateDBFromTemplate(dbname, role, template string, terminateConnections bool, tag ObjectTag) error {
+	err := c.GrantRole(role, c.user)
+	if err != nil {
+		return err
+	}
+	return c.pg.CreateDBFromTemplate(dbname, role, template, terminateConnections, tag)
+}
+
 func (c *gcppg) DropRole(role, newOwner, database string) error {
 	if role == "alloydbsuperuser" || role == "postgres" {
 		c.log.V(1).Info(fmt.Sprintf("not dropping %s as it is a reserved AlloyDB role", role))
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/movetokube/postgres-operator/pkg/utils"
+)
+
+const (
+	CREATE_MIGRATIONS_TABLE = `CREATE TABLE IF NOT EXISTS public.postgres_operator_migrations (name text PRIMARY KEY, checksum text NOT NULL, applied_at timestamptz NOT NULL DEFAULT now())`
+	GET_MIGRATIONS          = `SELECT name, checksum, applied_at FROM public.postgres_operator_migrations ORDER BY applied_at, name`
+	RECORD_MIGRATION        = `INSERT INTO public.postgres_operator_migrations (name, checksum) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`
+	GRANT_RECORD_MIGRATION  = `GRANT INSERT ON public.postgres_operator_migrations TO "%s"`
+	CREATE_MIGRATION_ROLE   = `CREATE ROLE "%s" WITH LOGIN PASSWORD '%s' VALID UNTIL '%s' IN ROLE "%s"`
+	SET_LOCAL_ROLE          = `SET LOCAL ROLE "%s"`
+)
+
+// migrationLoginValidity is how long the temporary role running a migration may log in
+const migrationLoginValidity = 10 * time.Minute
+
+// PostgresMigration is a SQL script applied once to a database.
+// Applied migrations are recorded with their checksum in a table of the database.
+type PostgresMigration struct {
+	Name      string
+	Checksum  string
+	SQL       string
+	AppliedAt time.Time
+}
+
+// GetMigrations lists the migrations applied to db
+func (c *pg) GetMigrations(db string) ([]PostgresMigration, error) {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(CREATE_MIGRATIONS_TABLE)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tmpDb.Query(GET_MIGRATIONS)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var migrations []PostgresMigration
+	for rows.Next() {
+		var migration PostgresMigration
+		err = rows.Scan(&migration.Name, &migration.Checksum, &migration.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, rows.Err()
+}
+
+// ApplyMigration runs the script of migration as role and records it, in one transaction.
+// A migration recorded in the meantime is not run again.
+//
+// The script is not run by the operator's own user: SET ROLE alone can be undone by the script, e.g. with
+// RESET ROLE or SET SESSION AUTHORIZATION. Instead a temporary login role is created as a member of role,
+// the script runs on a connection of its own logged in as that role, and the role is dropped afterwards.
+// This needs ADMIN OPTION on role for the operator's user and password authentication for the temporary role.
+func (c *pg) ApplyMigration(db, role string, migration PostgresMigration) (err error) {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(CREATE_MIGRATIONS_TABLE)
+	if err != nil {
+		return err
+	}
+	_, err = tmpDb.Exec(fmt.Sprintf(GRANT_RECORD_MIGRATION, role))
+	if err != nil {
+		return err
+	}
+
+	login, err := c.createMigrationRole(role)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42501" {
+			return fmt.Errorf("create migration role: the operator's user %s needs ADMIN OPTION on role %s to run migrations: %w", c.user, role, err)
+		}
+		return fmt.Errorf("create migration role: %w", err)
+	}
+	// Registered before the migration connection is opened, so it runs after that connection is closed
+	defer func() {
+		if dropErr := c.dropMigrationRole(tmpDb, login.user, role); dropErr != nil {
+			err = errors.Join(err, fmt.Errorf("drop migration role %s: %w", login.user, dropErr))
+		}
+	}()
+
+	migrationDb, err := c.login(login.user, login.password, db)
+	if migrationDb != nil {
+		// The session may be left in any state by the script, it must not be reused
+		defer migrationDb.Close()
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "28P01" || pqErr.Code == "28000") {
+			return fmt.Errorf("migration role %s could not log in, pg_hba.conf must allow password authentication for it: %w", login.user, err)
+		}
+		return err
+	}
+
+	tx, err := migrationDb.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Objects are owned by role rather than by the temporary role
+	_, err = tx.Exec(fmt.Sprintf(SET_LOCAL_ROLE, role))
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(RECORD_MIGRATION, migration.Name, migration.Checksum)
+	if err != nil {
+		return err
+	}
+	if recorded, err := res.RowsAffected(); err != nil || recorded == 0 {
+		return err
+	}
+	_, err = tx.Exec(migration.SQL)
+	if err != nil {
+		return fmt.Errorf("migration %s: %w", migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+type migrationLogin struct {
+	user     string
+	password string
+}
+
+// createMigrationRole creates a login role which is a member of role and may log in for a short while
+func (c *pg) createMigrationRole(role string) (migrationLogin, error) {
+	suffix, err := utils.GetSecureRandomString(12)
+	if err != nil {
+		return migrationLogin{}, err
+	}
+	password, err := utils.GetSecureRandomString(32)
+	if err != nil {
+		return migrationLogin{}, err
+	}
+	login := migrationLogin{
+		user:     "postgres_operator_migration_" + strings.ToLower(suffix),
+		password: password,
+	}
+	until := time.Now().Add(migrationLoginValidity).UTC().Format(time.RFC3339)
+	_, err = c.db.Exec(fmt.Sprintf(CREATE_MIGRATION_ROLE, login.user, login.password, until, role))
+	return login, err
+}
+
+// dropMigrationRole hands objects the script created as the temporary role over to role and drops it.
+// Every step is attempted, so the role is dropped even if its objects cannot be handed over.
+func (c *pg) dropMigrationRole(db *sql.DB, user, role string) error {
+	var errs []error
+	// Without superuser, e.g. on AWS RDS or GCP, REASSIGN OWNED BY requires membership of the role
+	_, err := db.Exec(fmt.Sprintf(GRANT_ROLE, user, c.user))
+	errs = append(errs, err)
+	_, err = db.Exec(fmt.Sprintf(REASIGN_OBJECTS, user, role))
+	errs = append(errs, err)
+	_, err = db.Exec(fmt.Sprintf(DROP_OWNED_BY, user))
+	errs = append(errs, err)
+	_, err = c.db.Exec(fmt.Sprintf(DROP_ROLE, user))
+	errs = append(errs, err)
+	return errors.Join(errs...)
+}
+
+// loginArgs are the URI args of the operator without its client certificate, for connections of other roles
+func loginArgs(uriArgs string) (string, error) {
+	args, err := url.ParseQuery(uriArgs)
+	if err != nil {
+		return "", err
+	}
+	for _, arg := range []string{"sslcert", "sslkey", "sslinline"} {
+		args.Del(arg)
+	}
+	return args.Encode(), nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyMigration", func() {
+	var (
+		c *pg
+		// statements executed by the operator's user and by the migration role
+		admin, migration []string
+		// statements failing, by prefix
+		failing  map[string]error
+		loginErr error
+		mu       sync.Mutex
+	)
+
+	migrationRole := func() string {
+		for _, query := range admin {
+			if name, found := strings.CutPrefix(query, `CREATE ROLE "`); found {
+				name, _, _ = strings.Cut(name, `"`)
+				return name
+			}
+		}
+		return ""
+	}
+
+	recorder := func(executed *[]string) *fakeConnector {
+		return &fakeConnector{result: func(query string) (driver.Result, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			*executed = append(*executed, query)
+			for prefix, err := range failing {
+				if strings.HasPrefix(query, prefix) {
+					return nil, err
+				}
+			}
+			return driver.RowsAffected(1), nil
+		}}
+	}
+
+	BeforeEach(func() {
+		admin, migration = nil, nil
+		failing = map[string]error{}
+		loginErr = nil
+		c = &pg{
+			db: sql.OpenDB(recorder(&admin)),
+			connections: newConnectionCache(connectionLimits{}, func(string) (*sql.DB, error) {
+				return sql.OpenDB(recorder(&admin)), nil
+			}),
+			log:  logr.Discard(),
+			user: "operator",
+			login: func(user, password, database string) (*sql.DB, error) {
+				if loginErr != nil {
+					return nil, loginErr
+				}
+				return sql.OpenDB(recorder(&migration)), nil
+			},
+		}
+	})
+
+	apply := func() error {
+		return c.ApplyMigration("app", "app-group", PostgresMigration{Name: "001_tables.sql", Checksum: "abc", SQL: "CREATE TABLE t (id int)"})
+	}
+
+	It("should run the script as the migration role and drop it", func() {
+		Expect(apply()).To(Succeed())
+		role := migrationRole()
+		Expect(role).To(HavePrefix("postgres_operator_migration_"))
+		Expect(migration).To(ContainElements(`SET LOCAL ROLE "app-group"`, "CREATE TABLE t (id int)"))
+		Expect(admin).To(ContainElements(
+			`REASSIGN OWNED BY "`+role+`" TO "app-group"`,
+			`DROP OWNED BY "`+role+`"`,
+			`DROP ROLE "`+role+`"`,
+		))
+	})
+
+	It("should drop the migration role when the script fails", func() {
+		failing["CREATE TABLE t "] = errors.New("syntax error")
+		Expect(apply()).To(MatchError(ContainSubstring("migration 001_tables.sql: syntax error")))
+		Expect(admin).To(ContainElement(`DROP ROLE "` + migrationRole() + `"`))
+	})
+
+	It("should drop the migration role when its objects cannot be handed over", func() {
+		failing["REASSIGN OWNED BY"] = errors.New("permission denied")
+		err := apply()
+		Expect(err).To(MatchError(ContainSubstring("drop migration role")))
+		Expect(err).To(MatchError(ContainSubstring("permission denied")))
+		Expect(admin).To(ContainElement(`DROP ROLE "` + migrationRole() + `"`))
+	})
+
+	It("should drop the migration role when it cannot log in", func() {
+		loginErr = &pq.Error{Code: "28P01", Message: "password authentication failed"}
+		Expect(apply()).To(MatchError(ContainSubstring("must allow password authentication")))
+		Expect(admin).To(ContainElement(`DROP ROLE "` + migrationRole() + `"`))
+		Expect(migration).To(BeEmpty())
+	})
+
+	It("should report missing ADMIN OPTION on the role", func() {
+		failing["CREATE ROLE"] = &pq.Error{Code: "42501", Message: "permission denied to grant role"}
+		Expect(apply()).To(MatchError(ContainSubstring("the operator's user operator needs ADMIN OPTION on role app-group")))
+		Expect(admin).NotTo(ContainElement(HavePrefix("DROP ROLE")))
+	})
+})
@@ -11,6 +11,7 @@ package mock_postgres
 
 import (
 	reflect "reflect"
+	time "time"
 
 	postgres "github.com/movetokube/postgres-operator/pkg/postgres"
 	gomock "go.uber.org/mock/gomock"
@@ -68,18 +69,75 @@ func (mr *MockPGMockRecorder) AlterDefaultLoginRole(role, setRole any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AlterDefaultLoginRole", reflect.TypeOf((*MockPG)(nil).AlterDefaultLoginRole), role, setRole)
 }
 
+// AlterSchemaOwner mocks base method.
+func (m *MockPG) AlterSchemaOwner(db, schema, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AlterSchemaOwner", db, schema, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AlterSchemaOwner indicates an expected call of AlterSchemaOwner.
+func (mr *MockPGMockRecorder) AlterSchemaOwner(db, schema, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AlterSchemaOwner", reflect.TypeOf((*MockPG)(nil).AlterSchemaOwner), db, schema, owner)
+}
+
+// ApplyMigration mocks base method.
+func (m *MockPG) ApplyMigration(db, role string, migration postgres.PostgresMigration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyMigration", db, role, migration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyMigration indicates an expected call of ApplyMigration.
+func (mr *MockPGMockRecorder) ApplyMigration(db, role, migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyMigration", reflect.TypeOf((*MockPG)(nil).ApplyMigration), db, role, migration)
+}
+
 // CreateDB mocks base method.
-func (m *MockPG) CreateDB(dbname, username string) error {
+func (m *MockPG) CreateDB(dbname, username string, tag postgres.ObjectTag) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateDB", dbname, username)
+	ret := m.ctrl.Call(m, "CreateDB", dbname, username, tag)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateDB indicates an expected call of CreateDB.
-func (mr *MockPGMockRecorder) CreateDB(dbname, username any) *gomock.Call {
+func (mr *MockPGMockRecorder) CreateDB(dbname, username, tag any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDB", reflect.TypeOf((*MockPG)(nil).CreateDB), dbname, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDB", reflect.TypeOf((*MockPG)(nil).CreateDB), dbname, username, tag)
+}
+
+// CreateDBFromTemplate mocks base method.
+func (m *MockPG) CreateDBFromTemplate(dbname, username, template string, terminateConnections bool, tag postgres.ObjectTag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDBFromTemplate", dbname, username, template, terminateConnections, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDBFromTemplate indicates an expected call of CreateDBFromTemplate.
+func (mr *MockPGMockRecorder) CreateDBFromTemplate(dbname, username, template, terminateConnections, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDBFromTemplate", reflect.TypeOf((*MockPG)(nil).CreateDBFromTemplate), dbname, username, template, terminateConnections, tag)
+}
+
+// CreateExpiringUserRole mocks base method.
+func (m *MockPG) CreateExpiringUserRole(role, password string, until time.Time, tag postgres.ObjectTag) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExpiringUserRole", role, password, until, tag)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExpiringUserRole indicates an expected call of CreateExpiringUserRole.
+func (mr *MockPGMockRecorder) CreateExpiringUserRole(role, password, until, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExpiringUserRole", reflect.TypeOf((*MockPG)(nil).CreateExpiringUserRole), role, password, until, tag)
 }
 
 // CreateExtension mocks base method.
@@ -97,46 +155,116 @@ func (mr *MockPGMockRecorder) CreateExtension(db, extension any) *gomock.Call {
 }
 
 // CreateGroupRole mocks base method.
-func (m *MockPG) CreateGroupRole(role string) error {
+func (m *MockPG) CreateGroupRole(role string, tag postgres.ObjectTag) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateGroupRole", role)
+	ret := m.ctrl.Call(m, "CreateGroupRole", role, tag)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateGroupRole indicates an expected call of CreateGroupRole.
-func (mr *MockPGMockRecorder) CreateGroupRole(role any) *gomock.Call {
+func (mr *MockPGMockRecorder) CreateGroupRole(role, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupRole", reflect.TypeOf((*MockPG)(nil).CreateGroupRole), role, tag)
+}
+
+// CreateOrUpdateForeignServer mocks base method.
+func (m *MockPG) CreateOrUpdateForeignServer(db string, server postgres.PostgresForeignServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateForeignServer", db, server)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateForeignServer indicates an expected call of CreateOrUpdateForeignServer.
+func (mr *MockPGMockRecorder) CreateOrUpdateForeignServer(db, server any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupRole", reflect.TypeOf((*MockPG)(nil).CreateGroupRole), role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateForeignServer", reflect.TypeOf((*MockPG)(nil).CreateOrUpdateForeignServer), db, server)
+}
+
+// CreateOrUpdatePublication mocks base method.
+func (m *MockPG) CreateOrUpdatePublication(db string, publication postgres.PostgresPublication) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdatePublication", db, publication)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdatePublication indicates an expected call of CreateOrUpdatePublication.
+func (mr *MockPGMockRecorder) CreateOrUpdatePublication(db, publication any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdatePublication", reflect.TypeOf((*MockPG)(nil).CreateOrUpdatePublication), db, publication)
+}
+
+// CreateOrUpdateSubscription mocks base method.
+func (m *MockPG) CreateOrUpdateSubscription(db string, subscription postgres.PostgresSubscription, updateConnection bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateSubscription", db, subscription, updateConnection)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateSubscription indicates an expected call of CreateOrUpdateSubscription.
+func (mr *MockPGMockRecorder) CreateOrUpdateSubscription(db, subscription, updateConnection any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateSubscription", reflect.TypeOf((*MockPG)(nil).CreateOrUpdateSubscription), db, subscription, updateConnection)
+}
+
+// CreateReplicationSlot mocks base method.
+func (m *MockPG) CreateReplicationSlot(db, name, plugin string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReplicationSlot", db, name, plugin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReplicationSlot indicates an expected call of CreateReplicationSlot.
+func (mr *MockPGMockRecorder) CreateReplicationSlot(db, name, plugin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReplicationSlot", reflect.TypeOf((*MockPG)(nil).CreateReplicationSlot), db, name, plugin)
 }
 
 // CreateSchema mocks base method.
-func (m *MockPG) CreateSchema(db, role, schema string) error {
+func (m *MockPG) CreateSchema(db, role, schema string, tag postgres.ObjectTag) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSchema", db, role, schema)
+	ret := m.ctrl.Call(m, "CreateSchema", db, role, schema, tag)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateSchema indicates an expected call of CreateSchema.
-func (mr *MockPGMockRecorder) CreateSchema(db, role, schema any) *gomock.Call {
+func (mr *MockPGMockRecorder) CreateSchema(db, role, schema, tag any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchema", reflect.TypeOf((*MockPG)(nil).CreateSchema), db, role, schema)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchema", reflect.TypeOf((*MockPG)(nil).CreateSchema), db, role, schema, tag)
 }
 
 // CreateUserRole mocks base method.
-func (m *MockPG) CreateUserRole(role, password string) (string, error) {
+func (m *MockPG) CreateUserRole(role, password string, tag postgres.ObjectTag) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUserRole", role, password)
+	ret := m.ctrl.Call(m, "CreateUserRole", role, password, tag)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUserRole indicates an expected call of CreateUserRole.
-func (mr *MockPGMockRecorder) CreateUserRole(role, password any) *gomock.Call {
+func (mr *MockPGMockRecorder) CreateUserRole(role, password, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserRole", reflect.TypeOf((*MockPG)(nil).CreateUserRole), role, password, tag)
+}
+
+// DisableRowSecurity mocks base method.
+func (m *MockPG) DisableRowSecurity(db, schema, table string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableRowSecurity", db, schema, table)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableRowSecurity indicates an expected call of DisableRowSecurity.
+func (mr *MockPGMockRecorder) DisableRowSecurity(db, schema, table any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserRole", reflect.TypeOf((*MockPG)(nil).CreateUserRole), role, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableRowSecurity", reflect.TypeOf((*MockPG)(nil).DisableRowSecurity), db, schema, table)
 }
 
 // DropDatabase mocks base method.
@@ -153,6 +281,62 @@ func (mr *MockPGMockRecorder) DropDatabase(db any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDatabase", reflect.TypeOf((*MockPG)(nil).DropDatabase), db)
 }
 
+// DropForeignServer mocks base method.
+func (m *MockPG) DropForeignServer(db, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropForeignServer", db, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropForeignServer indicates an expected call of DropForeignServer.
+func (mr *MockPGMockRecorder) DropForeignServer(db, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropForeignServer", reflect.TypeOf((*MockPG)(nil).DropForeignServer), db, name)
+}
+
+// DropPolicy mocks base method.
+func (m *MockPG) DropPolicy(db, schema, table, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropPolicy", db, schema, table, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropPolicy indicates an expected call of DropPolicy.
+func (mr *MockPGMockRecorder) DropPolicy(db, schema, table, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropPolicy", reflect.TypeOf((*MockPG)(nil).DropPolicy), db, schema, table, name)
+}
+
+// DropPublication mocks base method.
+func (m *MockPG) DropPublication(db, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropPublication", db, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropPublication indicates an expected call of DropPublication.
+func (mr *MockPGMockRecorder) DropPublication(db, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropPublication", reflect.TypeOf((*MockPG)(nil).DropPublication), db, name)
+}
+
+// DropReplicationSlot mocks base method.
+func (m *MockPG) DropReplicationSlot(db, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropReplicationSlot", db, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropReplicationSlot indicates an expected call of DropReplicationSlot.
+func (mr *MockPGMockRecorder) DropReplicationSlot(db, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropReplicationSlot", reflect.TypeOf((*MockPG)(nil).DropReplicationSlot), db, name)
+}
+
 // DropRole mocks base method.
 func (m *MockPG) DropRole(role, newOwner, database string) error {
 	m.ctrl.T.Helper()
@@ -167,6 +351,63 @@ func (mr *MockPGMockRecorder) DropRole(role, newOwner, database any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropRole", reflect.TypeOf((*MockPG)(nil).DropRole), role, newOwner, database)
 }
 
+// DropSubscription mocks base method.
+func (m *MockPG) DropSubscription(db, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropSubscription", db, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropSubscription indicates an expected call of DropSubscription.
+func (mr *MockPGMockRecorder) DropSubscription(db, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropSubscription", reflect.TypeOf((*MockPG)(nil).DropSubscription), db, name)
+}
+
+// DropUserMapping mocks base method.
+func (m *MockPG) DropUserMapping(db, server, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropUserMapping", db, server, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropUserMapping indicates an expected call of DropUserMapping.
+func (mr *MockPGMockRecorder) DropUserMapping(db, server, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropUserMapping", reflect.TypeOf((*MockPG)(nil).DropUserMapping), db, server, role)
+}
+
+// EnableRowSecurity mocks base method.
+func (m *MockPG) EnableRowSecurity(db, schema, table string, force bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableRowSecurity", db, schema, table, force)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableRowSecurity indicates an expected call of EnableRowSecurity.
+func (mr *MockPGMockRecorder) EnableRowSecurity(db, schema, table, force any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableRowSecurity", reflect.TypeOf((*MockPG)(nil).EnableRowSecurity), db, schema, table, force)
+}
+
+// GetDatabaseState mocks base method.
+func (m *MockPG) GetDatabaseState(db string, roles, schemas []string) (postgres.PostgresDatabaseState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDatabaseState", db, roles, schemas)
+	ret0, _ := ret[0].(postgres.PostgresDatabaseState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDatabaseState indicates an expected call of GetDatabaseState.
+func (mr *MockPGMockRecorder) GetDatabaseState(db, roles, schemas any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDatabaseState", reflect.TypeOf((*MockPG)(nil).GetDatabaseState), db, roles, schemas)
+}
+
 // GetDefaultDatabase mocks base method.
 func (m *MockPG) GetDefaultDatabase() string {
 	m.ctrl.T.Helper()
@@ -181,6 +422,111 @@ func (mr *MockPGMockRecorder) GetDefaultDatabase() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultDatabase", reflect.TypeOf((*MockPG)(nil).GetDefaultDatabase))
 }
 
+// GetDefaultPrivileges mocks base method.
+func (m *MockPG) GetDefaultPrivileges(db, schema string) ([]postgres.PostgresDefaultPrivilege, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultPrivileges", db, schema)
+	ret0, _ := ret[0].([]postgres.PostgresDefaultPrivilege)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultPrivileges indicates an expected call of GetDefaultPrivileges.
+func (mr *MockPGMockRecorder) GetDefaultPrivileges(db, schema any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultPrivileges", reflect.TypeOf((*MockPG)(nil).GetDefaultPrivileges), db, schema)
+}
+
+// GetExistingRoles mocks base method.
+func (m *MockPG) GetExistingRoles(roles []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExistingRoles", roles)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExistingRoles indicates an expected call of GetExistingRoles.
+func (mr *MockPGMockRecorder) GetExistingRoles(roles any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExistingRoles", reflect.TypeOf((*MockPG)(nil).GetExistingRoles), roles)
+}
+
+// GetMigrations mocks base method.
+func (m *MockPG) GetMigrations(db string) ([]postgres.PostgresMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigrations", db)
+	ret0, _ := ret[0].([]postgres.PostgresMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigrations indicates an expected call of GetMigrations.
+func (mr *MockPGMockRecorder) GetMigrations(db any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrations", reflect.TypeOf((*MockPG)(nil).GetMigrations), db)
+}
+
+// GetPolicies mocks base method.
+func (m *MockPG) GetPolicies(db, schema, table string) ([]postgres.PostgresPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicies", db, schema, table)
+	ret0, _ := ret[0].([]postgres.PostgresPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicies indicates an expected call of GetPolicies.
+func (mr *MockPGMockRecorder) GetPolicies(db, schema, table any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicies", reflect.TypeOf((*MockPG)(nil).GetPolicies), db, schema, table)
+}
+
+// GetReplicationSlots mocks base method.
+func (m *MockPG) GetReplicationSlots(db string) ([]postgres.PostgresReplicationSlot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReplicationSlots", db)
+	ret0, _ := ret[0].([]postgres.PostgresReplicationSlot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReplicationSlots indicates an expected call of GetReplicationSlots.
+func (mr *MockPGMockRecorder) GetReplicationSlots(db any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicationSlots", reflect.TypeOf((*MockPG)(nil).GetReplicationSlots), db)
+}
+
+// GetTag mocks base method.
+func (m *MockPG) GetTag(objectType, name string) (*postgres.ObjectTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTag", objectType, name)
+	ret0, _ := ret[0].(*postgres.ObjectTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTag indicates an expected call of GetTag.
+func (mr *MockPGMockRecorder) GetTag(objectType, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTag", reflect.TypeOf((*MockPG)(nil).GetTag), objectType, name)
+}
+
+// GetTaggedObjects mocks base method.
+func (m *MockPG) GetTaggedObjects() ([]postgres.TaggedObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaggedObjects")
+	ret0, _ := ret[0].([]postgres.TaggedObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaggedObjects indicates an expected call of GetTaggedObjects.
+func (mr *MockPGMockRecorder) GetTaggedObjects() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaggedObjects", reflect.TypeOf((*MockPG)(nil).GetTaggedObjects))
+}
+
 // GetUser mocks base method.
 func (m *MockPG) GetUser() string {
 	m.ctrl.T.Helper()
@@ -223,6 +569,20 @@ func (mr *MockPGMockRecorder) ReassignDatabaseOwner(dbName, currentOwner, newOwn
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignDatabaseOwner", reflect.TypeOf((*MockPG)(nil).ReassignDatabaseOwner), dbName, currentOwner, newOwner)
 }
 
+// RenameDatabase mocks base method.
+func (m *MockPG) RenameDatabase(currentName, newName string, terminateConnections bool, tag postgres.ObjectTag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameDatabase", currentName, newName, terminateConnections, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameDatabase indicates an expected call of RenameDatabase.
+func (mr *MockPGMockRecorder) RenameDatabase(currentName, newName, terminateConnections, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameDatabase", reflect.TypeOf((*MockPG)(nil).RenameDatabase), currentName, newName, terminateConnections, tag)
+}
+
 // RenameGroupRole mocks base method.
 func (m *MockPG) RenameGroupRole(currentRole, newRole string) error {
 	m.ctrl.T.Helper()
@@ -237,6 +597,21 @@ func (mr *MockPGMockRecorder) RenameGroupRole(currentRole, newRole any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameGroupRole", reflect.TypeOf((*MockPG)(nil).RenameGroupRole), currentRole, newRole)
 }
 
+// ReplacePolicy mocks base method.
+func (m *MockPG) ReplacePolicy(db string, policy postgres.PostgresPolicy) (postgres.PostgresPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePolicy", db, policy)
+	ret0, _ := ret[0].(postgres.PostgresPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplacePolicy indicates an expected call of ReplacePolicy.
+func (mr *MockPGMockRecorder) ReplacePolicy(db, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePolicy", reflect.TypeOf((*MockPG)(nil).ReplacePolicy), db, policy)
+}
+
 // RevokeRole mocks base method.
 func (m *MockPG) RevokeRole(role, revoked string) error {
 	m.ctrl.T.Helper()
@@ -251,6 +626,34 @@ func (mr *MockPGMockRecorder) RevokeRole(role, revoked any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockPG)(nil).RevokeRole), role, revoked)
 }
 
+// RevokeTablePrivileges mocks base method.
+func (m *MockPG) RevokeTablePrivileges(db, schema, table, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTablePrivileges", db, schema, table, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTablePrivileges indicates an expected call of RevokeTablePrivileges.
+func (mr *MockPGMockRecorder) RevokeTablePrivileges(db, schema, table, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTablePrivileges", reflect.TypeOf((*MockPG)(nil).RevokeTablePrivileges), db, schema, table, role)
+}
+
+// SetReplication mocks base method.
+func (m *MockPG) SetReplication(role string, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReplication", role, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReplication indicates an expected call of SetReplication.
+func (mr *MockPGMockRecorder) SetReplication(role, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReplication", reflect.TypeOf((*MockPG)(nil).SetReplication), role, enabled)
+}
+
 // SetSchemaPrivileges mocks base method.
 func (m *MockPG) SetSchemaPrivileges(schemaPrivileges postgres.PostgresSchemaPrivileges) error {
 	m.ctrl.T.Helper()
@@ -265,6 +668,48 @@ func (mr *MockPGMockRecorder) SetSchemaPrivileges(schemaPrivileges any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchemaPrivileges", reflect.TypeOf((*MockPG)(nil).SetSchemaPrivileges), schemaPrivileges)
 }
 
+// SetTablePrivileges mocks base method.
+func (m *MockPG) SetTablePrivileges(tableGrant postgres.PostgresTableGrant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTablePrivileges", tableGrant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTablePrivileges indicates an expected call of SetTablePrivileges.
+func (mr *MockPGMockRecorder) SetTablePrivileges(tableGrant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTablePrivileges", reflect.TypeOf((*MockPG)(nil).SetTablePrivileges), tableGrant)
+}
+
+// SetTag mocks base method.
+func (m *MockPG) SetTag(objectType, name string, tag postgres.ObjectTag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTag", objectType, name, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTag indicates an expected call of SetTag.
+func (mr *MockPGMockRecorder) SetTag(objectType, name, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTag", reflect.TypeOf((*MockPG)(nil).SetTag), objectType, name, tag)
+}
+
+// SetUserMapping mocks base method.
+func (m *MockPG) SetUserMapping(db string, mapping postgres.PostgresUserMapping) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserMapping", db, mapping)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserMapping indicates an expected call of SetUserMapping.
+func (mr *MockPGMockRecorder) SetUserMapping(db, mapping any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserMapping", reflect.TypeOf((*MockPG)(nil).SetUserMapping), db, mapping)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockPG) UpdatePassword(role, password string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ManagedBy marks the comments of objects created by the operator
+const ManagedBy = "ext-postgres-operator"
+
+const (
+	COMMENT_ON_ROLE     = `COMMENT ON ROLE "%s" IS %s`
+	COMMENT_ON_DATABASE = `COMMENT ON DATABASE "%s" IS %s`
+	COMMENT_ON_SCHEMA   = `COMMENT ON SCHEMA "%s" IS %s`
+	GET_ROLE_COMMENT    = `SELECT pg_catalog.shobj_description(oid, 'pg_authid') FROM pg_catalog.pg_roles WHERE rolname = $1`
+	GET_DB_COMMENT      = `SELECT pg_catalog.shobj_description(oid, 'pg_database') FROM pg_catalog.pg_database WHERE datname = $1`
+	GET_SCHEMA_COMMENT  = `SELECT pg_catalog.obj_description(oid, 'pg_namespace') FROM pg_catalog.pg_namespace WHERE nspname = $1`
+	// Comments of roles and databases are shared between databases
+	GET_TAGGED_OBJECTS = `SELECT 'ROLE', r.rolname, d.description
+		FROM pg_catalog.pg_roles r
+		JOIN pg_catalog.pg_shdescription d ON d.objoid = r.oid AND d.classoid = 'pg_catalog.pg_authid'::regclass
+		WHERE d.description LIKE $1
+		UNION ALL
+		SELECT 'DATABASE', db.datname, d.description
+		FROM pg_catalog.pg_database db
+		JOIN pg_catalog.pg_shdescription d ON d.objoid = db.oid AND d.classoid = 'pg_catalog.pg_database'::regclass
+		WHERE d.description LIKE $1`
+)
+
+// ErrForeignOwner is returned when an object exists already and is tagged by another resource
+var ErrForeignOwner = errors.New("object is owned by another resource")
+
+// ObjectTag identifies the resource a role, database or schema was created for.
+// It is stored as JSON in the comment of the object.
+type ObjectTag struct {
+	ManagedBy string `json:"managedBy"`
+	// Kubernetes cluster of the resource, see CLUSTER_NAME
+	Cluster string `json:"cluster,omitempty"`
+	// Operator instance, see POSTGRES_INSTANCE
+	Instance  string `json:"instance,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+	// Database a role owns objects in
+	Database string `json:"database,omitempty"`
+	// The resource was deleted and asked to keep the object, it is no orphan
+	Retained bool `json:"retained,omitempty"`
+	// Take over objects tagged by another resource instead of refusing to touch them
+	Adopt bool `json:"-"`
+}
+
+// TaggedObject is a role or database carrying an ObjectTag
+type TaggedObject struct {
+	// ROLE or DATABASE
+	Type string
+	Name string
+	Tag  ObjectTag
+}
+
+// SameOwner tells whether both tags name the same resource. The UID is left out, a resource
+// that is deleted and created again keeps its objects.
+func (t ObjectTag) SameOwner(other ObjectTag) bool {
+	return t.Cluster == other.Cluster && t.Kind == other.Kind && t.Namespace == other.Namespace && t.Name == other.Name
+}
+
+func (t ObjectTag) String() string {
+	if t.Cluster == "" {
+		return fmt.Sprintf("%s %s/%s", t.Kind, t.Namespace, t.Name)
+	}
+	return fmt.Sprintf("%s %s/%s in cluster %s", t.Kind, t.Namespace, t.Name, t.Cluster)
+}
+
+func (t ObjectTag) comment() (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return pq.QuoteLiteral(string(data)), nil
+}
+
+// parseTag returns the ObjectTag in comment, nil if the object is not managed by the operator
+func parseTag(comment sql.NullString) *ObjectTag {
+	if !comment.Valid {
+		return nil
+	}
+	tag := ObjectTag{}
+	// Somebody else may have changed the comment
+	if err := json.NewDecoder(strings.NewReader(comment.String)).Decode(&tag); err != nil || tag.ManagedBy != ManagedBy {
+		return nil
+	}
+	return &tag
+}
+
+// readTag reads the ObjectTag of the object name with query, nil if the object does not exist or is untagged
+func readTag(db *sql.DB, query, name string) (*ObjectTag, error) {
+	var comment sql.NullString
+	err := db.QueryRow(query, name).Scan(&comment)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseTag(comment), nil
+}
+
+// claim makes sure the existing object name is not tagged by another resource than tag, unless
+// adoption was requested. Untagged objects, e.g. created by older versions, are taken over.
+func claim(db *sql.DB, query, name string, tag ObjectTag) error {
+	if tag.UID == "" || tag.Adopt {
+		return nil
+	}
+	existing, err := readTag(db, query, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && !existing.SameOwner(tag) {
+		return fmt.Errorf("%w: %s belongs to %s", ErrForeignOwner, name, existing)
+	}
+	return nil
+}
+
+// tag sets the comment of an object to tag, objects created without a tag keep their comment
+func (c *pg) tag(statement, name string, tag ObjectTag) error {
+	return setTag(c.db, statement, name, tag)
+}
+
+func setTag(db *sql.DB, statement, name string, tag ObjectTag) error {
+	if tag.UID == "" {
+		return nil
+	}
+	comment, err := tag.comment()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(statement, name, comment))
+	return err
+}
+
+// GetTag returns the ObjectTag of a ROLE or DATABASE, nil if it does not exist or is not managed by the operator
+func (c *pg) GetTag(objectType, name string) (*ObjectTag, error) {
+	switch objectType {
+	case "ROLE":
+		return readTag(c.db, GET_ROLE_COMMENT, name)
+	case "DATABASE":
+		return readTag(c.db, GET_DB_COMMENT, name)
+	default:
+		return nil, fmt.Errorf("unsupported object type %s", objectType)
+	}
+}
+
+// SetTag replaces the ObjectTag of a ROLE or DATABASE
+func (c *pg) SetTag(objectType, name string, tag ObjectTag) error {
+	switch objectType {
+	case "ROLE":
+		return setTag(c.db, COMMENT_ON_ROLE, name, tag)
+	case "DATABASE":
+		return setTag(c.db, COMMENT_ON_DATABASE, name, tag)
+	default:
+		return fmt.Errorf("unsupported object type %s", objectType)
+	}
+}
+
+// GetTaggedObjects lists the roles and databases with an ObjectTag in their comment
+func (c *pg) GetTaggedObjects() ([]TaggedObject, error) {
+	rows, err := c.db.Query(GET_TAGGED_OBJECTS, fmt.Sprintf(`%%"managedBy":"%s"%%`, ManagedBy))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []TaggedObject
+	for rows.Next() {
+		var object TaggedObject
+		var comment sql.NullString
+		if err := rows.Scan(&object.Type, &object.Name, &comment); err != nil {
+			return nil, err
+		}
+		tag := parseTag(comment)
+		if tag == nil {
+			continue
+		}
+		object.Tag = *tag
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
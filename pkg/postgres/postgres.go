@@ -1,26 +1,63 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
 	"github.com/movetokube/postgres-operator/pkg/config"
 )
 
 type PG interface {
-	CreateDB(dbname, username string) error
-	CreateSchema(db, role, schema string) error
+	CreateDB(dbname, username string, tag ObjectTag) error
+	CreateDBFromTemplate(dbname, username, template string, terminateConnections bool, tag ObjectTag) error
+	RenameDatabase(currentName, newName string, terminateConnections bool, tag ObjectTag) error
+	CreateSchema(db, role, schema string, tag ObjectTag) error
+	AlterSchemaOwner(db, schema, owner string) error
 	CreateExtension(db, extension string) error
-	CreateGroupRole(role string) error
+	CreateGroupRole(role string, tag ObjectTag) error
 	RenameGroupRole(currentRole, newRole string) error
-	CreateUserRole(role, password string) (string, error)
+	CreateUserRole(role, password string, tag ObjectTag) (string, error)
 	UpdatePassword(role, password string) error
+	CreateExpiringUserRole(role, password string, until time.Time, tag ObjectTag) (string, error)
 	GrantRole(role, grantee string) error
 	AlterDatabaseOwner(dbName, owner string) error
 	ReassignDatabaseOwner(dbName, currentOwner, newOwner string) error
 	SetSchemaPrivileges(schemaPrivileges PostgresSchemaPrivileges) error
+	GetDefaultPrivileges(db, schema string) ([]PostgresDefaultPrivilege, error)
+	GetDatabaseState(db string, roles, schemas []string) (PostgresDatabaseState, error)
+	GetTaggedObjects() ([]TaggedObject, error)
+	GetTag(objectType, name string) (*ObjectTag, error)
+	SetTag(objectType, name string, tag ObjectTag) error
+	SetTablePrivileges(tableGrant PostgresTableGrant) error
+	RevokeTablePrivileges(db, schema, table, role string) error
+	EnableRowSecurity(db, schema, table string, force bool) error
+	DisableRowSecurity(db, schema, table string) error
+	GetPolicies(db, schema, table string) ([]PostgresPolicy, error)
+	ReplacePolicy(db string, policy PostgresPolicy) (PostgresPolicy, error)
+	DropPolicy(db, schema, table, name string) error
+	CreateOrUpdatePublication(db string, publication PostgresPublication) error
+	DropPublication(db, name string) error
+	CreateOrUpdateSubscription(db string, subscription PostgresSubscription, updateConnection bool) error
+	DropSubscription(db, name string) error
+	SetReplication(role string, enabled bool) error
+	CreateReplicationSlot(db, name, plugin string) error
+	DropReplicationSlot(db, name string) error
+	GetReplicationSlots(db string) ([]PostgresReplicationSlot, error)
+	CreateOrUpdateForeignServer(db string, server PostgresForeignServer) error
+	DropForeignServer(db, name string) error
+	SetUserMapping(db string, mapping PostgresUserMapping) error
+	DropUserMapping(db, server, role string) error
+	GetMigrations(db string) ([]PostgresMigration, error)
+	ApplyMigration(db, role string, migration PostgresMigration) error
 	RevokeRole(role, revoked string) error
+	GetExistingRoles(roles []string) ([]string, error)
 	AlterDefaultLoginRole(role, setRole string) error
 	DropDatabase(db string) error
 	DropRole(role, newOwner, database string) error
@@ -29,13 +66,16 @@ type PG interface {
 }
 
 type pg struct {
-	db              *sql.DB
+	db *sql.DB
+	// connections to databases other than the default database
+	connections     *connectionCache
 	log             logr.Logger
 	host            string
 	user            string
 	pass            string
-	args            string
 	defaultDatabase string
+	// login opens a connection of database as another role, without the client certificate of the operator
+	login func(user, password, database string) (*sql.DB, error)
 }
 
 type PostgresSchemaPrivileges struct {
@@ -46,27 +86,74 @@ type PostgresSchemaPrivileges struct {
 	SequencePrivs string
 	FunctionPrivs string
 	CreateSchema  bool
+	// Tables in Schema that Privs are not granted on
+	ExcludedTables []string
+	// Roles whose objects created in Schema in the future Role gets privileges on,
+	// besides the objects created by the operator's user
+	Creators []string
+}
+
+// PostgresDefaultPrivilege is a privilege granted to Grantee on objects Creator creates in the future
+type PostgresDefaultPrivilege struct {
+	Creator string
+	Grantee string
+	// TABLES, SEQUENCES, FUNCTIONS, TYPES or SCHEMAS
+	ObjectType string
+	Privilege  string
+}
+
+// PostgresTableGrant sets the privileges of Role on a single table.
+// Privileges are restricted to Columns, or to all columns except ExceptColumns, if set.
+type PostgresTableGrant struct {
+	DB            string
+	Role          string
+	Schema        string
+	Table         string
+	Privs         []string
+	Columns       []string
+	ExceptColumns []string
 }
 
 func NewPG(cfg *config.Cfg, logger logr.Logger) (PG, error) {
+	args, err := connectionArgs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	otherArgs, err := loginArgs(args)
+	if err != nil {
+		return nil, err
+	}
 	db, err := GetConnection(
 		cfg.PostgresUser,
 		cfg.PostgresPass,
 		cfg.PostgresHost,
 		cfg.PostgresDefaultDb,
-		cfg.PostgresUriArgs)
+		args)
 	if err != nil {
 		return nil, err
 	}
 	logger.V(1).Info("connected to postgres server")
+	connections := newConnectionCache(connectionLimits{
+		MaxOpen:      cfg.PostgresMaxConnsPerDatabase,
+		MaxDatabases: cfg.PostgresMaxDatabasePools,
+		IdleTimeout:  cfg.PostgresConnIdleTimeout,
+	}, func(database string) (*sql.DB, error) {
+		return GetConnection(cfg.PostgresUser, cfg.PostgresPass, cfg.PostgresHost, database, args)
+	})
+	if err := metrics.Registry.Register(connections); err != nil {
+		logger.Error(err, "could not register connection metrics")
+	}
 	postgres := &pg{
 		db:              db,
+		connections:     connections,
 		log:             logger,
 		host:            cfg.PostgresHost,
 		user:            cfg.PostgresUser,
 		pass:            cfg.PostgresPass,
-		args:            cfg.PostgresUriArgs,
 		defaultDatabase: cfg.PostgresDefaultDb,
+		login: func(user, password, database string) (*sql.DB, error) {
+			return GetConnection(user, password, cfg.PostgresHost, database, otherArgs)
+		},
 	}
 
 	switch cfg.CloudProvider {
@@ -93,6 +180,79 @@ func (c *pg) GetDefaultDatabase() string {
 	return c.defaultDatabase
 }
 
+// connection returns the cached connection pool of database and a function to call when done with it
+func (c *pg) connection(database string) (*sql.DB, func(), error) {
+	return c.connections.get(database)
+}
+
+// dedicatedConnection returns a connection of database that is not shared, for tenant-supplied SQL which may
+// leave session state behind. The function to call when done closes the connection instead of returning it to the pool.
+func (c *pg) dedicatedConnection(database string) (*sql.Conn, func(), error) {
+	tmpDb, release, err := c.connection(database)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := tmpDb.Conn(context.Background())
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return conn, func() {
+		// database/sql closes connections reported as bad rather than pooling them
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		conn.Close()
+		release()
+	}, nil
+}
+
+// connectionArgs adds the TLS settings of the operator to its URI args
+func connectionArgs(cfg *config.Cfg) (string, error) {
+	if cfg.PostgresSSLMode == "" && cfg.PostgresSSLRootCert == "" && cfg.PostgresSSLCert == "" {
+		return cfg.PostgresUriArgs, nil
+	}
+	args, err := url.ParseQuery(cfg.PostgresUriArgs)
+	if err != nil {
+		return "", fmt.Errorf("parse POSTGRES_URI_ARGS: %w", err)
+	}
+	if cfg.PostgresSSLMode != "" {
+		args.Set("sslmode", cfg.PostgresSSLMode)
+	}
+	if cfg.PostgresSSLRootCert != "" {
+		args.Set("sslrootcert", cfg.PostgresSSLRootCert)
+	}
+	if cfg.PostgresSSLCert != "" {
+		args.Set("sslcert", cfg.PostgresSSLCert)
+		args.Set("sslkey", cfg.PostgresSSLKey)
+	}
+	return args.Encode(), nil
+}
+
+// ClientConnection holds the connection settings of the operator that are handed to other clients of the server
+type ClientConnection struct {
+	// URI args without the paths of the operator's client certificate and CA bundle
+	UriArgs string
+	// Path of the CA bundle verifying the server in the operator's pod, its content is handed to clients
+	SSLRootCert string
+}
+
+// ClientConnectionArgs returns the connection settings for clients other than the operator, with the same
+// TLS settings as the operator's connection. The args are validated by NewPG already.
+func ClientConnectionArgs(cfg *config.Cfg) ClientConnection {
+	operatorArgs, _ := connectionArgs(cfg)
+	args, _ := url.ParseQuery(operatorArgs)
+	conn := ClientConnection{}
+	// "system" is the only sslrootcert value that is not a path of the operator's pod
+	if rootCert := args.Get("sslrootcert"); rootCert != "" && rootCert != "system" {
+		conn.SSLRootCert = rootCert
+		args.Del("sslrootcert")
+	}
+	for _, arg := range []string{"sslcert", "sslkey", "sslinline"} {
+		args.Del(arg)
+	}
+	conn.UriArgs = args.Encode()
+	return conn
+}
+
 func GetConnection(user, password, host, database, uriArgs string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", fmt.Sprintf("postgresql://%s:%s@%s/%s?%s", user, password, host, database, uriArgs))
 	if err != nil {
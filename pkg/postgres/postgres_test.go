@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/movetokube/postgres-operator/pkg/config"
+)
+
+var _ = Describe("ClientConnectionArgs", func() {
+	It("should apply the TLS settings of the operator", func() {
+		conn := ClientConnectionArgs(&config.Cfg{
+			PostgresUriArgs:     "sslmode=disable&connect_timeout=5",
+			PostgresSSLMode:     "verify-full",
+			PostgresSSLRootCert: "/etc/postgres-tls/ca.crt",
+			PostgresSSLCert:     "/etc/postgres-tls/tls.crt",
+			PostgresSSLKey:      "/etc/postgres-tls/tls.key",
+		})
+		Expect(conn.UriArgs).To(Equal("connect_timeout=5&sslmode=verify-full"))
+		Expect(conn.SSLRootCert).To(Equal("/etc/postgres-tls/ca.crt"))
+	})
+
+	It("should take the CA bundle from the URI args", func() {
+		conn := ClientConnectionArgs(&config.Cfg{PostgresUriArgs: "sslmode=verify-ca&sslrootcert=/certs/ca.pem"})
+		Expect(conn.UriArgs).To(Equal("sslmode=verify-ca"))
+		Expect(conn.SSLRootCert).To(Equal("/certs/ca.pem"))
+	})
+
+	It("should keep the system CA bundle", func() {
+		conn := ClientConnectionArgs(&config.Cfg{PostgresUriArgs: "sslmode=verify-full&sslrootcert=system"})
+		Expect(conn.UriArgs).To(Equal("sslmode=verify-full&sslrootcert=system"))
+		Expect(conn.SSLRootCert).To(BeEmpty())
+	})
+
+	It("should keep args without TLS settings", func() {
+		conn := ClientConnectionArgs(&config.Cfg{PostgresUriArgs: "sslmode=require"})
+		Expect(conn.UriArgs).To(Equal("sslmode=require"))
+		Expect(conn.SSLRootCert).To(BeEmpty())
+	})
+})
@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	CREATE_PUBLICATION         = `CREATE PUBLICATION "%s"%s WITH (publish = '%s')`
+	ALTER_PUBLICATION_OBJECTS  = `ALTER PUBLICATION "%s" SET %s`
+	ALTER_PUBLICATION_OPTIONS  = `ALTER PUBLICATION "%s" SET (publish = '%s')`
+	DROP_PUBLICATION           = `DROP PUBLICATION IF EXISTS "%s"`
+	GET_PUBLICATION            = `SELECT puballtables FROM pg_catalog.pg_publication WHERE pubname = $1`
+	CREATE_SUBSCRIPTION        = `CREATE SUBSCRIPTION "%s" CONNECTION %s PUBLICATION %s WITH (enabled = %t, copy_data = %t%s)`
+	ALTER_SUBSCRIPTION_CONN    = `ALTER SUBSCRIPTION "%s" CONNECTION %s`
+	ALTER_SUBSCRIPTION_PUBS    = `ALTER SUBSCRIPTION "%s" SET PUBLICATION %s`
+	ALTER_SUBSCRIPTION_ENABLED = `ALTER SUBSCRIPTION "%s" %s`
+	DROP_SUBSCRIPTION          = `DROP SUBSCRIPTION IF EXISTS "%s"`
+	GET_SUBSCRIPTION           = `SELECT subenabled, subpublications::text[] FROM pg_catalog.pg_subscription WHERE subname = $1 AND subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())`
+)
+
+// PostgresPublication describes a publication for logical replication.
+// Tables are either plain or schema qualified table names.
+type PostgresPublication struct {
+	Name      string
+	AllTables bool
+	Tables    []string
+	Schemas   []string
+	// Comma separated operations to publish
+	Publish string
+}
+
+// PostgresSubscription describes a subscription to publications of another server
+type PostgresSubscription struct {
+	Name         string
+	Connection   string
+	Publications []string
+	Enabled      bool
+	CopyData     bool
+	SlotName     string
+}
+
+// CreateOrUpdatePublication creates the publication or updates its published objects and operations.
+// Only owners of the published tables may publish them, without superuser the operator relies on
+// its membership in the owner role of the database granted by CreateDB.
+func (c *pg) CreateOrUpdatePublication(db string, publication PostgresPublication) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tx, err := tmpDb.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var allTables bool
+	err = tx.QueryRow(GET_PUBLICATION, publication.Name).Scan(&allTables)
+	exists := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	// A publication FOR ALL TABLES can't be changed into one for a list of objects and vice versa
+	if exists && allTables != publication.AllTables {
+		_, err = tx.Exec(fmt.Sprintf(DROP_PUBLICATION, publication.Name))
+		if err != nil {
+			return err
+		}
+		exists = false
+	}
+
+	objects := publicationObjects(publication)
+	if !exists {
+		forObjects := ""
+		if objects != "" {
+			forObjects = " FOR " + objects
+		}
+		_, err = tx.Exec(fmt.Sprintf(CREATE_PUBLICATION, publication.Name, forObjects, publication.Publish))
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if !publication.AllTables && objects != "" {
+		_, err = tx.Exec(fmt.Sprintf(ALTER_PUBLICATION_OBJECTS, publication.Name, objects))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(fmt.Sprintf(ALTER_PUBLICATION_OPTIONS, publication.Name, publication.Publish))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *pg) DropPublication(db, name string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		// Error code 3D000 is returned if database doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "3D000" {
+			return nil
+		}
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(DROP_PUBLICATION, name))
+	return err
+}
+
+// publicationObjects renders the objects of a publication as used after FOR and SET
+func publicationObjects(publication PostgresPublication) string {
+	if publication.AllTables {
+		return "ALL TABLES"
+	}
+	var objects []string
+	if len(publication.Tables) > 0 {
+		tables := make([]string, len(publication.Tables))
+		for i, table := range publication.Tables {
+			schema, name, found := strings.Cut(table, ".")
+			if !found {
+				schema, name = "public", table
+			}
+			tables[i] = fmt.Sprintf(`"%s"."%s"`, schema, name)
+		}
+		objects = append(objects, "TABLE "+strings.Join(tables, ", "))
+	}
+	if len(publication.Schemas) > 0 {
+		objects = append(objects, fmt.Sprintf(`TABLES IN SCHEMA "%s"`, strings.Join(publication.Schemas, `", "`)))
+	}
+	return strings.Join(objects, ", ")
+}
+
+// CreateOrUpdateSubscription creates the subscription or updates its publications and whether it is
+// enabled. The connection is only changed if updateConnection is set, as it can't be read back
+// without superuser privileges. Subscriptions can't be managed inside a transaction.
+func (c *pg) CreateOrUpdateSubscription(db string, subscription PostgresSubscription, updateConnection bool) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	publications := fmt.Sprintf(`"%s"`, strings.Join(subscription.Publications, `", "`))
+	var (
+		enabled  bool
+		existing []string
+	)
+	err = tmpDb.QueryRow(GET_SUBSCRIPTION, subscription.Name).Scan(&enabled, pq.Array(&existing))
+	if err == sql.ErrNoRows {
+		options := ""
+		if subscription.SlotName != "" {
+			options = fmt.Sprintf(", slot_name = %s", pq.QuoteLiteral(subscription.SlotName))
+		}
+		_, err = tmpDb.Exec(fmt.Sprintf(CREATE_SUBSCRIPTION, subscription.Name, pq.QuoteLiteral(subscription.Connection),
+			publications, subscription.Enabled, subscription.CopyData, options))
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if updateConnection {
+		_, err = tmpDb.Exec(fmt.Sprintf(ALTER_SUBSCRIPTION_CONN, subscription.Name, pq.QuoteLiteral(subscription.Connection)))
+		if err != nil {
+			return err
+		}
+	}
+	slices.Sort(existing)
+	desired := slices.Sorted(slices.Values(subscription.Publications))
+	if !slices.Equal(existing, desired) {
+		_, err = tmpDb.Exec(fmt.Sprintf(ALTER_SUBSCRIPTION_PUBS, subscription.Name, publications))
+		if err != nil {
+			return err
+		}
+	}
+	if enabled != subscription.Enabled {
+		action := "DISABLE"
+		if subscription.Enabled {
+			action = "ENABLE"
+		}
+		_, err = tmpDb.Exec(fmt.Sprintf(ALTER_SUBSCRIPTION_ENABLED, subscription.Name, action))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropSubscription drops the subscription along with its replication slot on the publisher
+func (c *pg) DropSubscription(db, name string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		// Error code 3D000 is returned if database doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "3D000" {
+			return nil
+		}
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(DROP_SUBSCRIPTION, name))
+	return err
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"github.com/lib/pq"
+)
+
+const (
+	CREATE_REPLICATION_SLOT = `SELECT pg_catalog.pg_create_logical_replication_slot($1, $2)`
+	DROP_REPLICATION_SLOT   = `SELECT pg_catalog.pg_drop_replication_slot($1)`
+	GET_REPLICATION_SLOTS   = `SELECT slot_name, plugin, active, COALESCE(pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), restart_lsn), 0)::bigint FROM pg_catalog.pg_replication_slots WHERE slot_type = 'logical' AND database = current_database()`
+)
+
+// PostgresReplicationSlot is a logical replication slot as reported by pg_replication_slots.
+// LagBytes is the amount of WAL the server retains for the slot.
+type PostgresReplicationSlot struct {
+	Name     string
+	Plugin   string
+	Active   bool
+	LagBytes int64
+}
+
+// CreateReplicationSlot creates a logical replication slot in db using the output plugin
+func (c *pg) CreateReplicationSlot(db, name, plugin string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(CREATE_REPLICATION_SLOT, name, plugin)
+	if err != nil {
+		// Error code 42710 is duplicate_object (slot already exists)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42710" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// DropReplicationSlot drops a logical replication slot of db, it fails while the slot is in use
+func (c *pg) DropReplicationSlot(db, name string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		// Error code 3D000 is returned if database doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "3D000" {
+			return nil
+		}
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(DROP_REPLICATION_SLOT, name)
+	if err != nil {
+		// Error code 42704 is undefined_object (slot does not exist)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42704" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// GetReplicationSlots lists the logical replication slots of db
+func (c *pg) GetReplicationSlots(db string) ([]PostgresReplicationSlot, error) {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := tmpDb.Query(GET_REPLICATION_SLOTS)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []PostgresReplicationSlot
+	for rows.Next() {
+		var slot PostgresReplicationSlot
+		err = rows.Scan(&slot.Name, &slot.Plugin, &slot.Active, &slot.LagBytes)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return slots, rows.Err()
+}
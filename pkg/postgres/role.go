@@ -2,30 +2,39 @@ package postgres
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lib/pq"
 )
 
 const (
-	CREATE_GROUP_ROLE   = `CREATE ROLE "%s"`
-	RENAME_GROUP_ROLE   = `ALTER ROLE "%s" RENAME TO "%s"`
-	CREATE_USER_ROLE    = `CREATE ROLE "%s" WITH LOGIN PASSWORD '%s'`
-	GRANT_ROLE          = `GRANT "%s" TO "%s"`
-	ALTER_USER_SET_ROLE = `ALTER USER "%s" SET ROLE "%s"`
-	REVOKE_ROLE         = `REVOKE "%s" FROM "%s"`
-	UPDATE_PASSWORD     = `ALTER ROLE "%s" WITH PASSWORD '%s'`
-	DROP_ROLE           = `DROP ROLE "%s"`
-	DROP_OWNED_BY       = `DROP OWNED BY "%s"`
-	REASIGN_OBJECTS     = `REASSIGN OWNED BY "%s" TO "%s"`
+	CREATE_GROUP_ROLE    = `CREATE ROLE "%s"`
+	RENAME_GROUP_ROLE    = `ALTER ROLE "%s" RENAME TO "%s"`
+	CREATE_USER_ROLE     = `CREATE ROLE "%s" WITH LOGIN PASSWORD '%s'`
+	GRANT_ROLE           = `GRANT "%s" TO "%s"`
+	ALTER_USER_SET_ROLE  = `ALTER USER "%s" SET ROLE "%s"`
+	REVOKE_ROLE          = `REVOKE "%s" FROM "%s"`
+	UPDATE_PASSWORD      = `ALTER ROLE "%s" WITH PASSWORD '%s'`
+	DROP_ROLE            = `DROP ROLE "%s"`
+	DROP_OWNED_BY        = `DROP OWNED BY "%s"`
+	REASIGN_OBJECTS      = `REASSIGN OWNED BY "%s" TO "%s"`
+	ALTER_REPLICATION    = `ALTER ROLE "%s" WITH %s`
+	CREATE_EXPIRING_ROLE = `CREATE ROLE "%s" WITH LOGIN PASSWORD '%s' VALID UNTIL '%s'`
 )
 
-func (c *pg) CreateGroupRole(role string) error {
+func (c *pg) CreateGroupRole(role string, tag ObjectTag) error {
 	// Error code 42710 is duplicate_object (role already exists)
 	_, err := c.db.Exec(fmt.Sprintf(CREATE_GROUP_ROLE, role))
-	if err != nil && err.(*pq.Error).Code != "42710" {
-		return err
+	if err != nil {
+		if err.(*pq.Error).Code != "42710" {
+			return err
+		}
+		err = claim(c.db, GET_ROLE_COMMENT, role, tag)
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return c.tag(COMMENT_ON_ROLE, role, tag)
 }
 
 func (c *pg) RenameGroupRole(currentRole, newRole string) error {
@@ -42,12 +51,12 @@ func (c *pg) RenameGroupRole(currentRole, newRole string) error {
 	return nil
 }
 
-func (c *pg) CreateUserRole(role, password string) (string, error) {
+func (c *pg) CreateUserRole(role, password string, tag ObjectTag) (string, error) {
 	_, err := c.db.Exec(fmt.Sprintf(CREATE_USER_ROLE, role, password))
 	if err != nil {
 		return "", err
 	}
-	return role, nil
+	return role, c.tag(COMMENT_ON_ROLE, role, tag)
 }
 
 func (c *pg) GrantRole(role, grantee string) error {
@@ -58,6 +67,41 @@ func (c *pg) GrantRole(role, grantee string) error {
 	return nil
 }
 
+// SetReplication allows role to connect in replication mode, e.g. for logical decoding
+func (c *pg) SetReplication(role string, enabled bool) error {
+	attribute := "NOREPLICATION"
+	if enabled {
+		attribute = "REPLICATION"
+	}
+	_, err := c.db.Exec(fmt.Sprintf(ALTER_REPLICATION, role, attribute))
+	return err
+}
+
+// CreateExpiringUserRole creates a login role whose password expires at until, the role cannot log in
+// afterwards. The role is created along with its tag, it never exists without them.
+func (c *pg) CreateExpiringUserRole(role, password string, until time.Time, tag ObjectTag) (string, error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(fmt.Sprintf(CREATE_EXPIRING_ROLE, role, password, until.UTC().Format(time.RFC3339)))
+	if err != nil {
+		return "", err
+	}
+	if tag.UID != "" {
+		comment, err := tag.comment()
+		if err != nil {
+			return "", err
+		}
+		_, err = tx.Exec(fmt.Sprintf(COMMENT_ON_ROLE, role, comment))
+		if err != nil {
+			return "", err
+		}
+	}
+	return role, tx.Commit()
+}
+
 func (c *pg) AlterDefaultLoginRole(role, setRole string) error {
 	_, err := c.db.Exec(fmt.Sprintf(ALTER_USER_SET_ROLE, role, setRole))
 	if err != nil {
@@ -74,9 +118,14 @@ func (c *pg) RevokeRole(role, revoked string) error {
 	return nil
 }
 
+// GetExistingRoles returns those of roles that exist on the server
+func (c *pg) GetExistingRoles(roles []string) ([]string, error) {
+	return queryStrings(c.db, GET_EXISTING_ROLES, pq.Array(roles))
+}
+
 func (c *pg) DropRole(role, newOwner, database string) error {
 	// REASSIGN OWNED BY only works if the correct database is selected
-	tmpDb, err := GetConnection(c.user, c.pass, c.host, database, c.args)
+	tmpDb, release, err := c.connection(database)
 	if err != nil {
 		if err.(*pq.Error).Code == "3D000" {
 			return nil // Database is does not exist (anymore)
@@ -85,7 +134,7 @@ func (c *pg) DropRole(role, newOwner, database string) error {
 		}
 	}
 	_, err = tmpDb.Exec(fmt.Sprintf(REASIGN_OBJECTS, role, newOwner))
-	defer tmpDb.Close()
+	defer release()
 	// Check if error exists and if different from "ROLE NOT FOUND" => 42704
 	if err != nil && err.(*pq.Error).Code != "42704" {
 		return err
@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	ENABLE_ROW_SECURITY  = `ALTER TABLE "%s"."%s" ENABLE ROW LEVEL SECURITY`
+	DISABLE_ROW_SECURITY = `ALTER TABLE "%s"."%s" DISABLE ROW LEVEL SECURITY, NO FORCE ROW LEVEL SECURITY`
+	FORCE_ROW_SECURITY   = `ALTER TABLE "%s"."%s" %s ROW LEVEL SECURITY`
+	CREATE_POLICY        = `CREATE POLICY "%s" ON "%s"."%s" AS %s FOR %s TO %s`
+	DROP_POLICY          = `DROP POLICY IF EXISTS "%s" ON "%s"."%s"`
+	GET_POLICIES         = `SELECT policyname, permissive, roles::text[], cmd, qual, with_check FROM pg_catalog.pg_policies WHERE schemaname = $1 AND tablename = $2`
+	// Backslashes in string literals of policy expressions are no escapes
+	STANDARD_STRINGS = `SET LOCAL standard_conforming_strings = on`
+)
+
+// PostgresPolicy is a row security policy on a table. Using and WithCheck are SQL expressions,
+// as read from pg_policies they are deparsed by the server and may differ from what was created.
+type PostgresPolicy struct {
+	Schema      string
+	Table       string
+	Name        string
+	Command     string
+	Restrictive bool
+	Roles       []string
+	Using       string
+	WithCheck   string
+}
+
+func (c *pg) EnableRowSecurity(db, schema, table string, force bool) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(ENABLE_ROW_SECURITY, schema, table))
+	if err != nil {
+		return err
+	}
+	// Row security does not apply to the table owner, unless forced
+	forced := "NO FORCE"
+	if force {
+		forced = "FORCE"
+	}
+	_, err = tmpDb.Exec(fmt.Sprintf(FORCE_ROW_SECURITY, schema, table, forced))
+	return err
+}
+
+// DisableRowSecurity turns row level security of a table off again, policies left on it no longer apply
+func (c *pg) DisableRowSecurity(db, schema, table string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(DISABLE_ROW_SECURITY, schema, table))
+	// Error code 42P01 is returned if the table doesn't exist (anymore)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42P01" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *pg) GetPolicies(db, schema, table string) ([]PostgresPolicy, error) {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return getPolicies(tmpDb, schema, table)
+}
+
+// ReplacePolicy drops and recreates a policy in one transaction and returns it as stored by the server.
+// The expressions of the policy are checked by checkExpression first.
+func (c *pg) ReplacePolicy(db string, policy PostgresPolicy) (PostgresPolicy, error) {
+	for _, expr := range []string{policy.Using, policy.WithCheck} {
+		if err := checkExpression(expr); err != nil {
+			return PostgresPolicy{}, fmt.Errorf("policy %s on %s.%s: %w", policy.Name, policy.Schema, policy.Table, err)
+		}
+	}
+
+	// The expressions are tenant-supplied, they must not leave state behind in pooled sessions
+	conn, release, err := c.dedicatedConnection(db)
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+	defer release()
+
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(STANDARD_STRINGS)
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+	_, err = tx.Exec(fmt.Sprintf(DROP_POLICY, policy.Name, policy.Schema, policy.Table))
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+
+	as := "PERMISSIVE"
+	if policy.Restrictive {
+		as = "RESTRICTIVE"
+	}
+	roles := fmt.Sprintf(`"%s"`, strings.Join(policy.Roles, `", "`))
+	query := fmt.Sprintf(CREATE_POLICY, policy.Name, policy.Schema, policy.Table, as, policy.Command, roles)
+	if policy.Using != "" {
+		query += fmt.Sprintf(" USING (%s)", policy.Using)
+	}
+	if policy.WithCheck != "" {
+		query += fmt.Sprintf(" WITH CHECK (%s)", policy.WithCheck)
+	}
+	// A prepared statement is sent with the extended protocol, for which the server refuses more than one command
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec()
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+
+	policies, err := getPolicies(tx, policy.Schema, policy.Table)
+	if err != nil {
+		return PostgresPolicy{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return PostgresPolicy{}, err
+	}
+	for _, p := range policies {
+		if p.Name == policy.Name {
+			return p, nil
+		}
+	}
+	return PostgresPolicy{}, fmt.Errorf("policy %s on %s.%s not found after creation", policy.Name, policy.Schema, policy.Table)
+}
+
+func (c *pg) DropPolicy(db, schema, table, name string) error {
+	tmpDb, release, err := c.connection(db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tmpDb.Exec(fmt.Sprintf(DROP_POLICY, name, schema, table))
+	// Error code 42P01 is returned if the table doesn't exist (anymore)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42P01" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func getPolicies(db queryer, schema, table string) ([]PostgresPolicy, error) {
+	rows, err := db.Query(GET_POLICIES, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []PostgresPolicy
+	for rows.Next() {
+		var (
+			permissive       string
+			using, withCheck sql.NullString
+		)
+		policy := PostgresPolicy{Schema: schema, Table: table}
+		err = rows.Scan(&policy.Name, &permissive, pq.Array(&policy.Roles), &policy.Command, &using, &withCheck)
+		if err != nil {
+			return nil, err
+		}
+		policy.Restrictive = permissive == "RESTRICTIVE"
+		policy.Using = using.String
+		policy.WithCheck = withCheck.String
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// checkExpression makes sure a policy expression, which is SQL given by users of the operator, cannot
+// close the parentheses it is put in and add to the CREATE POLICY statement or run other statements.
+// It follows the lexical rules of PostgreSQL for string literals, quoted identifiers and dollar quoting,
+// and rejects what it cannot follow safely: escape string literals, comments and semicolons.
+func checkExpression(expr string) error {
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		ch := expr[i]
+		switch {
+		case isIdentifierStart(ch):
+			start := i
+			for i+1 < len(expr) && isIdentifierChar(expr[i+1]) {
+				i++
+			}
+			// E'...' treats backslashes as escapes, where a quote may not end the string
+			if word := expr[start : i+1]; (word == "E" || word == "e") && i+1 < len(expr) && expr[i+1] == '\'' {
+				return fmt.Errorf("escape string constants are not supported in expressions")
+			}
+		case ch == '\'' || ch == '"':
+			// A doubled quote ends the literal and starts the next one, which keeps the count right
+			end := strings.IndexByte(expr[i+1:], ch)
+			if end < 0 {
+				return fmt.Errorf("unterminated quoted string in expression")
+			}
+			i += end + 1
+		case ch == '$':
+			tag := dollarQuoteTag(expr[i:])
+			if tag == "" {
+				// A positional parameter
+				continue
+			}
+			end := strings.Index(expr[i+len(tag):], tag)
+			if end < 0 {
+				return fmt.Errorf("unterminated dollar-quoted string in expression")
+			}
+			i += len(tag) + end + len(tag) - 1
+		case ch == '-' && strings.HasPrefix(expr[i:], "--"), ch == '/' && strings.HasPrefix(expr[i:], "/*"):
+			return fmt.Errorf("comments are not supported in expressions")
+		case ch == ';':
+			return fmt.Errorf("expressions must not contain ';'")
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced parentheses in expression")
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses in expression")
+	}
+	return nil
+}
+
+// dollarQuoteTag returns the $tag$ s starts with, or an empty string if it does not start a dollar quote
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1]
+		case !isIdentifierChar(s[i]) || (i == 1 && !isIdentifierStart(s[i])):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentifierStart(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80
+}
+
+// isIdentifierChar follows PostgreSQL, in which identifiers may contain dollar signs after the first character
+func isIdentifierChar(ch byte) bool {
+	return isIdentifierStart(ch) || ch >= '0' && ch <= '9' || ch == '$'
+}
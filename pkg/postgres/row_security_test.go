@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Row security", func() {
+	DescribeTable("should accept policy expressions",
+		func(expr string) {
+			Expect(checkExpression(expr)).To(Succeed())
+		},
+		Entry("empty", ""),
+		Entry("comparison", "tenant_id = current_setting('app.tenant_id')::int"),
+		Entry("nested parentheses", "(a = 1) AND (b IN (SELECT id FROM t WHERE (c)))"),
+		Entry("semicolon and parenthesis in a string", "name <> ');'"),
+		Entry("doubled quotes", "name = 'it''s; ('"),
+		Entry("quoted identifier", `"weird;)col" = 1`),
+		Entry("dollar quotes", "note = $x$ ); -- $x$"),
+		Entry("dollar in identifier", "a$b$ = 1"),
+		Entry("regular expression", `code ~ '^\d+$'`),
+	)
+
+	DescribeTable("should reject policy expressions",
+		func(expr, message string) {
+			Expect(checkExpression(expr)).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("statements injected with ;", "true); ALTER ROLE x SUPERUSER; --", "unbalanced parentheses"),
+		Entry("semicolon", "true; ALTER ROLE x SUPERUSER", "';'"),
+		Entry("closing parenthesis", "true) WITH CHECK (true", "unbalanced parentheses"),
+		Entry("opening parenthesis", "(true", "unbalanced parentheses"),
+		Entry("line comment", "true --", "comments"),
+		Entry("block comment", "true /* ) */", "comments"),
+		Entry("escape string", `name = E'\'); ALTER ROLE x SUPERUSER; --'`, "escape string"),
+		Entry("unterminated string", "name = ');", "unterminated quoted string"),
+		Entry("unterminated dollar quote", "name = $a$ );", "unterminated dollar-quoted"),
+	)
+})
@@ -1,6 +1,9 @@
 package utils
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 const INSTANCE_ANNOTATION = "postgres.db.movetokube.com/instance"
 
@@ -13,3 +16,11 @@ func MatchesInstanceAnnotation(annotationMap map[string]string, configuredInstan
 		return configuredInstance == ""
 	}
 }
+
+// ADOPT_ANNOTATION lets a resource take over roles, databases and schemas tagged by another resource
+const ADOPT_ANNOTATION = "postgres.db.movetokube.com/adopt"
+
+func AdoptionRequested(annotationMap map[string]string) bool {
+	adopt, err := strconv.ParseBool(annotationMap[ADOPT_ANNOTATION])
+	return err == nil && adopt
+}
@@ -78,3 +78,17 @@ var _ = Describe("MatchesInstanceAnnotation", func() {
 		})
 	})
 })
+
+var _ = Describe("AdoptionRequested", func() {
+	It("should return false when annotations are nil", func() {
+		Expect(AdoptionRequested(nil)).To(BeFalse())
+	})
+
+	It("should return true when the adopt annotation is true", func() {
+		Expect(AdoptionRequested(map[string]string{ADOPT_ANNOTATION: "true"})).To(BeTrue())
+	})
+
+	It("should return false when the adopt annotation is not a boolean", func() {
+		Expect(AdoptionRequested(map[string]string{ADOPT_ANNOTATION: "yes please"})).To(BeFalse())
+	})
+})
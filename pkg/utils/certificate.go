@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// CertificateAuthority signs client certificates
+type CertificateAuthority struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+	// CertPEM is the PEM encoded certificate of the authority, handed out as CA bundle
+	CertPEM []byte
+}
+
+// ParseCertificateAuthority reads a CA from a PEM encoded certificate and private key,
+// i.e. tls.crt and tls.key of a Kubernetes TLS secret
+func ParseCertificateAuthority(certPEM, keyPEM []byte) (*CertificateAuthority, error) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse CA certificate: %w", err)
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("certificate %q is not a CA", cert.Subject.CommonName)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("parse CA key: no PEM data")
+	}
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse CA key: %w", err)
+	}
+	return &CertificateAuthority{Cert: cert, Key: key, CertPEM: certPEM}, nil
+}
+
+// IssueClientCertificate returns a PEM encoded certificate for client authentication as commonName
+// and its PKCS #8 encoded ECDSA key, valid from now for validity
+func (ca *CertificateAuthority) IssueClientCertificate(commonName string, now time.Time, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		// Allow for clock skew between the operator and the server
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// ClientCertificateValid tells whether certPEM was issued by the CA for commonName and remains valid
+// for longer than renewBefore
+func (ca *CertificateAuthority) ClientCertificateValid(certPEM []byte, commonName string, now time.Time, renewBefore time.Duration) bool {
+	cert, err := parseCertificate(certPEM)
+	if err != nil || cert.Subject.CommonName != commonName {
+		return false
+	}
+	if now.Add(renewBefore).After(cert.NotAfter) {
+		return false
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err == nil
+}
+
+// CertificateCommonName returns the common name of a PEM encoded certificate
+func CertificateCommonName(certPEM []byte) (string, error) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return "", err
+	}
+	return cert.Subject.CommonName, nil
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// parsePrivateKey accepts PKCS #8, PKCS #1 and SEC 1 keys
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key format")
+}
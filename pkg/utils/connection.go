@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Connection string formats a PostgresUser can ask for in its secret
+const (
+	FormatLibpq      = "libpq"
+	FormatSQLAlchemy = "SQLAlchemy"
+	FormatPrisma     = "Prisma"
+	FormatRails      = "Rails"
+	FormatR2DBC      = "R2DBC"
+	FormatPgpass     = "pgpass"
+)
+
+// ConnectionFormatKeys are the secret keys of the connection string formats
+var ConnectionFormatKeys = map[string]string{
+	FormatLibpq:      "POSTGRES_DSN",
+	FormatSQLAlchemy: "POSTGRES_SQLALCHEMY_URL",
+	FormatPrisma:     "POSTGRES_PRISMA_URL",
+	FormatRails:      "DATABASE_URL",
+	FormatR2DBC:      "POSTGRES_R2DBC_URL",
+	FormatPgpass:     "PGPASS",
+}
+
+// r2dbcParams maps libpq parameters to the options of the R2DBC driver
+var r2dbcParams = map[string]string{
+	"sslmode":     "sslMode",
+	"sslrootcert": "sslRootCert",
+	"sslcert":     "sslCert",
+	"sslkey":      "sslKey",
+}
+
+// Connection holds what a client needs to connect to a database
+type Connection struct {
+	Hostname string
+	Port     string
+	Database string
+	User     string
+	Password string
+	// Schema is passed to clients selecting a schema in their connection string, i.e. Prisma
+	Schema string
+	// Params are libpq connection parameters such as sslmode, i.e. parsed URI args
+	Params url.Values
+}
+
+// URL returns the connection as URL of scheme with escaped credentials and params
+func (c Connection) URL(scheme string) string {
+	return c.url(scheme, c.Params)
+}
+
+func (c Connection) url(scheme string, params url.Values) string {
+	u := url.URL{
+		Scheme:   scheme,
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Hostname, c.Port),
+		Path:     "/" + c.Database,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// Format returns the connection string of format
+func (c Connection) Format(format string) (string, error) {
+	switch format {
+	case FormatLibpq:
+		return c.keywordValue(), nil
+	case FormatSQLAlchemy:
+		return c.URL("postgresql+psycopg2"), nil
+	case FormatPrisma:
+		params := cloneParams(c.Params)
+		if c.Schema != "" {
+			params.Set("schema", c.Schema)
+		}
+		return c.url("postgresql", params), nil
+	case FormatRails:
+		return c.URL("postgres"), nil
+	case FormatR2DBC:
+		params := url.Values{}
+		for key, values := range c.Params {
+			if option, ok := r2dbcParams[key]; ok {
+				key = option
+			}
+			params[key] = values
+		}
+		return "r2dbc:" + c.url("postgresql", params), nil
+	case FormatPgpass:
+		return strings.Join([]string{
+			pgpassEscape(c.Hostname),
+			pgpassEscape(c.Port),
+			pgpassEscape(c.Database),
+			pgpassEscape(c.User),
+			pgpassEscape(c.Password),
+		}, ":"), nil
+	default:
+		return "", fmt.Errorf("unknown connection format %q", format)
+	}
+}
+
+// keywordValue returns the libpq keyword/value connection string
+func (c Connection) keywordValue() string {
+	pairs := []string{
+		"host=" + libpqQuote(c.Hostname),
+		"port=" + libpqQuote(c.Port),
+		"dbname=" + libpqQuote(c.Database),
+		"user=" + libpqQuote(c.User),
+		"password=" + libpqQuote(c.Password),
+	}
+	keys := make([]string, 0, len(c.Params))
+	for key := range c.Params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+libpqQuote(c.Params.Get(key)))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// libpqQuote quotes values that are empty or contain spaces, quotes or backslashes
+func libpqQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\f\v'\\") {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+// pgpassEscape escapes the field separator and backslashes of a .pgpass line
+func pgpassEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `:`, `\:`).Replace(value)
+}
+
+func cloneParams(params url.Values) url.Values {
+	clone := url.Values{}
+	for key, values := range params {
+		clone[key] = slices.Clone(values)
+	}
+	return clone
+}
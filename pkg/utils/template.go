@@ -2,8 +2,12 @@ package utils
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"reflect"
+	"strings"
 	"text/template"
 )
 
@@ -15,6 +19,16 @@ type TemplateContext struct {
 	Hostname string // Hostname is different from Host as it does not contain the port number.
 	Port     string
 	UriArgs  string
+	// Login differs from Role on Azure, where it is role@server
+	Login string
+	// Group role granted to Role
+	Group string
+	// Namespace and name of the resource the template belongs to
+	Namespace string
+	Name      string
+	// Schema of the user if it has one, Schemas are all schemas of the database
+	Schema  string
+	Schemas []string
 }
 
 func RenderTemplate(data map[string]string, tc TemplateContext) (map[string][]byte, error) {
@@ -24,6 +38,7 @@ func RenderTemplate(data map[string]string, tc TemplateContext) (map[string][]by
 	var out = make(map[string][]byte, len(data))
 	for key, templ := range data {
 		tmplObj := template.New("")
+		tmplObj.Funcs(templateFuncs())
 		tmplObj.Funcs(template.FuncMap{
 			"mergeUriArgs": func(uriArgs string) (string, error) {
 				inputArgs, err := url.ParseQuery(uriArgs)
@@ -52,3 +67,116 @@ func RenderTemplate(data map[string]string, tc TemplateContext) (map[string][]by
 	}
 	return out, nil
 }
+
+// templateFuncs returns a subset of the Sprig functions, with the same names and argument order so they
+// work as documented by Sprig. Functions reading the environment, files or randomness are left out.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// strings
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"quote":      func(values ...any) string { return quoteAll("%q", values) },
+		"squote":     func(values ...any) string { return quoteAll("'%s'", values) },
+		// encodings, urlquery and js are built in
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": func(s string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			return string(decoded), err
+		},
+		"urlPathEscape": url.PathEscape,
+		"toJson": func(v any) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+		// defaults
+		"default":  func(d any, given ...any) any { return defaultValue(d, given) },
+		"empty":    empty,
+		"coalesce": coalesce,
+		"ternary": func(vt, vf any, condition bool) any {
+			if condition {
+				return vt
+			}
+			return vf
+		},
+		// collections
+		"list": func(values ...any) []any { return values },
+		"dict": dict,
+	}
+}
+
+// empty tells whether v is the zero value of its type, or an empty collection
+func empty(v any) bool {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+func defaultValue(d any, given []any) any {
+	if len(given) == 0 || empty(given[0]) {
+		return d
+	}
+	return given[0]
+}
+
+func coalesce(values ...any) any {
+	for _, v := range values {
+		if !empty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+func join(sep string, v any) string {
+	switch values := v.(type) {
+	case []string:
+		return strings.Join(values, sep)
+	case []any:
+		parts := make([]string, 0, len(values))
+		for _, value := range values {
+			if value != nil {
+				parts = append(parts, fmt.Sprint(value))
+			}
+		}
+		return strings.Join(parts, sep)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func quoteAll(format string, values []any) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != nil {
+			parts = append(parts, fmt.Sprintf(format, fmt.Sprint(value)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func dict(values ...any) (map[string]any, error) {
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("dict expects key value pairs")
+	}
+	d := make(map[string]any, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		d[fmt.Sprint(values[i])] = values[i+1]
+	}
+	return d, nil
+}
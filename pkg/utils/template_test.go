@@ -101,5 +101,78 @@ var _ = ginkgo.Describe("Template Utils", func() {
 				gomega.Expect(result).To(gomega.BeNil())
 			})
 		})
+
+		ginkgo.When("using the fields describing the resource", func() {
+			ginkgo.BeforeEach(func() {
+				templateContext.Login = "admin@server"
+				templateContext.Group = "postgres-group"
+				templateContext.Namespace = "apps"
+				templateContext.Name = "my-user"
+				templateContext.Schema = "reports"
+				templateContext.Schemas = []string{"public", "reports"}
+				templates = map[string]string{
+					"resource":    "{{.Namespace}}/{{.Name}}",
+					"roles":       "{{.Login}} {{.Group}}",
+					"search-path": "{{.Schema}}:{{join \",\" .Schemas}}",
+				}
+			})
+
+			ginkgo.It("should render them", func() {
+				result, err := RenderTemplate(templates, templateContext)
+
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(string(result["resource"])).To(gomega.Equal("apps/my-user"))
+				gomega.Expect(string(result["roles"])).To(gomega.Equal("admin@server postgres-group"))
+				gomega.Expect(string(result["search-path"])).To(gomega.Equal("reports:public,reports"))
+			})
+		})
+
+		ginkgo.When("using template functions", func() {
+			ginkgo.BeforeEach(func() {
+				templateContext.Password = "p@ss/word"
+				templates = map[string]string{
+					"upper":    "{{.Role | upper}}",
+					"urlquery": "{{.Password | urlquery}}",
+					"path":     "{{.Password | urlPathEscape}}",
+					"b64":      "{{.Password | b64enc}}",
+					"b64dec":   "{{\"cEBzcy93b3Jk\" | b64dec}}",
+					"default":  "{{.Schema | default \"public\"}}",
+					"coalesce": "{{coalesce .Schema .Database}}",
+					"ternary":  "{{ternary \"require\" \"disable\" (eq .Host \"localhost\")}}",
+					"replace":  "{{.Host | replace \"local\" \"remote\"}}",
+					"quote":    "{{quote .Role}} {{squote .Role}}",
+					"toJson":   "{{dict \"user\" .Role \"hosts\" (list .Host) | toJson}}",
+					"split":    "{{index (split \":\" \"a:b\") 1}}",
+					"trim":     "{{trimPrefix \"post\" .Database | trimSuffix \"es\" | trim}}",
+				}
+			})
+
+			ginkgo.It("should render them like Sprig", func() {
+				result, err := RenderTemplate(templates, templateContext)
+
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(string(result["upper"])).To(gomega.Equal("ADMIN"))
+				gomega.Expect(string(result["urlquery"])).To(gomega.Equal("p%40ss%2Fword"))
+				gomega.Expect(string(result["path"])).To(gomega.Equal("p@ss%2Fword"))
+				gomega.Expect(string(result["b64"])).To(gomega.Equal("cEBzcy93b3Jk"))
+				gomega.Expect(string(result["b64dec"])).To(gomega.Equal("p@ss/word"))
+				gomega.Expect(string(result["default"])).To(gomega.Equal("public"))
+				gomega.Expect(string(result["coalesce"])).To(gomega.Equal("postgres"))
+				gomega.Expect(string(result["ternary"])).To(gomega.Equal("require"))
+				gomega.Expect(string(result["replace"])).To(gomega.Equal("remotehost"))
+				gomega.Expect(string(result["quote"])).To(gomega.Equal(`"admin" 'admin'`))
+				gomega.Expect(string(result["toJson"])).To(gomega.Equal(`{"hosts":["localhost"],"user":"admin"}`))
+				gomega.Expect(string(result["split"])).To(gomega.Equal("b"))
+				gomega.Expect(string(result["trim"])).To(gomega.Equal("gr"))
+			})
+
+			ginkgo.It("should return errors of functions", func() {
+				result, err := RenderTemplate(map[string]string{"bad": "{{b64dec \"%%\"}}"}, templateContext)
+
+				gomega.Expect(err).To(gomega.HaveOccurred())
+				gomega.Expect(err.Error()).To(gomega.ContainSubstring("execute template"))
+				gomega.Expect(result).To(gomega.BeNil())
+			})
+		})
 	})
 })